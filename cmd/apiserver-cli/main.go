@@ -3,6 +3,7 @@ package main
 
 import (
 	"github.com/Mike-CZ/ftm-gas-monetization/cmd/apiserver-cli/apiserver"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/cli/migrate"
 	"github.com/urfave/cli/v2"
 	"log"
 	"os"
@@ -16,6 +17,7 @@ func initApp() *cli.App {
 		Commands: []*cli.Command{
 			&apiserver.CmdRun,
 			&apiserver.CmdConfig,
+			&migrate.CmdMigrate,
 		},
 	}
 }
@@ -9,4 +9,47 @@ var (
 		Name:  "cfg",
 		Usage: "path to config",
 	}
+
+	// Block defines the block the registry state is bootstrapped at
+	Block = cli.Uint64Flag{
+		Name:     "block",
+		Usage:    "block the registry state is bootstrapped at, the scanner continues from it",
+		Required: true,
+	}
+
+	// Contract defines the gas monetization contract address to filter by
+	Contract = cli.StringFlag{
+		Name:  "contract",
+		Usage: "gas monetization contract address",
+	}
+
+	// TxHash defines the transaction hash to filter by
+	TxHash = cli.StringFlag{
+		Name:  "tx",
+		Usage: "transaction hash",
+	}
+
+	// EventName defines the event name to filter by
+	EventName = cli.StringFlag{
+		Name:  "name",
+		Usage: "event name, e.g. ProjectAdded",
+	}
+
+	// EventOutcome defines the event processing outcome to filter by
+	EventOutcome = cli.StringFlag{
+		Name:  "outcome",
+		Usage: "event processing outcome: processed, failed, ignored, unhandled or unknown",
+	}
+
+	// FromBlock defines the first block of the range to filter by
+	FromBlock = cli.Uint64Flag{
+		Name:  "from-block",
+		Usage: "first block of the range",
+	}
+
+	// ToBlock defines the last block of the range to filter by
+	ToBlock = cli.Uint64Flag{
+		Name:  "to-block",
+		Usage: "last block of the range",
+	}
 )
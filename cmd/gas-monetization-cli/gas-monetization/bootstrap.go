@@ -0,0 +1,31 @@
+package gas_monetization
+
+import (
+	"ftm-gas-monetization/cmd/gas-monetization-cli/flags"
+	"ftm-gas-monetization/internal/app"
+	"ftm-gas-monetization/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdBootstrap defines a CLI command for bootstrapping the registry state at a pinned block
+// and running the gas monetization app from that block.
+var CmdBootstrap = cli.Command{
+	Action: bootstrap,
+	Name:   "bootstrap",
+	Usage:  `Reads the projects registry at the given block into an empty database and runs the gas monetization app from it.`,
+	Flags: []cli.Flag{
+		&flags.Cfg,
+		&flags.Block,
+	},
+}
+
+func bootstrap(ctx *cli.Context) error {
+	cfg := config.Load(ctx)
+	app.Bootstrap(ctx, cfg)
+	if err := app.BootstrapRegistry(ctx.Uint64(flags.Block.Name)); err != nil {
+		return err
+	}
+	app.Start()
+
+	return nil
+}
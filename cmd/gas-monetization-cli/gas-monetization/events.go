@@ -0,0 +1,69 @@
+package gas_monetization
+
+import (
+	"encoding/json"
+	"fmt"
+	"ftm-gas-monetization/cmd/gas-monetization-cli/flags"
+	"ftm-gas-monetization/internal/app"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdEvents defines a CLI command for listing raw events emitted by the gas monetization contracts.
+var CmdEvents = cli.Command{
+	Action: events,
+	Name:   "events",
+	Usage:  `Lists raw events emitted by the gas monetization contracts.`,
+	Flags: []cli.Flag{
+		&flags.Cfg,
+		&flags.Contract,
+		&flags.TxHash,
+		&flags.EventName,
+		&flags.EventOutcome,
+		&flags.FromBlock,
+		&flags.ToBlock,
+	},
+}
+
+func events(ctx *cli.Context) error {
+	cfg := config.Load(ctx)
+	app.Bootstrap(ctx, cfg)
+
+	query := app.Repository().ContractEventQuery()
+	if ctx.IsSet(flags.Contract.Name) {
+		if !common.IsHexAddress(ctx.String(flags.Contract.Name)) {
+			return fmt.Errorf("invalid contract address %s", ctx.String(flags.Contract.Name))
+		}
+		query.WhereContract(&types.Address{Address: common.HexToAddress(ctx.String(flags.Contract.Name))})
+	}
+	if ctx.IsSet(flags.TxHash.Name) {
+		query.WhereTxHash(&types.Hash{Hash: common.HexToHash(ctx.String(flags.TxHash.Name))})
+	}
+	if ctx.IsSet(flags.EventName.Name) {
+		query.WhereName(ctx.String(flags.EventName.Name))
+	}
+	if ctx.IsSet(flags.EventOutcome.Name) {
+		query.WhereOutcome(ctx.String(flags.EventOutcome.Name))
+	}
+	if ctx.IsSet(flags.FromBlock.Name) {
+		query.WhereBlockFrom(ctx.Uint64(flags.FromBlock.Name))
+	}
+	if ctx.IsSet(flags.ToBlock.Name) {
+		query.WhereBlockTo(ctx.Uint64(flags.ToBlock.Name))
+	}
+	list, err := query.OrderBy(db.ContractEventColumns.BlockNumber, db.Asc).OrderBy(db.ContractEventColumns.LogIndex, db.Asc).GetAll()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(ctx.App.Writer)
+	for i := range list {
+		if err := enc.Encode(list[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package gas_monetization
+
+import (
+	"encoding/json"
+	"fmt"
+	"ftm-gas-monetization/cmd/gas-monetization-cli/flags"
+	"ftm-gas-monetization/internal/app"
+	"ftm-gas-monetization/internal/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdExplain defines a CLI command for explaining the gas attribution and rewards of a transaction.
+var CmdExplain = cli.Command{
+	Action: explain,
+	Name:   "explain",
+	Usage:  `Explains the gas attribution and rewards of a transaction.`,
+	Flags: []cli.Flag{
+		&flags.Cfg,
+		&flags.TxHash,
+	},
+}
+
+func explain(ctx *cli.Context) error {
+	hash, err := hexutil.Decode(ctx.String(flags.TxHash.Name))
+	if err != nil || len(hash) != common.HashLength {
+		return fmt.Errorf("invalid transaction hash %s", ctx.String(flags.TxHash.Name))
+	}
+
+	cfg := config.Load(ctx)
+	// the explanation is written to the standard output, the logs go to the standard error
+	app.BootstrapErrLog(ctx, cfg)
+
+	exp, err := app.ExplainTransaction(common.BytesToHash(hash))
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(ctx.App.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exp)
+}
@@ -0,0 +1,34 @@
+package gas_monetization
+
+import (
+	"encoding/json"
+	"ftm-gas-monetization/cmd/gas-monetization-cli/flags"
+	"ftm-gas-monetization/internal/app"
+	"ftm-gas-monetization/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdRebuildStats defines a CLI command for rebuilding the global totals from the stored projects.
+var CmdRebuildStats = cli.Command{
+	Action: rebuildStats,
+	Name:   "rebuild-stats",
+	Usage:  `Recalculates the total amounts collected and claimed from the stored projects, the total transactions count is kept.`,
+	Flags: []cli.Flag{
+		&flags.Cfg,
+	},
+}
+
+func rebuildStats(ctx *cli.Context) error {
+	cfg := config.Load(ctx)
+	app.Bootstrap(ctx, cfg)
+
+	stats, err := app.Repository().RebuildStats()
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(ctx.App.Writer).Encode(map[string]interface{}{
+		"totalAmountCollected":   stats.TotalAmountCollected.ToInt().String(),
+		"totalAmountClaimed":     stats.TotalAmountClaimed.ToInt().String(),
+		"totalTransactionsCount": stats.TotalTransactionsCount,
+	})
+}
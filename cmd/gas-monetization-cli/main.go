@@ -3,6 +3,7 @@ package main
 
 import (
 	"ftm-gas-monetization/cmd/gas-monetization-cli/gas-monetization"
+	"ftm-gas-monetization/internal/cli/migrate"
 	"github.com/urfave/cli/v2"
 	"log"
 	"os"
@@ -15,7 +16,12 @@ func initApp() *cli.App {
 		Usage:    "starts observing blocks and accumulating pending rewards for white-listed addresses",
 		Commands: []*cli.Command{
 			&gas_monetization.CmdRun,
+			&gas_monetization.CmdBootstrap,
 			&gas_monetization.CmdConfig,
+			&gas_monetization.CmdEvents,
+			&gas_monetization.CmdExplain,
+			&gas_monetization.CmdRebuildStats,
+			&migrate.CmdMigrate,
 		},
 	}
 }
@@ -1,15 +1,23 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/config"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/graphql/resolvers"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/handlers"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/logger"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
+// shutdownTimeout is the maximum time the requests being served are waited for on shutdown.
+const shutdownTimeout = 10 * time.Second
+
 type ApiServer struct {
 	cfg    *config.Config
 	log    *logger.AppLogger
@@ -74,8 +82,31 @@ func (api *ApiServer) makeHttpServer() {
 
 // run executes the API server function.
 func (api *ApiServer) run() {
+	go api.observeSignals()
+
 	err := api.srv.ListenAndServe()
-	if err != nil {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		api.log.Error(err.Error())
+		return
+	}
+
+	// wait for the requests being served to finish before the connections are closed
+	<-api.closed
+	repository.R().Close()
+	api.log.Notice("server closed")
+}
+
+// observeSignals shuts the server down on a termination signal.
+func (api *ApiServer) observeSignals() {
+	ts := make(chan os.Signal, 1)
+	signal.Notify(ts, syscall.SIGINT, syscall.SIGTERM)
+	<-ts
+
+	api.log.Notice("server is terminating")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := api.srv.Shutdown(ctx); err != nil {
+		api.log.Errorf("failed to shut the server down; %s", err.Error())
 	}
+	close(api.closed)
 }
@@ -5,6 +5,8 @@ import (
 	"ftm-gas-monetization/internal/logger"
 	"ftm-gas-monetization/internal/repository"
 	"ftm-gas-monetization/internal/svc"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 	"sync"
 )
@@ -32,6 +34,15 @@ func Bootstrap(ctx *cli.Context, cfg *config.Config) {
 	}
 }
 
+// BootstrapErrLog bootstraps the app core logging into the error writer of the CLI app,
+// so the output of the command is not mixed with the logs.
+func BootstrapErrLog(ctx *cli.Context, cfg *config.Config) {
+	instance = App{
+		cfg: cfg,
+		log: logger.New(ctx.App.ErrWriter, ctx.App.HelpName, cfg.Logger.LoggingLevel),
+	}
+}
+
 // Start initializes and starts services.
 func Start() {
 	// start the manager
@@ -41,6 +52,17 @@ func Start() {
 	}
 }
 
+// BootstrapRegistry initializes the registry state as of the beginning of the given block.
+// It is expected to be called before the services are started.
+func BootstrapRegistry(block uint64) error {
+	return svc.Bootstrap(Repository(), instance.log, &instance.cfg.Partitions, block)
+}
+
+// ExplainTransaction explains the gas attribution and rewards of the given transaction.
+func ExplainTransaction(hash common.Hash) (*types.RewardExplanation, error) {
+	return svc.ExplainTransaction(Repository(), &instance.cfg.Attribution, hash)
+}
+
 // Repository provides access to the repository.
 func Repository() *repository.Repository {
 	onceRepository.Do(func() {
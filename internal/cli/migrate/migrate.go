@@ -0,0 +1,167 @@
+// Package migrate implements the database migration commands shared by the gas monetization CLIs.
+package migrate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"ftm-gas-monetization/cmd/gas-monetization-cli/flags"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/logger"
+	"ftm-gas-monetization/internal/repository/db"
+	"github.com/urfave/cli/v2"
+	"strconv"
+	"strings"
+)
+
+var (
+	// stepsFlag defines the number of migrations to revert
+	stepsFlag = cli.UintFlag{
+		Name:  "steps",
+		Usage: "number of migrations to revert",
+	}
+
+	// allFlag defines the revert of all the migrations
+	allFlag = cli.BoolFlag{
+		Name:  "all",
+		Usage: "revert all the migrations, dropping all the data; asks for confirmation",
+	}
+)
+
+// CmdMigrate defines a CLI command for managing the database schema migrations.
+var CmdMigrate = cli.Command{
+	Name:  "migrate",
+	Usage: `Manages the database schema migrations.`,
+	Subcommands: []*cli.Command{
+		{
+			Name:   "up",
+			Usage:  `Applies all pending migrations.`,
+			Flags:  []cli.Flag{&flags.Cfg},
+			Action: withMigrator(up),
+		},
+		{
+			Name:   "down",
+			Usage:  `Reverts the given number of applied migrations, or all of them if confirmed.`,
+			Flags:  []cli.Flag{&flags.Cfg, &stepsFlag, &allFlag},
+			Action: withMigrator(down),
+		},
+		{
+			Name:   "version",
+			Usage:  `Prints the version of the database schema.`,
+			Flags:  []cli.Flag{&flags.Cfg},
+			Action: withMigrator(version),
+		},
+		{
+			Name:      "force",
+			Usage:     `Sets the version of the database schema without running any migration.`,
+			ArgsUsage: "<version>",
+			Flags:     []cli.Flag{&flags.Cfg},
+			Action:    withMigrator(force),
+		},
+		{
+			Name:   "status",
+			Usage:  `Lists all known migrations and whether they are applied.`,
+			Flags:  []cli.Flag{&flags.Cfg},
+			Action: withMigrator(status),
+		},
+	},
+}
+
+// withMigrator wraps the given migration action with the migrator of the configured database.
+func withMigrator(action func(*cli.Context, *db.Migrator) error) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		cfg := config.Load(ctx)
+		log := logger.New(ctx.App.Writer, ctx.App.HelpName, cfg.Logger.LoggingLevel)
+		mg, err := db.NewMigrator(&cfg.DB, log)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := mg.Close(); err != nil {
+				log.Errorf("failed to close the database migrator; %s", err)
+			}
+		}()
+		return action(ctx, mg)
+	}
+}
+
+func up(ctx *cli.Context, mg *db.Migrator) error {
+	if err := mg.Up(); err != nil {
+		return err
+	}
+	return version(ctx, mg)
+}
+
+func down(ctx *cli.Context, mg *db.Migrator) error {
+	if ctx.Bool(allFlag.Name) {
+		if err := confirmDownAll(ctx); err != nil {
+			return err
+		}
+		if err := mg.DownAll(); err != nil {
+			return err
+		}
+		return version(ctx, mg)
+	}
+
+	steps := ctx.Uint(stepsFlag.Name)
+	if steps == 0 {
+		return fmt.Errorf("the number of migrations to revert is required, use --%s, or --%s to revert all of them", stepsFlag.Name, allFlag.Name)
+	}
+	if err := mg.Down(int(steps)); err != nil {
+		return err
+	}
+	return version(ctx, mg)
+}
+
+// confirmDownAll asks the user to confirm all the data are going to be dropped.
+func confirmDownAll(ctx *cli.Context) error {
+	if _, err := fmt.Fprint(ctx.App.Writer, "All migrations are going to be reverted and all the data dropped. Type \"yes\" to continue: "); err != nil {
+		return err
+	}
+	answer, err := bufio.NewReader(ctx.App.Reader).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("revert of all migrations not confirmed; %s", err)
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		return fmt.Errorf("revert of all migrations not confirmed")
+	}
+	return nil
+}
+
+func version(ctx *cli.Context, mg *db.Migrator) error {
+	v, dirty, err := mg.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		_, err = fmt.Fprintf(ctx.App.Writer, "%d (dirty)\n", v)
+		return err
+	}
+	_, err = fmt.Fprintf(ctx.App.Writer, "%d\n", v)
+	return err
+}
+
+func force(ctx *cli.Context, mg *db.Migrator) error {
+	v, err := strconv.Atoi(ctx.Args().First())
+	if err != nil || ctx.NArg() != 1 {
+		return fmt.Errorf("expected a single schema version, got %q", ctx.Args().Slice())
+	}
+	if err := mg.Force(v); err != nil {
+		return err
+	}
+	return version(ctx, mg)
+}
+
+func status(ctx *cli.Context, mg *db.Migrator) error {
+	list, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(ctx.App.Writer)
+	for i := range list {
+		if err := enc.Encode(list[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
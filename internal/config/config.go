@@ -9,7 +9,10 @@ type Config struct {
 	Rpc             Rpc
 	Api             ApiServer
 	Logger          Logging
-	GasMonetization GasMonetization
+	GasMonetization []GasMonetization
+	Metadata        Metadata
+	Attribution     Attribution
+	Partitions      Partitions
 	Slack           Slack
 	AppName         string
 }
@@ -19,6 +22,9 @@ type Rpc struct {
 	TracingRpcUrl string
 }
 
+// GasMonetization is a configuration of a single gas monetization contract deployment.
+// Multiple deployments can be indexed side by side, e.g. during a contract migration.
+// Projects, transactions and withdrawals are scoped by the contract address.
 type GasMonetization struct {
 	StartFromBlock uint64
 	// address of the gas monetization contract
@@ -27,12 +33,75 @@ type GasMonetization struct {
 	DataProviderPK string
 }
 
+// Attribution modes of the delegated call gas.
+const (
+	// AttributeDelegateCallToProxy attributes gas of a delegated call to the contract delegating the call.
+	AttributeDelegateCallToProxy = "proxy"
+	// AttributeDelegateCallToImplementation attributes gas of a delegated call to the contract executed.
+	AttributeDelegateCallToImplementation = "implementation"
+)
+
+// Attribution is a configuration of the attribution of transaction call frames gas to projects.
+type Attribution struct {
+	// contract the gas of DELEGATECALL and CALLCODE frames is attributed to, proxy or implementation
+	DelegateCall string
+	// exclude gas of STATICCALL frames
+	ExcludeStaticCalls bool
+	// exclude gas of contract creation frames, it is attributed to the created contract otherwise
+	ExcludeCreations bool
+}
+
+// Metadata is a configuration of the project metadata fetcher.
+type Metadata struct {
+	// interval of checks for metadata due to be fetched in seconds
+	Interval int
+	// timeout of a single metadata request in seconds
+	Timeout int
+	// maximal size of the metadata document in bytes
+	MaxSize int64
+	// delay of the first retry of a failed fetch in seconds, it doubles with each further failure
+	RetryDelay int
+	// maximal delay between retries of a failed fetch in seconds
+	MaxRetryDelay int
+	// age of metadata of active projects in seconds after which the metadata are fetched again
+	RefreshInterval int
+	// HTTP gateways resolving ipfs:// URIs, tried in the given order
+	IpfsGateways []string
+	// HTTP gateways resolving ar:// URIs, tried in the given order
+	ArweaveGateways []string
+	// maximal number of projects fetched in a single check
+	BatchSize int
+	// allows metadata hosts resolving to private, loopback and reserved addresses, e.g. in development
+	AllowPrivateHosts bool
+}
+
+// Partitions is a configuration of the epoch partitions of the transaction table.
+type Partitions struct {
+	// number of epochs stored in a single partition
+	Epochs uint64
+	// number of partitions created ahead of the partition of the current epoch
+	Ahead uint64
+	// interval of the partition maintenance in seconds
+	Interval int
+	// number of past epochs the transactions are kept for, all of them are kept if zero;
+	// the stats rebuilt afterwards count only the transactions kept
+	RetentionEpochs uint64
+	// detach the expired partitions instead of dropping them, so they can be archived
+	DetachExpired bool
+}
+
 type DB struct {
 	User     string
 	Password string
 	Host     string
 	Port     string
 	Name     string
+	// migrate the database schema on start, the migrate command is expected to be run on deploy otherwise
+	AutoMigrate bool
+	// connection strings of read replicas serving the API reads, the primary serves them if empty
+	Replicas []string
+	// interval of the replica health checks in seconds
+	ReplicaCheckInterval int
 }
 
 type ApiServer struct {
@@ -5,6 +5,13 @@ import (
 	"github.com/spf13/viper"
 )
 
+// defaults of a gas monetization contract deployment; the list of deployments can not be defaulted
+// by viper key by key, so the defaults are applied to each entry after the configuration is loaded
+const (
+	defaultGasMonetizationContractAddress = "0x9f6089633272C23cFD6E9C146b6E87cc9f065718"
+	defaultGasMonetizationDataProviderPK  = "904d5dea0bdffb09d78a81c15f0b3b893f504679eb8cd1de585309cad58e6285"
+)
+
 func applyDefaults(cfg *viper.Viper) {
 	// db
 	cfg.SetDefault("db.user", "root")
@@ -12,6 +19,9 @@ func applyDefaults(cfg *viper.Viper) {
 	cfg.SetDefault("db.host", "localhost")
 	cfg.SetDefault("db.port", 5432)
 	cfg.SetDefault("db.name", "gas_monetization")
+	cfg.SetDefault("db.autoMigrate", true)
+	cfg.SetDefault("db.replicas", []string{})
+	cfg.SetDefault("db.replicaCheckInterval", 10)
 
 	// logger
 	cfg.SetDefault("logger.loggingLevel", logging.INFO)
@@ -21,10 +31,29 @@ func applyDefaults(cfg *viper.Viper) {
 	cfg.SetDefault("rpc.operaRpcUrl", "https://rpcapi.fantom.network")
 	cfg.SetDefault("rpc.tracingRpcUrl", "https://rpcapi-tracing.fantom.network")
 
-	// gas monetization
-	cfg.SetDefault("gasMonetization.contractAddress", "0x9f6089633272C23cFD6E9C146b6E87cc9f065718")
-	cfg.SetDefault("gasMonetization.dataProviderPK", "904d5dea0bdffb09d78a81c15f0b3b893f504679eb8cd1de585309cad58e6285")
-	cfg.SetDefault("gasMonetization.startFromBlock", 0)
+	// gas attribution
+	cfg.SetDefault("attribution.delegateCall", AttributeDelegateCallToProxy)
+	cfg.SetDefault("attribution.excludeStaticCalls", false)
+	cfg.SetDefault("attribution.excludeCreations", false)
+
+	// transaction partitions
+	cfg.SetDefault("partitions.epochs", 1000)
+	cfg.SetDefault("partitions.ahead", 2)
+	cfg.SetDefault("partitions.interval", 60*60)
+	cfg.SetDefault("partitions.retentionEpochs", 0)
+	cfg.SetDefault("partitions.detachExpired", false)
+
+	// metadata fetcher
+	cfg.SetDefault("metadata.interval", 15)
+	cfg.SetDefault("metadata.timeout", 10)
+	cfg.SetDefault("metadata.maxSize", 256*1024)
+	cfg.SetDefault("metadata.retryDelay", 60)
+	cfg.SetDefault("metadata.maxRetryDelay", 6*60*60)
+	cfg.SetDefault("metadata.refreshInterval", 24*60*60)
+	cfg.SetDefault("metadata.ipfsGateways", []string{"https://ipfs.io/ipfs/", "https://dweb.link/ipfs/"})
+	cfg.SetDefault("metadata.arweaveGateways", []string{"https://arweave.net/"})
+	cfg.SetDefault("metadata.batchSize", 50)
+	cfg.SetDefault("metadata.allowPrivateHosts", false)
 
 	// apiserver server
 	cfg.SetDefault("api.readTimeout", 2)
@@ -36,3 +65,20 @@ func applyDefaults(cfg *viper.Viper) {
 	cfg.SetDefault("api.domainAddress", "localhost:16761")
 	cfg.SetDefault("api.corsOrigin", []string{"*"})
 }
+
+// applyGasMonetizationDefaults fills the values missing in the configured gas monetization deployments,
+// a single default deployment is used if there is none. A single object is accepted in place of the list.
+func applyGasMonetizationDefaults(config *Config) {
+	if len(config.GasMonetization) == 0 {
+		config.GasMonetization = []GasMonetization{{}}
+	}
+	for i := range config.GasMonetization {
+		gm := &config.GasMonetization[i]
+		if gm.ContractAddress == "" {
+			gm.ContractAddress = defaultGasMonetizationContractAddress
+		}
+		if gm.DataProviderPK == "" {
+			gm.DataProviderPK = defaultGasMonetizationDataProviderPK
+		}
+	}
+}
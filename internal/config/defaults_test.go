@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestGasMonetizationDefaults(t *testing.T) {
+	cfg := viper.New()
+	cfg.SetConfigType("json")
+	applyDefaults(cfg)
+	err := cfg.ReadConfig(strings.NewReader(`{"gasMonetization": [
+		{"contractAddress": "0x0000000000000000000000000000000000000001", "startFromBlock": 10},
+		{"contractAddress": "0x0000000000000000000000000000000000000002", "dataProviderPK": "01"}
+	]}`))
+	assert.Nil(t, err)
+
+	var config Config
+	assert.Nil(t, cfg.Unmarshal(&config))
+	applyGasMonetizationDefaults(&config)
+	assert.Nil(t, validate(&config))
+
+	// each entry gets the defaults of the values it does not configure
+	if assert.Len(t, config.GasMonetization, 2) {
+		assert.Equal(t, defaultGasMonetizationDataProviderPK, config.GasMonetization[0].DataProviderPK)
+		assert.EqualValues(t, 10, config.GasMonetization[0].StartFromBlock)
+		assert.Equal(t, "01", config.GasMonetization[1].DataProviderPK)
+	}
+
+	// the default deployment is used if there is none
+	config.GasMonetization = nil
+	applyGasMonetizationDefaults(&config)
+	if assert.Len(t, config.GasMonetization, 1) {
+		assert.Equal(t, defaultGasMonetizationContractAddress, config.GasMonetization[0].ContractAddress)
+	}
+}
@@ -18,6 +18,11 @@ func Load(ctx *cli.Context) *Config {
 	if err = cfg.Unmarshal(&config); err != nil {
 		log.Fatalf("can not extract configuration. Err: %v", err)
 	}
+	applyGasMonetizationDefaults(&config)
+
+	if err = validate(&config); err != nil {
+		log.Fatalf("invalid configuration. Err: %v", err)
+	}
 
 	return &config
 }
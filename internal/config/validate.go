@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validate checks the configuration values the services can not run with.
+func validate(config *Config) error {
+	deployments := make(map[string]bool, len(config.GasMonetization))
+	for _, gm := range config.GasMonetization {
+		address := strings.ToLower(gm.ContractAddress)
+		if deployments[address] {
+			return fmt.Errorf("gas monetization contract %s is configured more than once", gm.ContractAddress)
+		}
+		deployments[address] = true
+	}
+	if len(config.DB.Replicas) > 0 && config.DB.ReplicaCheckInterval <= 0 {
+		return fmt.Errorf("db.replicaCheckInterval must be positive, %d given", config.DB.ReplicaCheckInterval)
+	}
+	if config.Metadata.Interval <= 0 {
+		return fmt.Errorf("metadata.interval must be positive, %d given", config.Metadata.Interval)
+	}
+	if config.Metadata.BatchSize <= 0 {
+		return fmt.Errorf("metadata.batchSize must be positive, %d given", config.Metadata.BatchSize)
+	}
+	// the block dispatcher and the partition maintainer MUST agree on the partition size
+	if config.Partitions.Epochs == 0 {
+		return fmt.Errorf("partitions.epochs must be positive")
+	}
+	if config.Partitions.Interval <= 0 {
+		return fmt.Errorf("partitions.interval must be positive, %d given", config.Partitions.Interval)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidatePartitions(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			Metadata:   Metadata{Interval: 60, BatchSize: 50},
+			Partitions: Partitions{Epochs: 1000, Interval: 3600},
+		}
+	}
+	assert.Nil(t, validate(valid()))
+
+	// the block dispatcher and the partition maintainer can not run with an empty partition size
+	cfg := valid()
+	cfg.Partitions.Epochs = 0
+	assert.ErrorContains(t, validate(cfg), "partitions.epochs")
+
+	// the partition maintainer can not tick without a positive interval
+	cfg = valid()
+	cfg.Partitions.Interval = 0
+	assert.ErrorContains(t, validate(cfg), "partitions.interval")
+}
+
+func TestValidateMetadata(t *testing.T) {
+	cfg := &Config{
+		Metadata:   Metadata{Interval: 60},
+		Partitions: Partitions{Epochs: 1000, Interval: 3600},
+	}
+	// the metadata fetcher would never fetch anything without a positive batch size
+	assert.ErrorContains(t, validate(cfg), "metadata.batchSize")
+}
@@ -0,0 +1,100 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/graphql"
+)
+
+type ContractEvent struct {
+	Contract    types.Address
+	BlockNumber graphql.Long
+	TxHash      common.Hash
+	LogIndex    graphql.Long
+	Topic       *common.Hash
+	Name        *string
+	Data        hexutil.Bytes
+	Arguments   *string
+	Outcome     string
+	Error       *string
+}
+
+// ContractEventsArgs represents filters of the contract events list.
+type ContractEventsArgs struct {
+	Contract  *common.Address
+	TxHash    *common.Hash
+	Name      *string
+	Outcome   *string
+	FromBlock *graphql.Long
+	ToBlock   *graphql.Long
+	// AfterBlock and AfterLogIndex represent the last event of the previous page.
+	AfterBlock    *graphql.Long
+	AfterLogIndex *graphql.Long
+	Limit         int32
+}
+
+// maxContractEventsLimit is the maximal number of contract events returned at once.
+const maxContractEventsLimit = 1000
+
+// ContractEvents provides list of raw events emitted by the gas monetization contracts, a page at a time
+func (rs *RootResolver) ContractEvents(ctx context.Context, args ContractEventsArgs) (out []ContractEvent, err error) {
+	if args.Limit <= 0 || args.Limit > maxContractEventsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxContractEventsLimit)
+	}
+	if (args.AfterBlock == nil) != (args.AfterLogIndex == nil) {
+		return nil, fmt.Errorf("afterBlock and afterLogIndex must be given together")
+	}
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	filter := storage.ContractEventFilter{Name: args.Name, Outcome: args.Outcome, Limit: uint64(args.Limit)}
+	if args.AfterBlock != nil {
+		filter.After = &storage.EventPosition{
+			BlockNumber: uint64(*args.AfterBlock),
+			LogIndex:    uint64(*args.AfterLogIndex),
+		}
+	}
+	if args.Contract != nil {
+		filter.Contract = &types.Address{Address: *args.Contract}
+	}
+	if args.TxHash != nil {
+		filter.TxHash = &types.Hash{Hash: *args.TxHash}
+	}
+	if args.FromBlock != nil {
+		from := uint64(*args.FromBlock)
+		filter.FromBlock = &from
+	}
+	if args.ToBlock != nil {
+		to := uint64(*args.ToBlock)
+		filter.ToBlock = &to
+	}
+	list, err := rs.storage().FindContractEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(list); i++ {
+		data, err := hexutil.Decode(list[i].Data)
+		if err != nil {
+			return nil, err
+		}
+		event := ContractEvent{
+			Contract:    *list[i].ContractAddress,
+			BlockNumber: graphql.Long(list[i].BlockNumber),
+			TxHash:      list[i].TxHash.Hash,
+			LogIndex:    graphql.Long(list[i].LogIndex),
+			Name:        list[i].Name,
+			Data:        data,
+			Arguments:   list[i].Arguments,
+			Outcome:     list[i].Outcome,
+			Error:       list[i].Error,
+		}
+		if list[i].Topic != nil {
+			event.Topic = &list[i].Topic.Hash
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
@@ -9,3 +9,8 @@ import (
 func (rs *RootResolver) GasMonetizationAddress() common.Address {
 	return repository.R().GasMonetizationAddress()
 }
+
+// GasMonetizationContracts returns the addresses of all watched gas monetization contracts.
+func (rs *RootResolver) GasMonetizationContracts() []common.Address {
+	return repository.R().GasMonetizationAddresses()
+}
@@ -1,14 +1,17 @@
 package resolvers
 
 import (
-	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository"
+	"context"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/graphql"
 )
 
 type Project struct {
 	Id                graphql.Long  `db:"id"`
 	ProjectId         graphql.Long  `db:"project_id"`
+	Contract          types.Address `db:"contract_address"`
 	OwnerAddress      types.Address `db:"owner_address"`
 	ReceiverAddress   types.Address `db:"receiver_address"`
 	CollectedRewards  graphql.Long  `db:"collected_rewards"`
@@ -18,15 +21,26 @@ type Project struct {
 	Url               string        `db:"url"`
 	ImageUrl          string        `db:"image_url"`
 	TransactionsCount graphql.Long  `db:"transactions_count"`
+	ProjectMetadata
+	// storage is the storage the project was loaded from, its details are resolved from the same one.
+	storage storage.Storage
 }
 
-// Projects provides list of projects
-func (rs *RootResolver) Projects() (out []Project, err error) {
-	query := repository.R().ProjectQuery()
-	if err != nil {
-		return nil, err
+// Projects provides list of projects, optionally limited to the given gas monetization contract,
+// metadata category and metadata tag
+func (rs *RootResolver) Projects(ctx context.Context, args struct {
+	Contract *common.Address
+	Category *string
+	Tag      *string
+}) (out []Project, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	filter := storage.ProjectFilter{Category: args.Category, Tag: args.Tag}
+	if args.Contract != nil {
+		filter.Contract = &types.Address{Address: *args.Contract}
 	}
-	list, err := query.GetAll()
+	st := rs.storage()
+	list, err := st.FindProjects(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +48,7 @@ func (rs *RootResolver) Projects() (out []Project, err error) {
 		out = append(out, Project{
 			Id:                graphql.Long(list[i].Id),
 			ProjectId:         graphql.Long(list[i].ProjectId),
+			Contract:          *list[i].ContractAddress,
 			OwnerAddress:      *list[i].OwnerAddress,
 			ReceiverAddress:   *list[i].ReceiverAddress,
 			CollectedRewards:  graphql.Long(list[i].CollectedRewards.ToInt().Uint64()),
@@ -43,27 +58,27 @@ func (rs *RootResolver) Projects() (out []Project, err error) {
 			Url:               list[i].Url,
 			ImageUrl:          list[i].ImageUrl,
 			TransactionsCount: graphql.Long(list[i].TransactionsCount),
+			ProjectMetadata:   newProjectMetadata(&list[i]),
+			storage:           st,
 		})
 	}
 	return out, nil
 }
 
-func (pr Project) Contracts() (out []ProjectContract, err error) {
-	query := repository.R().ProjectContractQuery()
-	if err != nil {
-		return nil, err
-	}
-	list, err := query.WhereProjectId(int64(pr.Id)).GetAll()
+// Contracts provides list of the project contracts, removed contracts are included on demand only
+func (pr Project) Contracts(ctx context.Context, args struct{ IncludeRemoved bool }) (out []ProjectContract, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	id := int64(pr.Id)
+	list, err := pr.storage.FindProjectContracts(ctx, storage.ProjectContractFilter{
+		ProjectId:  &id,
+		NotRemoved: !args.IncludeRemoved,
+	})
 	if err != nil {
 		return nil, err
 	}
 	for i := 0; i < len(list); i++ {
-		out = append(out, ProjectContract{
-			Id:        graphql.Long(list[i].Id),
-			ProjectId: graphql.Long(list[i].ProjectId),
-			Address:   *list[i].Address,
-			Approved:  list[i].Approved,
-		})
+		out = append(out, newProjectContract(&list[i]))
 	}
 	return out, nil
 }
@@ -0,0 +1,44 @@
+package resolvers
+
+import (
+	"context"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
+	"github.com/ethereum/go-ethereum/graphql"
+)
+
+type ProjectActivityPeriod struct {
+	FromEpoch graphql.Long
+	ToEpoch   *graphql.Long
+}
+
+// ActivityPeriods provides list of periods the project was active in
+func (pr Project) ActivityPeriods(ctx context.Context) (out []ProjectActivityPeriod, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	list, err := pr.storage.FindProjectActivityPeriods(ctx, int64(pr.Id))
+	if err != nil {
+		return nil, err
+	}
+	out = make([]ProjectActivityPeriod, 0, len(list))
+	for i := 0; i < len(list); i++ {
+		period := ProjectActivityPeriod{FromEpoch: graphql.Long(list[i].FromEpoch)}
+		if list[i].ToEpoch != nil {
+			to := graphql.Long(*list[i].ToEpoch)
+			period.ToEpoch = &to
+		}
+		out = append(out, period)
+	}
+	return out, nil
+}
+
+// ActiveInEpoch resolves whether the project was active in the given epoch
+func (pr Project) ActiveInEpoch(ctx context.Context, args struct{ Epoch graphql.Long }) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	id, epoch := int64(pr.Id), uint64(args.Epoch)
+	list, err := pr.storage.FindProjects(ctx, storage.ProjectFilter{Id: &id, ActiveInEpoch: &epoch})
+	if err != nil {
+		return false, err
+	}
+	return len(list) > 0, nil
+}
@@ -1,35 +1,54 @@
 package resolvers
 
 import (
-	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository"
+	"context"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
 	"github.com/ethereum/go-ethereum/graphql"
 )
 
 type ProjectContract struct {
-	Id        graphql.Long  `db:"id"`
-	ProjectId graphql.Long  `db:"project_id"`
-	Address   types.Address `db:"address"`
-	Approved  bool          `db:"is_approved"`
+	Id             graphql.Long  `db:"id"`
+	ProjectId      graphql.Long  `db:"project_id"`
+	Address        types.Address `db:"address"`
+	Approved       bool          `db:"is_approved"`
+	AddedAtEpoch   graphql.Long  `db:"added_at_epoch"`
+	AddedAtBlock   graphql.Long  `db:"added_at_block"`
+	RemovedAtEpoch *graphql.Long `db:"removed_at_epoch"`
+	RemovedAtBlock *graphql.Long `db:"removed_at_block"`
 }
 
-// Contracts provides list of contracts
-func (rs *RootResolver) Contracts() (out []ProjectContract, err error) {
-	query := repository.R().ProjectContractQuery()
-	if err != nil {
-		return nil, err
+// newProjectContract creates a new project contract resolver of the given project contract.
+func newProjectContract(pc *types.ProjectContract) ProjectContract {
+	out := ProjectContract{
+		Id:           graphql.Long(pc.Id),
+		ProjectId:    graphql.Long(pc.ProjectId),
+		Address:      *pc.Address,
+		Approved:     pc.Approved,
+		AddedAtEpoch: graphql.Long(pc.AddedAtEpoch),
+		AddedAtBlock: graphql.Long(pc.AddedAtBlock),
+	}
+	if pc.RemovedAtEpoch != nil {
+		epoch := graphql.Long(*pc.RemovedAtEpoch)
+		out.RemovedAtEpoch = &epoch
 	}
-	list, err := query.GetAll()
+	if pc.RemovedAtBlock != nil {
+		block := graphql.Long(*pc.RemovedAtBlock)
+		out.RemovedAtBlock = &block
+	}
+	return out
+}
+
+// Contracts provides list of contracts
+func (rs *RootResolver) Contracts(ctx context.Context) (out []ProjectContract, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	list, err := rs.storage().FindProjectContracts(ctx, storage.ProjectContractFilter{})
 	if err != nil {
 		return nil, err
 	}
 	for i := 0; i < len(list); i++ {
-		out = append(out, ProjectContract{
-			Id:        graphql.Long(list[i].Id),
-			ProjectId: graphql.Long(list[i].ProjectId),
-			Address:   *list[i].Address,
-			Approved:  list[i].Approved,
-		})
+		out = append(out, newProjectContract(&list[i]))
 	}
 	return out, nil
 }
@@ -0,0 +1,39 @@
+package resolvers
+
+import (
+	"context"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/graphql"
+)
+
+type ProjectHistory struct {
+	Attribute   string
+	OldValue    *string
+	NewValue    string
+	BlockNumber graphql.Long
+	Epoch       graphql.Long
+	TxHash      common.Hash
+}
+
+// History provides list of changes of the project attributes
+func (pr Project) History(ctx context.Context, args struct{ Attribute *string }) (out []ProjectHistory, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	id := int64(pr.Id)
+	list, err := pr.storage.FindProjectHistory(ctx, storage.ProjectHistoryFilter{ProjectId: &id, Attribute: args.Attribute})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(list); i++ {
+		out = append(out, ProjectHistory{
+			Attribute:   list[i].Attribute,
+			OldValue:    list[i].OldValue,
+			NewValue:    list[i].NewValue,
+			BlockNumber: graphql.Long(list[i].BlockNumber),
+			Epoch:       graphql.Long(list[i].Epoch),
+			TxHash:      list[i].TxHash.Hash,
+		})
+	}
+	return out, nil
+}
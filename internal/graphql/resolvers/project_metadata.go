@@ -0,0 +1,91 @@
+package resolvers
+
+import (
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
+	"sort"
+)
+
+// ProjectMetadata represents the metadata fields of the project resolver.
+type ProjectMetadata struct {
+	Description              *string
+	Website                  *string
+	Category                 *string
+	Tags                     []string
+	Logo                     *ProjectLogo
+	Socials                  []ProjectSocial
+	Contact                  *ProjectContact
+	MetadataVersion          *int32
+	MetadataStatus           string
+	MetadataValidationErrors []string
+}
+
+type ProjectLogo struct {
+	Small  *string
+	Medium *string
+	Large  *string
+	Svg    *string
+}
+
+type ProjectSocial struct {
+	Network string
+	Url     string
+}
+
+type ProjectContact struct {
+	Email *string
+	Url   *string
+}
+
+// newProjectMetadata creates the metadata fields of the project resolver of the given project.
+func newProjectMetadata(p *types.Project) ProjectMetadata {
+	out := ProjectMetadata{
+		Tags:                     []string{},
+		Socials:                  []ProjectSocial{},
+		MetadataStatus:           p.MetadataStatus,
+		MetadataValidationErrors: []string(p.MetadataValidationErrors),
+	}
+	if out.MetadataValidationErrors == nil {
+		out.MetadataValidationErrors = []string{}
+	}
+	m := p.Metadata
+	if m == nil {
+		return out
+	}
+	version := int32(m.Version)
+	out.MetadataVersion = &version
+	out.Description = optional(m.Description)
+	out.Website = optional(m.Website)
+	out.Category = optional(m.Category)
+	if m.Tags != nil {
+		out.Tags = m.Tags
+	}
+	if m.Logo != nil {
+		out.Logo = &ProjectLogo{
+			Small:  optional(m.Logo.Small),
+			Medium: optional(m.Logo.Medium),
+			Large:  optional(m.Logo.Large),
+			Svg:    optional(m.Logo.Svg),
+		}
+	}
+	for network, url := range m.Socials {
+		out.Socials = append(out.Socials, ProjectSocial{Network: network, Url: url})
+	}
+	sort.Slice(out.Socials, func(i, j int) bool {
+		return out.Socials[i].Network < out.Socials[j].Network
+	})
+	if m.Contact != nil {
+		out.Contact = &ProjectContact{
+			Email: optional(m.Contact.Email),
+			Url:   optional(m.Contact.Url),
+		}
+	}
+	return out
+}
+
+// optional returns nil for an empty string, so missing values are resolved as null.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,36 @@
+package resolvers
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+type ProjectMetadataVersion struct {
+	Uri           string
+	ContentHash   common.Hash
+	Content       string
+	SchemaVersion int32
+	FetchedAt     gql.Time
+}
+
+// MetadataVersions provides list of distinct metadata documents of the project ordered from the newest
+func (pr Project) MetadataVersions(ctx context.Context) (out []ProjectMetadataVersion, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	list, err := pr.storage.FindProjectMetadataVersions(ctx, int64(pr.Id))
+	if err != nil {
+		return nil, err
+	}
+	// the versions are stored from the oldest
+	for i := len(list) - 1; i >= 0; i-- {
+		out = append(out, ProjectMetadataVersion{
+			Uri:           list[i].Uri,
+			ContentHash:   list[i].ContentHash.Hash,
+			Content:       list[i].Content,
+			SchemaVersion: int32(list[i].SchemaVersion),
+			FetchedAt:     gql.Time{Time: list[i].FetchedAt},
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+package resolvers
+
+import (
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/svc"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/graphql"
+)
+
+type RewardExplanation struct {
+	Hash              common.Hash
+	BlockNumber       graphql.Long
+	Epoch             graphql.Long
+	Source            string
+	GasPrice          hexutil.Big
+	EffectiveGasPrice hexutil.Big
+	BaseFee           *hexutil.Big
+	GasPriceSource    string
+	RewardsPercentage graphql.Long
+	Reward            hexutil.Big
+	Frames            []RewardExplanationFrame
+}
+
+type RewardExplanationFrame struct {
+	Path     string
+	CallType string
+	From     *common.Address
+	To       *common.Address
+	Target   *common.Address
+	Selector *string
+	GasUsed  *graphql.Long
+	SelfGas  graphql.Long
+	Error    *string
+	Watched  bool
+	Projects []RewardExplanationProject
+	Reward   *hexutil.Big
+}
+
+type RewardExplanationProject struct {
+	Contract  common.Address
+	ProjectId graphql.Long
+}
+
+// RewardExplanationArgs represents the transaction to be explained.
+type RewardExplanationArgs struct {
+	TxHash common.Hash
+}
+
+// RewardExplanation provides breakdown of the gas attribution and rewards of the given transaction
+func (rs *RootResolver) RewardExplanation(args RewardExplanationArgs) (*RewardExplanation, error) {
+	exp, err := svc.ExplainTransaction(repository.R().Replica(), &cfg.Attribution, args.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	out := RewardExplanation{
+		Hash:              exp.Hash,
+		BlockNumber:       graphql.Long(exp.BlockNumber),
+		Epoch:             graphql.Long(exp.Epoch),
+		Source:            exp.Source,
+		GasPrice:          bigOrZero(exp.GasPrice),
+		EffectiveGasPrice: bigOrZero(exp.EffectiveGasPrice),
+		GasPriceSource:    exp.GasPriceSource,
+		RewardsPercentage: graphql.Long(exp.RewardsPercentage),
+		Reward:            bigOrZero(exp.Reward),
+	}
+	if exp.BaseFee != nil {
+		out.BaseFee = &exp.BaseFee.Big
+	}
+	for _, f := range exp.Frames {
+		frame := RewardExplanationFrame{
+			Path:     f.Path,
+			CallType: f.CallType,
+			From:     f.From,
+			To:       f.To,
+			Target:   f.Target,
+			Selector: f.Selector,
+			SelfGas:  graphql.Long(f.SelfGas),
+			Error:    f.Error,
+			Watched:  f.Watched,
+			Projects: make([]RewardExplanationProject, 0, len(f.Projects)),
+		}
+		if f.GasUsed != nil {
+			gas := graphql.Long(*f.GasUsed)
+			frame.GasUsed = &gas
+		}
+		if f.Reward != nil {
+			frame.Reward = &f.Reward.Big
+		}
+		for _, p := range f.Projects {
+			frame.Projects = append(frame.Projects, RewardExplanationProject{
+				Contract:  p.Contract,
+				ProjectId: graphql.Long(p.ProjectId),
+			})
+		}
+		out.Frames = append(out.Frames, frame)
+	}
+	return &out, nil
+}
+
+// bigOrZero provides value of the given amount, zero if not known
+func bigOrZero(v *types.Big) hexutil.Big {
+	if v == nil {
+		return hexutil.Big{}
+	}
+	return v.Big
+}
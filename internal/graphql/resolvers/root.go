@@ -2,12 +2,19 @@
 package resolvers
 
 import (
+	"context"
 	"fmt"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/config"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/logger"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
 	"sync"
+	"time"
 )
 
+// queryTimeoutDuration represents the maximal duration of the storage queries of a single resolver.
+const queryTimeoutDuration = 30 * time.Second
+
 // log represents the logger to be used by the repository.
 var log logger.AppLogger
 
@@ -22,7 +29,15 @@ var resolver RootResolver
 // oneInstance is the sync guarding root resolver singleton creation.
 var oneInstance sync.Once
 
-type RootResolver struct{}
+type RootResolver struct {
+	// storage provides the storage a request is resolved from, so the reads are spread over the replicas.
+	storage func() storage.Storage
+}
+
+// NewRootResolver creates a root resolver resolving the requests from the storage given by the provider.
+func NewRootResolver(provider func() storage.Storage) *RootResolver {
+	return &RootResolver{storage: provider}
+}
 
 // SetLogger sets the repository logger to be used to collect logging info.
 func SetLogger(l logger.AppLogger) {
@@ -53,9 +68,16 @@ func newResolver() *RootResolver {
 		panic(fmt.Errorf("missing logger"))
 	}
 
-	// create new resolver
-	rs := RootResolver{}
+	// create new resolver reading from the database replicas
+	rs := NewRootResolver(func() storage.Storage {
+		return repository.R().Replica().Storage()
+	})
 	log.Notice("GraphQL resolver started")
 
-	return &rs
+	return rs
+}
+
+// queryContext derives the context of the storage queries of a single resolver from the request context.
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, queryTimeoutDuration)
 }
@@ -1,33 +1,39 @@
 package resolvers
 
 import (
-	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository"
+	"context"
 	"github.com/ethereum/go-ethereum/graphql"
 )
 
 // TotalAmountClaimed provides total amount claimed tokens
-func (rs *RootResolver) TotalAmountClaimed() (out graphql.Long, err error) {
-	output, err := repository.R().TotalAmountClaimed()
+func (rs *RootResolver) TotalAmountClaimed(ctx context.Context) (out graphql.Long, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	stats, err := rs.storage().Stats(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return graphql.Long(output.Int64()), err
+	return graphql.Long(stats.TotalAmountClaimed.ToInt().Int64()), err
 }
 
 // TotalAmountCollected provides total amount collected tokens
-func (rs *RootResolver) TotalAmountCollected() (out graphql.Long, err error) {
-	output, err := repository.R().TotalAmountCollected()
+func (rs *RootResolver) TotalAmountCollected(ctx context.Context) (out graphql.Long, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	stats, err := rs.storage().Stats(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return graphql.Long(output.Int64()), err
+	return graphql.Long(stats.TotalAmountCollected.ToInt().Int64()), err
 }
 
 // TotalTransactionCount provides total amount collected tokens
-func (rs *RootResolver) TotalTransactionCount() (out graphql.Long, err error) {
-	output, err := repository.R().TotalTransactionsCount()
+func (rs *RootResolver) TotalTransactionCount(ctx context.Context) (out graphql.Long, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	stats, err := rs.storage().Stats(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return graphql.Long(output), err
+	return graphql.Long(stats.TotalTransactionsCount), err
 }
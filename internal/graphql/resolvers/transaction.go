@@ -0,0 +1,94 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/graphql"
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// maxTransactionsLimit is the maximal number of transactions returned at once.
+const maxTransactionsLimit = 1000
+
+type Transaction struct {
+	Hash              common.Hash
+	Contract          *common.Address
+	Project           graphql.Long
+	BlockNumber       graphql.Long
+	Epoch             graphql.Long
+	Timestamp         gql.Time
+	From              common.Address
+	To                *common.Address
+	TraceAddress      string
+	CallType          string
+	GasUsed           graphql.Long
+	EffectiveGasPrice hexutil.Big
+	Reward            hexutil.Big
+}
+
+// TransactionsArgs represents filters of the transactions list.
+type TransactionsArgs struct {
+	Contract *common.Address
+	Project  *graphql.Long
+	Epoch    *graphql.Long
+	Limit    int32
+}
+
+// Transactions provides list of rewarded transactions, optionally limited to the given gas monetization contract,
+// project and epoch
+func (rs *RootResolver) Transactions(ctx context.Context, args TransactionsArgs) (out []Transaction, err error) {
+	if args.Limit <= 0 || args.Limit > maxTransactionsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxTransactionsLimit)
+	}
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	filter := storage.TransactionFilter{Latest: uint64(args.Limit)}
+	if args.Contract != nil {
+		filter.Contract = &types.Address{Address: *args.Contract}
+	}
+	if args.Project != nil {
+		projectId := int64(*args.Project)
+		filter.ProjectId = &projectId
+	}
+	if args.Epoch != nil {
+		epoch := uint64(*args.Epoch)
+		filter.Epoch = &epoch
+	}
+	list, err := rs.storage().FindTransactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out = make([]Transaction, 0, len(list))
+	for i := 0; i < len(list); i++ {
+		trx := Transaction{
+			Hash:              list[i].Hash.Hash,
+			Project:           graphql.Long(list[i].ProjectId),
+			Epoch:             graphql.Long(list[i].Epoch),
+			Timestamp:         gql.Time{Time: list[i].Timestamp},
+			From:              list[i].From.Address,
+			TraceAddress:      list[i].TraceAddress,
+			CallType:          list[i].CallType,
+			EffectiveGasPrice: list[i].EffectiveGasPrice.Big,
+			Reward:            list[i].RewardToClaim.Big,
+		}
+		if list[i].ContractAddress != nil {
+			trx.Contract = &list[i].ContractAddress.Address
+		}
+		// the block number and the gas used are always stored, a missing value is reported as zero
+		if list[i].BlockNumber != nil {
+			trx.BlockNumber = graphql.Long(*list[i].BlockNumber)
+		}
+		if list[i].GasUsed != nil {
+			trx.GasUsed = graphql.Long(*list[i].GasUsed)
+		}
+		if list[i].To != nil {
+			trx.To = &list[i].To.Address
+		}
+		out = append(out, trx)
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package resolvers
+
+import (
+	"context"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/graphql"
+)
+
+type WithdrawalRequest struct {
+	Contract      *common.Address
+	Project       graphql.Long
+	RequestEpoch  graphql.Long
+	WithdrawEpoch *graphql.Long
+	Amount        *hexutil.Big
+	Recipient     *common.Address
+}
+
+// WithdrawalRequestsArgs represents filters of the withdrawal requests list.
+type WithdrawalRequestsArgs struct {
+	Contract *common.Address
+	Project  *graphql.Long
+	Pending  bool
+}
+
+// WithdrawalRequests provides list of withdrawal requests, optionally limited to the given gas monetization
+// contract and project, or to the pending requests
+func (rs *RootResolver) WithdrawalRequests(ctx context.Context, args WithdrawalRequestsArgs) (out []WithdrawalRequest, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	filter := storage.WithdrawalRequestFilter{NotWithdrawn: args.Pending}
+	if args.Contract != nil {
+		filter.Contract = &types.Address{Address: *args.Contract}
+	}
+	if args.Project != nil {
+		projectId := int64(*args.Project)
+		filter.ProjectId = &projectId
+	}
+	list, err := rs.storage().FindWithdrawalRequests(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out = make([]WithdrawalRequest, 0, len(list))
+	// the requests are stored from the oldest
+	for i := len(list) - 1; i >= 0; i-- {
+		request := WithdrawalRequest{
+			Project:      graphql.Long(list[i].ProjectId),
+			RequestEpoch: graphql.Long(list[i].RequestEpoch),
+		}
+		if list[i].ContractAddress != nil {
+			request.Contract = &list[i].ContractAddress.Address
+		}
+		if list[i].WithdrawEpoch != nil {
+			epoch := graphql.Long(*list[i].WithdrawEpoch)
+			request.WithdrawEpoch = &epoch
+		}
+		if list[i].Amount != nil {
+			request.Amount = &list[i].Amount.Big
+		}
+		if list[i].RecipientAddress != nil {
+			request.Recipient = &list[i].RecipientAddress.Address
+		}
+		out = append(out, request)
+	}
+	return out, nil
+}
@@ -25,12 +25,47 @@ scalar Cursor
 # Time represents date and time including time zone information in RFC3339 format.
 scalar Time
 
+type ContractEvent {
+    # Address of the gas monetization contract which emitted the event
+    contract: Address!
+
+    # Number of the block the event was emitted in
+    blockNumber: Long!
+
+    # Hash of the transaction the event was emitted in
+    txHash: Bytes32!
+
+    # Index of the log in the block
+    logIndex: Long!
+
+    # Event signature topic, null for anonymous logs
+    topic: Bytes32
+
+    # Name of the event, null if the topic is unknown to the contract ABI
+    name: String
+
+    # Raw data of the log
+    data: Bytes!
+
+    # Decoded arguments of the event encoded as JSON object
+    arguments: String
+
+    # Outcome of the event processing: processed, failed, ignored, unhandled or unknown
+    outcome: String!
+
+    # Error of the failed event handler
+    error: String
+}
+
 type Project {
     # Id of project
     id: Long!
 
-    # List of contracts
-    contracts: [ProjectContract!]!
+    # Address of the gas monetization contract the project is registered in
+    contract: Address!
+
+    # List of contracts, including the removed ones if requested
+    contracts(includeRemoved: Boolean = false): [ProjectContract!]!
 
     # Address of owner
     ownerAddress: Address!
@@ -58,7 +93,88 @@ type Project {
 
     # Amount of tokens for claim
     rewardsToClaim: Long!
+
+    # Description of project
+    description: String
+
+    # URL of the project website
+    website: String
+
+    # Category of project
+    category: String
+
+    # Tags of project
+    tags: [String!]!
+
+    # Variants of the project logo
+    logo: ProjectLogo
+
+    # Social network profiles of project ordered by network
+    socials: [ProjectSocial!]!
+
+    # Contact of the project team
+    contact: ProjectContact
+
+    # Version of the metadata schema the metadata conform to, null if no valid metadata were fetched yet
+    metadataVersion: Int
+
+    # Status of the metadata fetch, one of pending, ok, failed or invalid
+    metadataStatus: String!
+
+    # Violations of the metadata schema by the last fetched metadata document
+    metadataValidationErrors: [String!]!
+
+    # Distinct metadata documents of project ordered from the newest
+    metadataVersions: [ProjectMetadataVersion!]!
+
+    # Changes of owner, recipient and metadata URI ordered from the oldest, optionally of the given attribute only
+    history(attribute: String): [ProjectHistory!]!
+
+    # Periods the project was active in ordered from the oldest
+    activityPeriods: [ProjectActivityPeriod!]!
+
+    # Whether the project was active in the given epoch
+    activeInEpoch(epoch: Long!): Boolean!
 }
+
+type ProjectLogo {
+    # URL of the small logo
+    small: String
+
+    # URL of the medium logo
+    medium: String
+
+    # URL of the large logo
+    large: String
+
+    # URL of the vector logo
+    svg: String
+}
+
+type ProjectSocial {
+    # Name of the social network
+    network: String!
+
+    # URL of the project profile
+    url: String!
+}
+
+type ProjectContact {
+    # Email of the project team
+    email: String
+
+    # URL of the contact page
+    url: String
+}
+
+type ProjectActivityPeriod {
+    # First epoch the project was active in
+    fromEpoch: Long!
+
+    # Epoch the project was suspended in, null while the project is active
+    toEpoch: Long
+}
+
 type ProjectContract {
     # Id of contract
     id: Long!
@@ -71,7 +187,200 @@ type ProjectContract {
 
     # approved contract
     approved: Boolean!
+
+    # epoch the contract was added to the project in
+    addedAtEpoch: Long!
+
+    # block the contract was added to the project in
+    addedAtBlock: Long!
+
+    # epoch the contract was removed from the project in, null while it is a member
+    removedAtEpoch: Long
+
+    # block the contract was removed from the project in, null while it is a member
+    removedAtBlock: Long
+}
+type ProjectHistory {
+    # Changed attribute: owner, recipient or metadata_uri
+    attribute: String!
+
+    # Value before the change, null when the project was added
+    oldValue: String
+
+    # Value after the change
+    newValue: String!
+
+    # Number of the block the change was made in
+    blockNumber: Long!
+
+    # Epoch the change was made in
+    epoch: Long!
+
+    # Hash of the transaction which made the change
+    txHash: Bytes32!
 }
+
+type ProjectMetadataVersion {
+    # URI the document was fetched from
+    uri: String!
+
+    # Keccak256 hash of the raw document
+    contentHash: Bytes32!
+
+    # Raw document as it was served
+    content: String!
+
+    # Version of the metadata schema the document conforms to
+    schemaVersion: Int!
+
+    # Time the document was fetched first
+    fetchedAt: Time!
+}
+
+# RewardExplanation represents the breakdown of the gas attribution and rewards of a single transaction.
+type RewardExplanation {
+    # Hash of the transaction
+    hash: Bytes32!
+
+    # Number of the block the transaction was included in
+    blockNumber: Long!
+
+    # Epoch the transaction was rewarded in
+    epoch: Long!
+
+    # "stored" for processed transactions carrying their stored rewards, "trace" for the live attribution
+    source: String!
+
+    # Gas price declared by the transaction
+    gasPrice: BigInt!
+
+    # Gas price the reward is calculated from
+    effectiveGasPrice: BigInt!
+
+    # Base fee of the transaction block, if known
+    baseFee: BigInt
+
+    # Source of the effective gas price
+    gasPriceSource: String!
+
+    # Percentage of the fee paid to the projects
+    rewardsPercentage: Long!
+
+    # Total reward of all watching projects
+    reward: BigInt!
+
+    # Call frames of the transaction
+    frames: [RewardExplanationFrame!]!
+}
+
+# RewardExplanationFrame represents the attribution of a single call frame.
+type RewardExplanationFrame {
+    # Comma separated path of the frame in the call tree, empty for the top-level call
+    path: String!
+
+    # Call type of the frame
+    callType: String!
+
+    from: Address
+    to: Address
+
+    # Contract the frame gas is attributed to, null if the frame is not attributed
+    target: Address
+
+    # Function selector of the call
+    selector: String
+
+    # Gas used by the frame including its sub-calls, missing for stored frames absent in the live trace
+    gasUsed: Long
+
+    # Gas used by the frame without its successful sub-calls
+    selfGas: Long!
+
+    # Error of a failed frame
+    error: String
+
+    # Whether the target is watched by any active project
+    watched: Boolean!
+
+    # Projects watching the target
+    projects: [RewardExplanationProject!]!
+
+    # Reward of the frame paid to each of the watching projects
+    reward: BigInt
+}
+
+# RewardExplanationProject represents a project watching the target of a call frame.
+type RewardExplanationProject {
+    # Gas monetization contract the project is registered in
+    contract: Address!
+
+    # On-chain project id
+    projectId: Long!
+}
+
+# Transaction represents a rewarded call frame of a transaction.
+type Transaction {
+    # Hash of the transaction
+    hash: Bytes32!
+
+    # Address of the gas monetization contract the project is registered in, null if not recorded
+    contract: Address
+
+    # Id of the rewarded project
+    project: Long!
+
+    # Number of the block the transaction was included in
+    blockNumber: Long!
+
+    # Epoch the transaction was rewarded in
+    epoch: Long!
+
+    # Time of the block the transaction was included in
+    timestamp: Time!
+
+    # Address of the sender
+    from: Address!
+
+    # Address of the called contract
+    to: Address
+
+    # Path of the call frame in the transaction call tree, empty for the top level call
+    traceAddress: String!
+
+    # Call type of the call frame
+    callType: String!
+
+    # Gas attributed to the project
+    gasUsed: Long!
+
+    # Gas price the reward is calculated from
+    effectiveGasPrice: BigInt!
+
+    # Reward of the project
+    reward: BigInt!
+}
+
+# WithdrawalRequest represents a request of a project to withdraw its rewards.
+type WithdrawalRequest {
+    # Address of the gas monetization contract the project is registered in, null if not recorded
+    contract: Address
+
+    # Id of the project
+    project: Long!
+
+    # Epoch the withdrawal was requested in
+    requestEpoch: Long!
+
+    # Epoch the withdrawal completed in, null while pending
+    withdrawEpoch: Long
+
+    # Amount of the withdrawn rewards, null while pending
+    amount: BigInt
+
+    # Address the rewards were sent to, null while pending
+    recipient: Address
+}
+
 # Root schema definition
 schema {
     query: Query
@@ -88,10 +397,31 @@ type Query {
     # Version represents the API server version responding to your requests.
     totalTransactionCount: Long!
 
-    # Returns the address of the gas monetization contract.
+    # Returns the address of the primary gas monetization contract.
     gasMonetizationAddress: Address!
 
-    # Projects represents list of validated projects
-    projects: [Project!]!
+    # Returns the addresses of all watched gas monetization contracts.
+    gasMonetizationContracts: [Address!]!
+
+    # Projects represents list of validated projects, optionally of the given gas monetization contract,
+    # metadata category and metadata tag only
+    projects(contract: Address, category: String, tag: String): [Project!]!
+
+    # Rewarded transactions ordered from the newest, optionally of the given gas monetization contract,
+    # project and epoch only; at most the given number of them
+    transactions(contract: Address, project: Long, epoch: Long, limit: Int = 100): [Transaction!]!
+
+    # Withdrawal requests ordered from the newest, optionally of the given gas monetization contract
+    # and project only, or the pending ones only
+    withdrawalRequests(contract: Address, project: Long, pending: Boolean = false): [WithdrawalRequest!]!
+
+    # Raw events emitted by the gas monetization contracts ordered by block and log index, optionally following
+    # the event at the given block and log index only; at most the given number of them
+    contractEvents(contract: Address, txHash: Bytes32, name: String, outcome: String, fromBlock: Long, toBlock: Long,
+        afterBlock: Long, afterLogIndex: Long, limit: Int = 100): [ContractEvent!]!
+
+    # Breakdown of the gas attribution and rewards of the given transaction, traced live and carrying
+    # the stored rewards if the transaction was processed already
+    rewardExplanation(txHash: Bytes32!): RewardExplanation!
 }
 `
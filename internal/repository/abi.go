@@ -1,8 +1,16 @@
 package repository
 
-import "github.com/ethereum/go-ethereum/accounts/abi"
+import (
+	"ftm-gas-monetization/internal/repository/rpc/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
 
 // GasMonetizationAbi provides access to decoded ABI of Fantom Gas Monetization contract.
 func (repo *Repository) GasMonetizationAbi() *abi.ABI {
 	return repo.rpc.GasMonetizationAbi()
 }
+
+// GasMonetizationEvents provides access to typed event decoders of Fantom Gas Monetization contract.
+func (repo *Repository) GasMonetizationEvents() *contracts.GasMonetizationFilterer {
+	return repo.rpc.GasMonetizationEvents()
+}
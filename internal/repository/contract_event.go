@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/repository/db"
+)
+
+// ContractEventQuery returns a new contract event query builder.
+func (repo *Repository) ContractEventQuery() *db.ContractEventQueryBuilder {
+	return repo.db.ContractEventQuery(context.Background())
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type ContractEventQueryBuilder struct {
+	queryBuilder[types.ContractEvent, *ContractEventQueryBuilder]
+}
+
+// ContractEventQuery returns a new contract event query builder.
+func (db *Db) ContractEventQuery(ctx context.Context) *ContractEventQueryBuilder {
+	qb := &ContractEventQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.ContractEvent](ctx, db.con, "contract_event", qb)
+	return qb
+}
+
+// ContractEventColumns represents the columns contract events can be ordered and aggregated by.
+var ContractEventColumns = struct {
+	Id          Column[types.ContractEvent, int64]
+	BlockNumber Column[types.ContractEvent, uint64]
+	LogIndex    Column[types.ContractEvent, uint64]
+}{
+	Id:          Column[types.ContractEvent, int64]{name: "id"},
+	BlockNumber: Column[types.ContractEvent, uint64]{name: "block_number"},
+	LogIndex:    Column[types.ContractEvent, uint64]{name: "log_index"},
+}
+
+// WhereContract adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereContract(contract *types.Address) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "contract_address = :contract_address")
+	qb.parameters["contract_address"] = contract
+	return qb
+}
+
+// WhereTxHash adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereTxHash(hash *types.Hash) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "tx_hash = :tx_hash")
+	qb.parameters["tx_hash"] = hash
+	return qb
+}
+
+// WhereLogIndex adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereLogIndex(index uint64) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "log_index = :log_index")
+	qb.parameters["log_index"] = index
+	return qb
+}
+
+// WhereName adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereName(name string) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "event_name = :event_name")
+	qb.parameters["event_name"] = name
+	return qb
+}
+
+// WhereOutcome adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereOutcome(outcome string) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "outcome = :outcome")
+	qb.parameters["outcome"] = outcome
+	return qb
+}
+
+// WhereBlockFrom adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereBlockFrom(block uint64) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "block_number >= :block_from")
+	qb.parameters["block_from"] = block
+	return qb
+}
+
+// WhereBlockTo adds a where clause to the query builder.
+func (qb *ContractEventQueryBuilder) WhereBlockTo(block uint64) *ContractEventQueryBuilder {
+	qb.where = append(qb.where, "block_number <= :block_to")
+	qb.parameters["block_to"] = block
+	return qb
+}
+
+// StoreContractEvent stores the contract event into the database. An event already stored
+// for the same log is replaced, so a retried block overwrites the outcome of the previous attempt.
+func (db *Db) StoreContractEvent(ctx context.Context, event *types.ContractEvent) error {
+	query := `INSERT INTO contract_event (contract_address, block_number, tx_hash, log_index, topic, event_name, data,
+                            arguments, outcome, error)
+				VALUES (:contract_address, :block_number, :tx_hash, :log_index, :topic, :event_name, :data,
+				        :arguments, :outcome, :error)
+				ON CONFLICT (tx_hash, log_index) DO UPDATE SET contract_address = :contract_address,
+				    block_number = :block_number, topic = :topic, event_name = :event_name, data = :data,
+				    arguments = :arguments, outcome = :outcome, error = :error`
+	_, err := sqlx.NamedExecContext(ctx, db.con, query, event)
+	if err != nil {
+		db.log.Errorf("failed to store contract event %s/%d: %v", event.TxHash.Hex(), event.LogIndex, err)
+		return err
+	}
+	return nil
+}
@@ -5,13 +5,8 @@ import (
 	"fmt"
 	"ftm-gas-monetization/internal/config"
 	"ftm-gas-monetization/internal/logger"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
-	"path/filepath"
-	"runtime"
 )
 
 // Db defines the database repository.
@@ -22,7 +17,7 @@ type Db struct {
 	// con is the database connection that MUST be used to run queries. (can be db or tx)
 	con sqlx.ExtContext
 	// migrator is the database migrator.
-	migrator *migrate.Migrate
+	migrator *Migrator
 }
 
 // New creates a new database repository.
@@ -30,19 +25,19 @@ func New(config *config.DB, log *logger.AppLogger) *Db {
 	dbLogger := log.ModuleLogger("db")
 
 	// Build connection string.
-	cs := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		config.User, config.Password, config.Host, config.Port, config.Name)
+	cs := connectionString(config)
 
-	migrator, err := initializeMigrator(cs)
+	// Connect to the database.
+	con, err := sqlx.Connect("postgres", cs)
 	if err != nil {
-		dbLogger.Criticalf("failed to create database migrator: %s", err)
+		dbLogger.Criticalf("failed to connect to the database: %s", err)
 		return nil
 	}
 
-	// Connect to the database.
-	con, err := sqlx.Connect("postgres", cs)
+	migrator, err := newMigrator(cs, con, dbLogger)
 	if err != nil {
-		dbLogger.Criticalf("failed to connect to the database: %s", err)
+		dbLogger.Criticalf("failed to create database migrator: %s", err)
+		_ = con.Close()
 		return nil
 	}
 
@@ -54,8 +49,13 @@ func New(config *config.DB, log *logger.AppLogger) *Db {
 		migrator: migrator,
 	}
 
-	// Run the database migrations.
-	if err := db.migrateTables(); err != nil {
+	// Run the database migrations, or make sure they were run if the migration is done separately.
+	if config.AutoMigrate {
+		err = db.migrateTables()
+	} else {
+		err = migrator.Verify()
+	}
+	if err != nil {
 		dbLogger.Criticalf("failed to run the database migrations: %s", err)
 		_ = con.Close()
 		return nil
@@ -64,6 +64,11 @@ func New(config *config.DB, log *logger.AppLogger) *Db {
 	return &db
 }
 
+// Close closes the database connection.
+func (db *Db) Close() error {
+	return db.db.Close()
+}
+
 // DatabaseTransaction runs the given function in a database transaction.
 func (db *Db) DatabaseTransaction(ctx context.Context, fn func(context.Context, *Db) error) error {
 	// Start a database transaction.
@@ -85,7 +90,7 @@ func (db *Db) DatabaseTransaction(ctx context.Context, fn func(context.Context,
 	if err != nil {
 		// Rollback the transaction.
 		_ = tx.Rollback()
-		return fmt.Errorf("failed to run the database transaction: %s", err)
+		return fmt.Errorf("failed to run the database transaction: %w", err)
 	}
 
 	// Commit the transaction.
@@ -99,33 +104,19 @@ func (db *Db) DatabaseTransaction(ctx context.Context, fn func(context.Context,
 
 // migrateTables runs the database migrations.
 func (db *Db) migrateTables() error {
-	if err := db.migrator.Up(); err != nil {
-		if err == migrate.ErrNoChange {
-			return nil
-		}
-		return fmt.Errorf("failed to run the database migrations: %s", err)
-	}
-	return nil
+	return db.migrator.Up()
 }
 
 // dropTables drops all the database tables.
 func (db *Db) dropTables() error {
-	if err := db.migrator.Down(); err != nil {
+	if err := db.migrator.DownAll(); err != nil {
 		return fmt.Errorf("failed to drop database tables: %s", err)
 	}
 	return nil
 }
 
-// initializeMigrator returns the database migrator.
-func initializeMigrator(cs string) (*migrate.Migrate, error) {
-	_, path, _, ok := runtime.Caller(0)
-	if !ok {
-		return nil, fmt.Errorf("failed to get the current database migrations file path")
-	}
-	pathToMigrationFiles := filepath.Dir(path) + "/migrations"
-	m, err := migrate.New(fmt.Sprintf("file:%s", pathToMigrationFiles), cs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create the database migrations: %s", err)
-	}
-	return m, nil
+// connectionString builds the connection string of the configured database.
+func connectionString(config *config.DB) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		config.User, config.Password, config.Host, config.Port, config.Name)
 }
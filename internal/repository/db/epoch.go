@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/types"
+	"time"
+)
+
+type EpochQueryBuilder struct {
+	queryBuilder[types.Epoch, *EpochQueryBuilder]
+}
+
+// EpochQuery returns a new epoch query builder.
+func (db *Db) EpochQuery(ctx context.Context) *EpochQueryBuilder {
+	qb := &EpochQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.Epoch](ctx, db.con, "epoch", qb)
+	return qb
+}
+
+// EpochColumns represents the columns epochs can be ordered and aggregated by.
+var EpochColumns = struct {
+	Number Column[types.Epoch, uint64]
+}{
+	Number: Column[types.Epoch, uint64]{name: "number"},
+}
+
+// WhereNumber adds a where clause to the query builder.
+func (qb *EpochQueryBuilder) WhereNumber(number uint64) *EpochQueryBuilder {
+	qb.where = append(qb.where, "number = :number")
+	qb.parameters["number"] = number
+	return qb
+}
+
+// WhereAt adds a where clause to the query builder matching the epoch in progress at the given time.
+func (qb *EpochQueryBuilder) WhereAt(at time.Time) *EpochQueryBuilder {
+	qb.where = append(qb.where, "start_time <= :at AND (end_time IS NULL OR end_time > :at)")
+	qb.parameters["at"] = at
+	return qb
+}
+
+// WhereSealed adds a where clause to the query builder.
+func (qb *EpochQueryBuilder) WhereSealed(sealed bool) *EpochQueryBuilder {
+	qb.where = append(qb.where, "is_sealed = :is_sealed")
+	qb.parameters["is_sealed"] = sealed
+	return qb
+}
+
+// OpenEpoch records the start of the epoch at the given block. An epoch already recorded is kept.
+func (db *Db) OpenEpoch(ctx context.Context, number uint64, firstBlock uint64, start time.Time) error {
+	_, err := db.con.ExecContext(ctx, `INSERT INTO epoch (number, first_block, start_time) VALUES ($1, $2, $3)
+		ON CONFLICT (number) DO NOTHING`, number, firstBlock, start)
+	if err != nil {
+		db.log.Errorf("failed to open epoch %d: %v", number, err)
+		return err
+	}
+	return nil
+}
+
+// SealEpoch records the end of the epoch and marks it sealed. It returns false if the epoch was sealed already,
+// so the epoch can be closed repeatedly, e.g. when a block is retried.
+func (db *Db) SealEpoch(ctx context.Context, number uint64, lastBlock uint64, end time.Time) (bool, error) {
+	res, err := db.con.ExecContext(ctx, `INSERT INTO epoch (number, last_block, end_time, is_sealed) VALUES ($1, $2, $3, TRUE)
+		ON CONFLICT (number) DO UPDATE SET last_block = EXCLUDED.last_block, end_time = EXCLUDED.end_time, is_sealed = TRUE
+		WHERE NOT epoch.is_sealed`, number, lastBlock, end)
+	if err != nil {
+		db.log.Errorf("failed to seal epoch %d: %v", number, err)
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
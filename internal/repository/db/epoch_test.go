@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"time"
+)
+
+func (s *DbTestSuite) TestCloseEpoch() {
+	start := time.Unix(1678285698, 0)
+	end := start.Add(10 * time.Minute)
+	assert.Nil(s.T(), s.db.OpenEpoch(context.Background(), 1, 100, start))
+	// an open epoch is kept
+	assert.Nil(s.T(), s.db.OpenEpoch(context.Background(), 1, 101, end))
+
+	sealed, err := s.db.SealEpoch(context.Background(), 1, 199, end)
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), sealed)
+	assert.Nil(s.T(), s.db.OpenEpoch(context.Background(), 2, 200, end))
+
+	// the epoch is sealed only once
+	sealed, err = s.db.SealEpoch(context.Background(), 1, 250, end.Add(time.Minute))
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), sealed)
+
+	eq := s.db.EpochQuery(context.Background())
+	epoch, err := eq.WhereNumber(1).GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), epoch.Sealed)
+	assert.EqualValues(s.T(), 100, *epoch.FirstBlock)
+	assert.EqualValues(s.T(), 199, *epoch.LastBlock)
+
+	// the epoch ends when the following one starts
+	for at, number := range map[time.Time]uint64{start: 1, end.Add(-time.Second): 1, end: 2, end.Add(time.Hour): 2} {
+		eq = s.db.EpochQuery(context.Background())
+		epoch, err = eq.WhereAt(at).GetFirstOrFail()
+		assert.Nil(s.T(), err)
+		assert.Equal(s.T(), number, epoch.Number)
+	}
+
+	// an epoch never opened can be sealed, e.g. the epoch in progress when the epochs were introduced
+	sealed, err = s.db.SealEpoch(context.Background(), 0, 99, start)
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), sealed)
+}
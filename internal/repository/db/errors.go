@@ -0,0 +1,56 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"ftm-gas-monetization/internal/repository/storage"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrDuplicate represents a violation of a unique constraint.
+	ErrDuplicate = storage.ErrDuplicate
+
+	// ErrMissingReference represents a violation of a foreign key constraint.
+	ErrMissingReference = storage.ErrMissingReference
+)
+
+// pq error codes of the integrity constraint violations
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// ConstraintError represents a violation of an integrity constraint of the database.
+type ConstraintError struct {
+	Err        error
+	Table      string
+	Constraint string
+	Detail     string
+}
+
+// Error returns the description of the violated constraint.
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s; %s violates %s: %s", e.Err, e.Table, e.Constraint, e.Detail)
+}
+
+// Unwrap returns the kind of the violation, i.e. ErrDuplicate or ErrMissingReference.
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// mapError maps integrity constraint violations reported by the database to ConstraintError.
+// Other errors are returned unchanged.
+func mapError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return &ConstraintError{Err: ErrDuplicate, Table: pqErr.Table, Constraint: pqErr.Constraint, Detail: pqErr.Detail}
+	case pqForeignKeyViolation:
+		return &ConstraintError{Err: ErrMissingReference, Table: pqErr.Table, Constraint: pqErr.Constraint, Detail: pqErr.Detail}
+	}
+	return err
+}
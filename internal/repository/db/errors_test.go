@@ -0,0 +1,21 @@
+package db
+
+import (
+	"errors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestMapError tests constraint violations are mapped to the repository errors
+func TestMapError(t *testing.T) {
+	err := mapError(&pq.Error{Code: pqUniqueViolation, Table: "project", Constraint: "project_contract_project_id_key"})
+	assert.True(t, errors.Is(err, ErrDuplicate))
+	assert.Contains(t, err.Error(), "project_contract_project_id_key")
+
+	err = mapError(&pq.Error{Code: pqForeignKeyViolation, Table: "transaction", Constraint: "transaction_project_fk"})
+	assert.True(t, errors.Is(err, ErrMissingReference))
+
+	other := &pq.Error{Code: "42P01"}
+	assert.Equal(t, error(other), mapError(other))
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/types"
+)
+
+// AssignUnscopedRows assigns projects, transactions and withdrawal requests stored before contract scoping
+// was introduced to the given gas monetization contract.
+func (db *Db) AssignUnscopedRows(ctx context.Context, contract *types.Address) error {
+	for _, table := range []string{"project", "transaction", "withdrawal_request"} {
+		res, err := db.con.ExecContext(ctx, "UPDATE "+table+" SET contract_address = $1 WHERE contract_address IS NULL", contract)
+		if err != nil {
+			db.log.Errorf("failed to assign %s rows to contract %s: %v", table, contract.Hex(), err)
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			db.log.Noticef("%d %s rows assigned to contract %s", n, table, contract.Hex())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+// integrityCheck represents a query counting existing rows violating an integrity constraint.
+type integrityCheck struct {
+	// description of the violating rows
+	description string
+	// since is the schema version the query can run against
+	since uint
+	// enforced is the version of the migration introducing the constraint, the check is not needed since
+	enforced uint
+	query    string
+}
+
+// integrityChecks lists the checks of the data against the constraints introduced by the integrity migrations.
+var integrityChecks = []integrityCheck{
+	{
+		description: "transactions of unknown projects",
+		since:       2,
+		enforced:    17,
+		query:       "SELECT COUNT(*) FROM transaction t WHERE NOT EXISTS (SELECT 1 FROM project p WHERE p.id = t.project_id)",
+	},
+	{
+		description: "withdrawal requests of unknown projects",
+		since:       5,
+		enforced:    17,
+		query:       "SELECT COUNT(*) FROM withdrawal_request w WHERE NOT EXISTS (SELECT 1 FROM project p WHERE p.id = w.project_id)",
+	},
+	{
+		description: "projects registered more than once in a gas monetization contract",
+		since:       6,
+		enforced:    17,
+		query:       "SELECT COUNT(*) FROM (SELECT 1 FROM project GROUP BY contract_address, project_id HAVING COUNT(*) > 1) d",
+	},
+	{
+		description: "contracts added to more than one project of a gas monetization contract",
+		since:       9,
+		enforced:    17,
+		query: "SELECT COUNT(*) FROM (SELECT 1 FROM project_contract pc JOIN project p ON p.id = pc.project_id " +
+			"WHERE pc.removed_at_block IS NULL GROUP BY p.contract_address, pc.address HAVING COUNT(*) > 1) d",
+	},
+}
+
+// checkIntegrity checks the existing data against the integrity constraints, which would make the migrations
+// introducing the constraints fail half way. An error is returned if any data violate them, the data have to be
+// fixed manually before the migrations run. Checks of the constraints already in place are skipped.
+func (mg *Migrator) checkIntegrity(ctx context.Context) error {
+	version, _, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the database schema version: %s", err)
+	}
+
+	var violations []string
+	for _, check := range integrityChecks {
+		if version < check.since || version >= check.enforced {
+			continue
+		}
+		var count uint64
+		if err := sqlx.GetContext(ctx, mg.con, &count, check.query); err != nil {
+			return fmt.Errorf("failed to check %s: %s", check.description, err)
+		}
+		if count > 0 {
+			mg.log.Criticalf("found %d %s", count, check.description)
+			violations = append(violations, fmt.Sprintf("%d %s", count, check.description))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("existing data violate the integrity constraints, fix them before migrating: %s",
+			strings.Join(violations, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+ALTER TABLE withdrawal_request DROP COLUMN IF EXISTS contract_address;
+ALTER TABLE transaction DROP COLUMN IF EXISTS contract_address;
+ALTER TABLE project DROP COLUMN IF EXISTS contract_address;
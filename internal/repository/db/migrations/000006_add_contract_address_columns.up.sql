@@ -0,0 +1,3 @@
+ALTER TABLE project ADD COLUMN IF NOT EXISTS contract_address VARCHAR(40);
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS contract_address VARCHAR(40);
+ALTER TABLE withdrawal_request ADD COLUMN IF NOT EXISTS contract_address VARCHAR(40);
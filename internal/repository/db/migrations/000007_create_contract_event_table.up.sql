@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS contract_event(
+    id serial PRIMARY KEY,
+    contract_address VARCHAR(40) NOT NULL,
+    block_number BIGINT NOT NULL,
+    tx_hash VARCHAR(64) NOT NULL,
+    log_index INT NOT NULL,
+    topic VARCHAR(64),
+    event_name VARCHAR(64),
+    data TEXT NOT NULL,
+    arguments JSONB,
+    outcome VARCHAR(16) NOT NULL,
+    error TEXT,
+    UNIQUE (tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS contract_event_block_number_idx ON contract_event (block_number);
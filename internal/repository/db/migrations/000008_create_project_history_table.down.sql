@@ -0,0 +1,2 @@
+ALTER TABLE withdrawal_request DROP COLUMN IF EXISTS recipient_address;
+DROP TABLE IF EXISTS project_history;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS project_history(
+    id serial PRIMARY KEY,
+    project_id INT NOT NULL,
+    attribute VARCHAR(32) NOT NULL,
+    old_value TEXT,
+    new_value TEXT NOT NULL,
+    block_number BIGINT NOT NULL,
+    log_index BIGINT NOT NULL,
+    epoch_number BIGINT NOT NULL,
+    tx_hash VARCHAR(64) NOT NULL,
+    CONSTRAINT project_history_project_fk FOREIGN KEY (project_id) REFERENCES project (id),
+    -- a log record changes an attribute once, so the replayed change is not recorded again
+    CONSTRAINT project_history_change_key UNIQUE (project_id, block_number, log_index, attribute)
+);
+
+CREATE INDEX IF NOT EXISTS project_history_project_id_idx ON project_history (project_id, attribute, block_number);
+
+ALTER TABLE withdrawal_request ADD COLUMN IF NOT EXISTS recipient_address VARCHAR(40);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS project_contract_address_idx;
+ALTER TABLE project_contract DROP COLUMN IF EXISTS removed_at_block;
+ALTER TABLE project_contract DROP COLUMN IF EXISTS removed_at_epoch;
+ALTER TABLE project_contract DROP COLUMN IF EXISTS added_at_block;
+ALTER TABLE project_contract DROP COLUMN IF EXISTS added_at_epoch;
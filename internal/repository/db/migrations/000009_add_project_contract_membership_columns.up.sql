@@ -0,0 +1,6 @@
+ALTER TABLE project_contract ADD COLUMN IF NOT EXISTS added_at_epoch BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE project_contract ADD COLUMN IF NOT EXISTS added_at_block BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE project_contract ADD COLUMN IF NOT EXISTS removed_at_epoch BIGINT;
+ALTER TABLE project_contract ADD COLUMN IF NOT EXISTS removed_at_block BIGINT;
+
+CREATE INDEX IF NOT EXISTS project_contract_address_idx ON project_contract (address, added_at_block);
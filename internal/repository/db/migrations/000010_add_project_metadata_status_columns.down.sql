@@ -0,0 +1,5 @@
+ALTER TABLE project DROP COLUMN IF EXISTS metadata_fetched_at;
+ALTER TABLE project DROP COLUMN IF EXISTS metadata_next_fetch;
+ALTER TABLE project DROP COLUMN IF EXISTS metadata_attempts;
+ALTER TABLE project DROP COLUMN IF EXISTS metadata_error;
+ALTER TABLE project DROP COLUMN IF EXISTS metadata_status;
@@ -0,0 +1,8 @@
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata_status VARCHAR(16) NOT NULL DEFAULT 'pending';
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata_error TEXT;
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata_next_fetch TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata_fetched_at TIMESTAMP;
+
+-- metadata of projects with a name were fetched successfully before
+UPDATE project SET metadata_status = 'ok', metadata_fetched_at = NOW() WHERE name <> '';
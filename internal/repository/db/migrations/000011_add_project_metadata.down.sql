@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS project_metadata_tags_idx;
+DROP INDEX IF EXISTS project_metadata_category_idx;
+ALTER TABLE project DROP COLUMN IF EXISTS metadata_validation_errors;
+ALTER TABLE project DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,8 @@
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE project ADD COLUMN IF NOT EXISTS metadata_validation_errors TEXT[];
+
+CREATE INDEX IF NOT EXISTS project_metadata_category_idx ON project ((metadata->>'category'));
+CREATE INDEX IF NOT EXISTS project_metadata_tags_idx ON project USING GIN ((metadata->'tags'));
+
+-- legacy metadata documents are fetched again to fill the metadata column
+UPDATE project SET metadata_status = 'pending', metadata_next_fetch = NOW() WHERE metadata_status = 'ok';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS project_metadata_version(
+    id serial PRIMARY KEY,
+    project_id INT NOT NULL,
+    uri TEXT NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    content TEXT NOT NULL,
+    schema_version INT NOT NULL,
+    fetched_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS project_metadata_version_project_id_idx ON project_metadata_version (project_id, id);
@@ -0,0 +1,2 @@
+ALTER TABLE transaction DROP COLUMN IF EXISTS selector;
+ALTER TABLE transaction DROP COLUMN IF EXISTS call_type;
@@ -0,0 +1,2 @@
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS call_type VARCHAR(16) NOT NULL DEFAULT 'call';
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS selector VARCHAR(10);
@@ -0,0 +1 @@
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS trace_address VARCHAR(255) NOT NULL DEFAULT '';
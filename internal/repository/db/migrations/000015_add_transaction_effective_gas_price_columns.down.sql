@@ -0,0 +1,3 @@
+ALTER TABLE transaction DROP COLUMN IF EXISTS gas_price_source;
+ALTER TABLE transaction DROP COLUMN IF EXISTS base_fee;
+ALTER TABLE transaction DROP COLUMN IF EXISTS effective_gas_price;
@@ -0,0 +1,7 @@
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS effective_gas_price TEXT;
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS base_fee TEXT;
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS gas_price_source VARCHAR(16) NOT NULL DEFAULT 'transaction';
+
+-- rewards of the existing transactions were calculated from the transaction gas price
+UPDATE transaction SET effective_gas_price = gas_price WHERE effective_gas_price IS NULL;
+ALTER TABLE transaction ALTER COLUMN effective_gas_price SET NOT NULL;
@@ -0,0 +1,31 @@
+CREATE OR REPLACE FUNCTION pg_temp.numeric_to_hex(amount NUMERIC) RETURNS TEXT AS $$
+DECLARE
+    result TEXT := '';
+BEGIN
+    IF amount IS NULL THEN
+        RETURN NULL;
+    END IF;
+    IF amount = 0 THEN
+        RETURN '0';
+    END IF;
+    WHILE amount > 0 LOOP
+        result := substr('0123456789abcdef', mod(amount, 16)::INT + 1, 1) || result;
+        amount := div(amount, 16);
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE state SET value = pg_temp.numeric_to_hex(value::NUMERIC)
+    WHERE key IN ('total_amount_collected', 'total_amount_claimed') AND value IS NOT NULL;
+
+ALTER TABLE withdrawal_request ALTER COLUMN amount TYPE TEXT USING pg_temp.numeric_to_hex(amount);
+
+ALTER TABLE project ALTER COLUMN rewards_to_claim TYPE TEXT USING pg_temp.numeric_to_hex(rewards_to_claim);
+ALTER TABLE project ALTER COLUMN claimed_rewards TYPE TEXT USING pg_temp.numeric_to_hex(claimed_rewards);
+ALTER TABLE project ALTER COLUMN collected_rewards TYPE TEXT USING pg_temp.numeric_to_hex(collected_rewards);
+
+ALTER TABLE transaction ALTER COLUMN reward_to_claim TYPE TEXT USING pg_temp.numeric_to_hex(reward_to_claim);
+ALTER TABLE transaction ALTER COLUMN base_fee TYPE TEXT USING pg_temp.numeric_to_hex(base_fee);
+ALTER TABLE transaction ALTER COLUMN effective_gas_price TYPE TEXT USING pg_temp.numeric_to_hex(effective_gas_price);
+ALTER TABLE transaction ALTER COLUMN gas_price TYPE TEXT USING pg_temp.numeric_to_hex(gas_price);
@@ -0,0 +1,30 @@
+-- monetary values were stored as hex strings without the 0x prefix
+CREATE OR REPLACE FUNCTION pg_temp.hex_to_numeric(hex TEXT) RETURNS NUMERIC AS $$
+DECLARE
+    result NUMERIC := 0;
+BEGIN
+    IF hex IS NULL THEN
+        RETURN NULL;
+    END IF;
+    hex := lower(regexp_replace(hex, '^0x', ''));
+    FOR i IN 1..length(hex) LOOP
+        result := result * 16 + (position(substr(hex, i, 1) IN '0123456789abcdef') - 1);
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+ALTER TABLE transaction ALTER COLUMN gas_price TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(gas_price);
+ALTER TABLE transaction ALTER COLUMN effective_gas_price TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(effective_gas_price);
+ALTER TABLE transaction ALTER COLUMN base_fee TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(base_fee);
+ALTER TABLE transaction ALTER COLUMN reward_to_claim TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(reward_to_claim);
+
+ALTER TABLE project ALTER COLUMN collected_rewards TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(collected_rewards);
+ALTER TABLE project ALTER COLUMN claimed_rewards TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(claimed_rewards);
+ALTER TABLE project ALTER COLUMN rewards_to_claim TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(rewards_to_claim);
+
+ALTER TABLE withdrawal_request ALTER COLUMN amount TYPE NUMERIC(78,0) USING pg_temp.hex_to_numeric(amount);
+
+-- the state table is a generic key value store, the totals are kept as decimal strings there
+UPDATE state SET value = pg_temp.hex_to_numeric(value)::TEXT
+    WHERE key IN ('total_amount_collected', 'total_amount_claimed') AND value IS NOT NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE withdrawal_request DROP CONSTRAINT IF EXISTS withdrawal_request_project_fk;
+ALTER TABLE transaction DROP CONSTRAINT IF EXISTS transaction_project_fk;
+
+DROP INDEX IF EXISTS project_contract_address_key;
+ALTER TABLE project_contract DROP COLUMN IF EXISTS contract_address;
+DROP INDEX IF EXISTS project_contract_project_id_key;
+DROP INDEX IF EXISTS withdrawal_request_project_id_idx;
+DROP INDEX IF EXISTS transaction_project_epoch_idx;
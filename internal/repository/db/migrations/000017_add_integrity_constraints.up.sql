@@ -0,0 +1,20 @@
+-- the existing data are checked against the constraints before the migration runs, see integrityChecks
+CREATE INDEX IF NOT EXISTS transaction_project_epoch_idx ON transaction (project_id, epoch_number);
+CREATE INDEX IF NOT EXISTS withdrawal_request_project_id_idx ON withdrawal_request (project_id);
+
+-- project ids are assigned by each gas monetization contract separately
+CREATE UNIQUE INDEX IF NOT EXISTS project_contract_project_id_key ON project (contract_address, project_id);
+
+-- a contract can be an open member of a single project of each gas monetization contract,
+-- so the gas monetization contract of the project is kept along with the membership;
+-- a contract may be registered again after it was removed, so only the open membership is unique
+ALTER TABLE project_contract ADD COLUMN IF NOT EXISTS contract_address VARCHAR(40);
+UPDATE project_contract pc SET contract_address = p.contract_address FROM project p WHERE p.id = pc.project_id;
+CREATE UNIQUE INDEX IF NOT EXISTS project_contract_address_key ON project_contract (contract_address, address) WHERE removed_at_block IS NULL;
+
+-- the foreign keys are added without scanning the existing rows, which are validated afterwards;
+-- the rows passed the integrity check before the migration
+ALTER TABLE transaction ADD CONSTRAINT transaction_project_fk FOREIGN KEY (project_id) REFERENCES project (id) NOT VALID;
+ALTER TABLE withdrawal_request ADD CONSTRAINT withdrawal_request_project_fk FOREIGN KEY (project_id) REFERENCES project (id) NOT VALID;
+ALTER TABLE transaction VALIDATE CONSTRAINT transaction_project_fk;
+ALTER TABLE withdrawal_request VALIDATE CONSTRAINT withdrawal_request_project_fk;
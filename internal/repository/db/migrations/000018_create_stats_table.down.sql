@@ -0,0 +1,7 @@
+INSERT INTO state (key, value)
+SELECT 'total_amount_collected', total_amount_collected::TEXT FROM stats
+UNION ALL SELECT 'total_amount_claimed', total_amount_claimed::TEXT FROM stats
+UNION ALL SELECT 'total_transactions_count', total_transactions_count::TEXT FROM stats
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value;
+
+DROP TABLE IF EXISTS stats;
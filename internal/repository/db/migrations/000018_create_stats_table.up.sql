@@ -0,0 +1,17 @@
+-- single row of the global totals, updated atomically
+CREATE TABLE IF NOT EXISTS stats(
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    total_amount_collected NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_amount_claimed NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_transactions_count BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO stats (id, total_amount_collected, total_amount_claimed, total_transactions_count)
+VALUES (
+    TRUE,
+    COALESCE((SELECT value::NUMERIC FROM state WHERE key = 'total_amount_collected'), 0),
+    COALESCE((SELECT value::NUMERIC FROM state WHERE key = 'total_amount_claimed'), 0),
+    COALESCE((SELECT value::BIGINT FROM state WHERE key = 'total_transactions_count'), 0)
+) ON CONFLICT (id) DO NOTHING;
+
+DELETE FROM state WHERE key IN ('total_amount_collected', 'total_amount_claimed', 'total_transactions_count');
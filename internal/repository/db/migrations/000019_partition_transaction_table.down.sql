@@ -0,0 +1,36 @@
+ALTER TABLE transaction RENAME TO transaction_partitioned;
+ALTER INDEX IF EXISTS transaction_pkey RENAME TO transaction_partitioned_pkey;
+ALTER INDEX IF EXISTS transaction_project_epoch_idx RENAME TO transaction_partitioned_project_epoch_idx;
+
+CREATE TABLE transaction(
+    id INT NOT NULL DEFAULT nextval('transaction_id_seq') PRIMARY KEY,
+    project_id INT NOT NULL,
+    contract_address VARCHAR(40),
+    hash VARCHAR(64) NOT NULL,
+    block_hash VARCHAR(64),
+    block_number BIGINT NOT NULL,
+    epoch_number BIGINT NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    from_address VARCHAR(40),
+    to_address VARCHAR(40),
+    trace_address VARCHAR(255) NOT NULL DEFAULT '',
+    call_type VARCHAR(16) NOT NULL DEFAULT 'call',
+    selector VARCHAR(10),
+    gas_used BIGINT NOT NULL,
+    gas_price NUMERIC(78,0) NOT NULL,
+    effective_gas_price NUMERIC(78,0) NOT NULL,
+    base_fee NUMERIC(78,0),
+    gas_price_source VARCHAR(16) NOT NULL DEFAULT 'transaction',
+    reward_to_claim NUMERIC(78,0) NOT NULL,
+    CONSTRAINT transaction_project_fk FOREIGN KEY (project_id) REFERENCES project (id)
+);
+
+-- detached partitions are not restored
+INSERT INTO transaction SELECT id, project_id, contract_address, hash, block_hash, block_number, epoch_number, timestamp,
+    from_address, to_address, trace_address, call_type, selector, gas_used, gas_price, effective_gas_price, base_fee,
+    gas_price_source, reward_to_claim
+FROM transaction_partitioned;
+
+ALTER SEQUENCE transaction_id_seq OWNED BY transaction.id;
+DROP TABLE transaction_partitioned;
+CREATE INDEX IF NOT EXISTS transaction_project_epoch_idx ON transaction (project_id, epoch_number);
@@ -0,0 +1,51 @@
+-- transactions are partitioned by ranges of epochs, so the epoch queries scan a single partition
+-- and the expired epochs can be detached or dropped at once; the partitions are maintained by the indexer
+ALTER TABLE transaction RENAME TO transaction_legacy;
+ALTER TABLE transaction_legacy DROP CONSTRAINT IF EXISTS transaction_project_fk;
+ALTER INDEX IF EXISTS transaction_pkey RENAME TO transaction_legacy_pkey;
+ALTER INDEX IF EXISTS transaction_project_epoch_idx RENAME TO transaction_legacy_project_epoch_idx;
+
+-- the partition key has to be a part of the primary key
+CREATE TABLE transaction(
+    id INT NOT NULL DEFAULT nextval('transaction_id_seq'),
+    project_id INT NOT NULL,
+    contract_address VARCHAR(40),
+    hash VARCHAR(64) NOT NULL,
+    block_hash VARCHAR(64),
+    block_number BIGINT NOT NULL,
+    epoch_number BIGINT NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    from_address VARCHAR(40),
+    to_address VARCHAR(40),
+    trace_address VARCHAR(255) NOT NULL DEFAULT '',
+    call_type VARCHAR(16) NOT NULL DEFAULT 'call',
+    selector VARCHAR(10),
+    gas_used BIGINT NOT NULL,
+    gas_price NUMERIC(78,0) NOT NULL,
+    effective_gas_price NUMERIC(78,0) NOT NULL,
+    base_fee NUMERIC(78,0),
+    gas_price_source VARCHAR(16) NOT NULL DEFAULT 'transaction',
+    reward_to_claim NUMERIC(78,0) NOT NULL,
+    PRIMARY KEY (id, epoch_number),
+    CONSTRAINT transaction_project_fk FOREIGN KEY (project_id) REFERENCES project (id)
+) PARTITION BY RANGE (epoch_number);
+
+ALTER SEQUENCE transaction_id_seq OWNED BY transaction.id;
+CREATE INDEX IF NOT EXISTS transaction_project_epoch_idx ON transaction (project_id, epoch_number);
+
+-- the existing transactions become a single partition up to the current epoch, including it
+DO $$
+DECLARE
+    upper_epoch BIGINT;
+BEGIN
+    SELECT GREATEST(
+        COALESCE(MAX(epoch_number) + 1, 0),
+        COALESCE((SELECT value::BIGINT + 1 FROM state WHERE key = 'current_epoch'), 0)
+    ) INTO upper_epoch FROM transaction_legacy;
+
+    IF upper_epoch > 0 THEN
+        EXECUTE format('ALTER TABLE transaction ATTACH PARTITION transaction_legacy FOR VALUES FROM (0) TO (%s)', upper_epoch);
+    ELSE
+        DROP TABLE transaction_legacy;
+    END IF;
+END $$;
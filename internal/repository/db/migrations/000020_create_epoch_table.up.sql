@@ -0,0 +1,11 @@
+-- epochs observed by the block dispatcher; the start of the epoch in progress when the table was created is not known
+CREATE TABLE IF NOT EXISTS epoch(
+    number BIGINT PRIMARY KEY,
+    first_block BIGINT,
+    last_block BIGINT,
+    start_time TIMESTAMP,
+    end_time TIMESTAMP,
+    is_sealed BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS epoch_time_idx ON epoch (start_time, end_time);
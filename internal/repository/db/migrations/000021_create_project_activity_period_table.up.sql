@@ -0,0 +1,16 @@
+-- periods the projects were active in; a project is active from the first epoch of a period
+-- up to the last one, excluding it, or without an end while the period is open
+CREATE TABLE IF NOT EXISTS project_activity_period(
+    id serial PRIMARY KEY,
+    project_id INT NOT NULL,
+    from_epoch BIGINT NOT NULL,
+    to_epoch BIGINT,
+    CONSTRAINT project_activity_period_project_fk FOREIGN KEY (project_id) REFERENCES project (id)
+);
+
+CREATE INDEX IF NOT EXISTS project_activity_period_project_epoch_idx ON project_activity_period (project_id, from_epoch);
+CREATE UNIQUE INDEX IF NOT EXISTS project_activity_period_open_key ON project_activity_period (project_id) WHERE to_epoch IS NULL;
+
+-- the earlier periods of the existing projects were overwritten, only the last one is known
+INSERT INTO project_activity_period (project_id, from_epoch, to_epoch)
+SELECT id, active_from_epoch, active_to_epoch FROM project;
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/logger"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	"os"
+)
+
+// migrations holds the SQL migrations, so the binary can migrate the database without the source tree.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// MigrationStatus represents a single migration of the database schema.
+type MigrationStatus struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+	// Dirty marks the migration which failed in the middle and needs to be fixed and forced.
+	Dirty bool `json:"dirty,omitempty"`
+}
+
+// Migrator manages the migrations of the database schema.
+type Migrator struct {
+	log *logger.AppLogger
+	m   *migrate.Migrate
+	// con is the connection the existing data are checked over
+	con sqlx.ExtContext
+	// closer closes the connection, if it is owned by the migrator
+	closer func() error
+}
+
+// NewMigrator creates a new migrator of the configured database.
+func NewMigrator(cfg *config.DB, log *logger.AppLogger) (*Migrator, error) {
+	dbLogger := log.ModuleLogger("db")
+	cs := connectionString(cfg)
+	con, err := sqlx.Connect("postgres", cs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %s", err)
+	}
+	m, err := newMigrator(cs, con, dbLogger)
+	if err != nil {
+		_ = con.Close()
+		return nil, err
+	}
+	m.closer = con.Close
+	return m, nil
+}
+
+// newMigrator creates a new migrator of the database at the given connection string.
+func newMigrator(cs string, con sqlx.ExtContext, log *logger.AppLogger) (*Migrator, error) {
+	src, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the embedded database migrations: %s", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, cs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the database migrations: %s", err)
+	}
+	return &Migrator{log: log, m: m, con: con}, nil
+}
+
+// Up applies all pending migrations, unless existing data violate the integrity constraints to be introduced.
+func (mg *Migrator) Up() error {
+	if err := mg.checkIntegrity(context.Background()); err != nil {
+		return err
+	}
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run the database migrations: %s", err)
+	}
+	return nil
+}
+
+// Down reverts the given number of applied migrations.
+func (mg *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("number of migrations to revert must be positive, %d given", steps)
+	}
+	if err := mg.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to revert the database migrations: %s", err)
+	}
+	return nil
+}
+
+// DownAll reverts all the applied migrations, which drops all the tables including their data.
+func (mg *Migrator) DownAll() error {
+	if err := mg.m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to revert the database migrations: %s", err)
+	}
+	return nil
+}
+
+// Version returns the version of the database schema, zero if no migration is applied.
+func (mg *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Force sets the version of the database schema without running any migration,
+// e.g. to clear the dirty state after a failed migration was fixed manually.
+func (mg *Migrator) Force(version int) error {
+	if err := mg.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force the database schema version %d: %s", version, err)
+	}
+	return nil
+}
+
+// Status returns all the known migrations along with their state.
+func (mg *Migrator) Status() ([]MigrationStatus, error) {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+	src, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var list []MigrationStatus
+	version, err := src.First()
+	for err == nil {
+		r, name, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return nil, readErr
+		}
+		_ = r.Close()
+		list = append(list, MigrationStatus{
+			Version: version,
+			Name:    name,
+			Applied: version <= current,
+			Dirty:   dirty && version == current,
+		})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Latest returns the version of the latest known migration.
+func (mg *Migrator) Latest() (uint, error) {
+	list, err := mg.Status()
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+	return list[len(list)-1].Version, nil
+}
+
+// Verify checks the database schema is migrated to the latest known version.
+func (mg *Migrator) Verify() error {
+	version, dirty, err := mg.Version()
+	if err != nil {
+		return fmt.Errorf("failed to get the database schema version: %s", err)
+	}
+	if dirty {
+		return fmt.Errorf("database schema version %d is dirty, fix the failed migration and force the version", version)
+	}
+	latest, err := mg.Latest()
+	if err != nil {
+		return fmt.Errorf("failed to get the database migrations: %s", err)
+	}
+	if version != latest {
+		return fmt.Errorf("database schema version %d does not match %d, run the migrations", version, latest)
+	}
+	return nil
+}
+
+// Close releases the migrator resources.
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	if mg.closer != nil {
+		if err := mg.closer(); err != nil {
+			return err
+		}
+	}
+	if srcErr != nil {
+		return srcErr
+	}
+	return dbErr
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// TestEmbeddedMigrations tests all migrations are embedded with consecutive versions and a down migration
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.Glob(migrations, "migrations/*.up.sql")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+	for i, file := range files {
+		assert.True(t, strings.HasPrefix(file, fmt.Sprintf("migrations/%06d_", i+1)), file)
+		_, err := fs.Stat(migrations, strings.TrimSuffix(file, ".up.sql")+".down.sql")
+		assert.Nil(t, err, file)
+	}
+}
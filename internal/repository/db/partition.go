@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// partitionLockKey is the key of the advisory lock serializing changes of the transaction partitions.
+const partitionLockKey = 0x67617370617274
+
+// partitionBoundRe matches the range bound of a partition as described by Postgres, e.g. FOR VALUES FROM ('0') TO ('1000').
+var partitionBoundRe = regexp.MustCompile(`FROM \('?(\d+|MINVALUE)'?\) TO \('?(\d+|MAXVALUE)'?\)`)
+
+// TransactionPartition represents a partition of the transaction table holding a range of epochs.
+type TransactionPartition struct {
+	Name string
+	// FromEpoch is the first epoch of the partition
+	FromEpoch uint64
+	// ToEpoch is the first epoch after the partition
+	ToEpoch uint64
+}
+
+// TransactionPartitions returns the partitions of the transaction table ordered by their epochs.
+func (db *Db) TransactionPartitions(ctx context.Context) ([]TransactionPartition, error) {
+	var rows []struct {
+		Name  string `db:"name"`
+		Bound string `db:"bound"`
+	}
+	err := sqlx.SelectContext(ctx, db.con, &rows, `SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+		FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid WHERE i.inhparent = 'transaction'::regclass`)
+	if err != nil {
+		db.log.Errorf("failed to list transaction partitions: %s", err)
+		return nil, err
+	}
+
+	partitions := make([]TransactionPartition, 0, len(rows))
+	for _, row := range rows {
+		match := partitionBoundRe.FindStringSubmatch(row.Bound)
+		if match == nil {
+			// the default partition has no range
+			continue
+		}
+		partition := TransactionPartition{Name: row.Name, ToEpoch: math.MaxUint64}
+		if match[1] != "MINVALUE" {
+			if partition.FromEpoch, err = strconv.ParseUint(match[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid bound of partition %s; %s", row.Name, err)
+			}
+		}
+		if match[2] != "MAXVALUE" {
+			if partition.ToEpoch, err = strconv.ParseUint(match[2], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid bound of partition %s; %s", row.Name, err)
+			}
+		}
+		partitions = append(partitions, partition)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].FromEpoch < partitions[j].FromEpoch
+	})
+	return partitions, nil
+}
+
+// EnsureTransactionPartitions creates partitions of the given number of epochs, so all the epochs
+// from the given epoch up to the given one, excluding it, can be stored. It returns the created partitions.
+func (db *Db) EnsureTransactionPartitions(ctx context.Context, fromEpoch uint64, toEpoch uint64, size uint64) ([]TransactionPartition, error) {
+	if size == 0 {
+		return nil, fmt.Errorf("partition size must be positive")
+	}
+
+	var missing []TransactionPartition
+	err := db.lockPartitions(ctx, func(ctx context.Context, db *Db) error {
+		existing, err := db.TransactionPartitions(ctx)
+		if err != nil {
+			return err
+		}
+
+		missing = missingPartitions(existing, fromEpoch, toEpoch, size)
+		for _, p := range missing {
+			query := fmt.Sprintf("CREATE TABLE %s PARTITION OF transaction FOR VALUES FROM (%d) TO (%d)",
+				pq.QuoteIdentifier(p.Name), p.FromEpoch, p.ToEpoch)
+			if _, err := db.con.ExecContext(ctx, query); err != nil {
+				db.log.Errorf("failed to create transaction partition %s: %s", p.Name, err)
+				return err
+			}
+			db.log.Noticef("transaction partition %s of epochs %d-%d created", p.Name, p.FromEpoch, p.ToEpoch-1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// ExpireTransactionPartitions detaches, or drops, the partitions of the transaction table holding only
+// epochs before the given one. It returns the expired partitions.
+func (db *Db) ExpireTransactionPartitions(ctx context.Context, beforeEpoch uint64, detach bool) ([]TransactionPartition, error) {
+	expired := make([]TransactionPartition, 0)
+	err := db.lockPartitions(ctx, func(ctx context.Context, db *Db) error {
+		existing, err := db.TransactionPartitions(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range existing {
+			if p.ToEpoch > beforeEpoch {
+				break
+			}
+			query := "DROP TABLE " + pq.QuoteIdentifier(p.Name)
+			if detach {
+				query = "ALTER TABLE transaction DETACH PARTITION " + pq.QuoteIdentifier(p.Name)
+			}
+			if _, err := db.con.ExecContext(ctx, query); err != nil {
+				db.log.Errorf("failed to expire transaction partition %s: %s", p.Name, err)
+				return err
+			}
+			db.log.Noticef("transaction partition %s of epochs %d-%d expired", p.Name, p.FromEpoch, p.ToEpoch-1)
+			expired = append(expired, p)
+		}
+		return nil
+	})
+	if err != nil {
+		// the partitions are expired in a database transaction, none of them is expired on failure
+		return nil, err
+	}
+	return expired, nil
+}
+
+// lockPartitions runs the function holding the lock of the transaction partitions, so the block dispatcher
+// and the partition maintainer never create the same partition at once. The lock is held until the database
+// transaction of the repository ends, a new database transaction is used if there is none.
+func (db *Db) lockPartitions(ctx context.Context, fn func(context.Context, *Db) error) error {
+	if _, ok := db.con.(*sqlx.Tx); !ok {
+		return db.DatabaseTransaction(ctx, func(ctx context.Context, db *Db) error {
+			return db.lockPartitions(ctx, fn)
+		})
+	}
+	if _, err := db.con.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", partitionLockKey); err != nil {
+		db.log.Errorf("failed to lock transaction partitions: %s", err)
+		return err
+	}
+	return fn(ctx, db)
+}
+
+// missingPartitions returns the partitions aligned to the given size needed to store the given epochs,
+// the ranges already covered by the existing partitions, ordered by their epochs, are left out.
+func missingPartitions(existing []TransactionPartition, fromEpoch uint64, toEpoch uint64, size uint64) []TransactionPartition {
+	missing := make([]TransactionPartition, 0)
+	for start := fromEpoch - fromEpoch%size; start < toEpoch; start += size {
+		from, end := start, start+size
+		for _, p := range existing {
+			if p.ToEpoch <= from || p.FromEpoch >= end {
+				continue
+			}
+			// the gap before the existing partition is missing
+			if p.FromEpoch > from {
+				missing = append(missing, newTransactionPartition(from, p.FromEpoch))
+			}
+			from = p.ToEpoch
+			if from >= end {
+				break
+			}
+		}
+		if from < end {
+			missing = append(missing, newTransactionPartition(from, end))
+		}
+	}
+	return missing
+}
+
+// newTransactionPartition returns a partition holding the epochs from the given one up to the given one, excluding it.
+func newTransactionPartition(from uint64, to uint64) TransactionPartition {
+	return TransactionPartition{
+		Name:      fmt.Sprintf("transaction_epoch_%d_%d", from, to-1),
+		FromEpoch: from,
+		ToEpoch:   to,
+	}
+}
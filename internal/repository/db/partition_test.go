@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMissingPartitions(t *testing.T) {
+	// no partitions yet, the ranges are aligned to the size
+	missing := missingPartitions(nil, 250, 350, 100)
+	assert.Equal(t, []TransactionPartition{
+		{Name: "transaction_epoch_200_299", FromEpoch: 200, ToEpoch: 300},
+		{Name: "transaction_epoch_300_399", FromEpoch: 300, ToEpoch: 400},
+	}, missing)
+
+	// the legacy partition ends in the middle of a range, another partition is in the middle of the next one
+	existing := []TransactionPartition{
+		{Name: "transaction_legacy", FromEpoch: 0, ToEpoch: 250},
+		{Name: "transaction_epoch_320_329", FromEpoch: 320, ToEpoch: 330},
+	}
+	missing = missingPartitions(existing, 200, 400, 100)
+	assert.Equal(t, []TransactionPartition{
+		{Name: "transaction_epoch_250_299", FromEpoch: 250, ToEpoch: 300},
+		{Name: "transaction_epoch_300_319", FromEpoch: 300, ToEpoch: 320},
+		{Name: "transaction_epoch_330_399", FromEpoch: 330, ToEpoch: 400},
+	}, missing)
+
+	// everything is covered already
+	assert.Empty(t, missingPartitions(existing, 0, 200, 100))
+}
+
+func (s *DbTestSuite) TestTransactionPartitions() {
+	// the epochs before testPartitionEpochs are covered by the test database already
+	created, err := s.db.EnsureTransactionPartitions(context.Background(), 995, 1025, 10)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), created, 3)
+
+	// existing partitions are kept
+	created, err = s.db.EnsureTransactionPartitions(context.Background(), 0, 1030, 10)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), created, 0)
+
+	project := s.storeTestProject(1)
+	for _, epoch := range []uint64{1003, 1015, 1025} {
+		trx := testTransaction(project.Id)
+		trx.Epoch = hexutil.Uint64(epoch)
+		assert.Nil(s.T(), s.db.StoreTransaction(context.Background(), trx))
+	}
+
+	// the partitions are transparent to the queries
+	tq := s.db.TransactionQuery(context.Background())
+	count, err := tq.WhereEpoch(1015).Count()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 1, count)
+
+	// partitions of the epochs before 1020 are detached
+	expired, err := s.db.ExpireTransactionPartitions(context.Background(), 1020, true)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), expired, 3)
+	tq = s.db.TransactionQuery(context.Background())
+	count, err = tq.Count()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 1, count)
+
+	partitions, err := s.db.TransactionPartitions(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), partitions, 1)
+	assert.EqualValues(s.T(), 1020, partitions[0].FromEpoch)
+
+	// detached partitions are kept as standalone tables
+	for _, p := range expired {
+		_, err = s.db.con.ExecContext(context.Background(), "DROP TABLE "+p.Name)
+		assert.Nil(s.T(), err)
+	}
+}
@@ -5,17 +5,41 @@ import (
 	"fmt"
 	"ftm-gas-monetization/internal/types"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
 )
 
 type ProjectQueryBuilder struct {
-	queryBuilder[types.Project]
+	queryBuilder[types.Project, *ProjectQueryBuilder]
 }
 
 // ProjectQuery returns a new project query builder.
-func (db *Db) ProjectQuery(ctx context.Context) ProjectQueryBuilder {
-	return ProjectQueryBuilder{
-		queryBuilder: newQueryBuilder[types.Project](ctx, db.con, "project"),
-	}
+func (db *Db) ProjectQuery(ctx context.Context) *ProjectQueryBuilder {
+	qb := &ProjectQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.Project](ctx, db.con, "project", qb)
+	return qb
+}
+
+// ProjectColumns represents the columns projects can be ordered and aggregated by.
+var ProjectColumns = struct {
+	Id                Column[types.Project, int64]
+	ProjectId         Column[types.Project, uint64]
+	TransactionsCount Column[types.Project, uint64]
+	CollectedRewards  Column[types.Project, types.Big]
+	MetadataNextFetch Column[types.Project, time.Time]
+}{
+	Id:                Column[types.Project, int64]{name: "id"},
+	ProjectId:         Column[types.Project, uint64]{name: "project_id"},
+	TransactionsCount: Column[types.Project, uint64]{name: "transactions_count"},
+	CollectedRewards:  Column[types.Project, types.Big]{name: "collected_rewards"},
+	MetadataNextFetch: Column[types.Project, time.Time]{name: "metadata_next_fetch"},
+}
+
+// WhereIdIn adds a where clause to the query builder.
+func (qb *ProjectQueryBuilder) WhereIdIn(ids []int64) *ProjectQueryBuilder {
+	qb.where = append(qb.where, "id = ANY(:ids)")
+	qb.parameters["ids"] = pq.Array(ids)
+	return qb
 }
 
 // WhereProjectId adds a where clause to the query builder.
@@ -25,6 +49,13 @@ func (qb *ProjectQueryBuilder) WhereProjectId(projectId uint64) *ProjectQueryBui
 	return qb
 }
 
+// WhereContract adds a where clause to the query builder.
+func (qb *ProjectQueryBuilder) WhereContract(contract *types.Address) *ProjectQueryBuilder {
+	qb.where = append(qb.where, "contract_address = :contract_address")
+	qb.parameters["contract_address"] = contract
+	return qb
+}
+
 // WhereOwner adds a where clause to the query builder.
 func (qb *ProjectQueryBuilder) WhereOwner(owner *types.Address) *ProjectQueryBuilder {
 	qb.where = append(qb.where, "owner_address = :owner_address")
@@ -32,26 +63,50 @@ func (qb *ProjectQueryBuilder) WhereOwner(owner *types.Address) *ProjectQueryBui
 	return qb
 }
 
-// WhereActiveInEpoch adds a where clause to the query builder.
+// WhereActiveInEpoch adds a where clause to the query builder. The project is active in the epoch
+// if any of its activity periods covers it, so a project suspended and enabled again is inactive in between.
 func (qb *ProjectQueryBuilder) WhereActiveInEpoch(epoch uint64) *ProjectQueryBuilder {
-	qb.where = append(qb.where, "active_from_epoch <= :epoch AND (active_to_epoch IS NULL OR active_to_epoch > :epoch)")
+	qb.where = append(qb.where, `EXISTS (SELECT 1 FROM project_activity_period ap WHERE ap.project_id = project.id
+		AND ap.from_epoch <= :epoch AND (ap.to_epoch IS NULL OR ap.to_epoch > :epoch))`)
 	qb.parameters["epoch"] = epoch
 	return qb
 }
 
+// WhereMetadataDue adds a where clause to the query builder.
+func (qb *ProjectQueryBuilder) WhereMetadataDue(now time.Time) *ProjectQueryBuilder {
+	qb.where = append(qb.where, "metadata_status <> :metadata_ok AND metadata_next_fetch <= :metadata_now")
+	qb.parameters["metadata_ok"] = types.ProjectMetadataOk
+	qb.parameters["metadata_now"] = now
+	return qb
+}
+
+// WhereCategory adds a where clause to the query builder.
+func (qb *ProjectQueryBuilder) WhereCategory(category string) *ProjectQueryBuilder {
+	qb.where = append(qb.where, "metadata->>'category' = :category")
+	qb.parameters["category"] = category
+	return qb
+}
+
+// WhereTag adds a where clause to the query builder.
+func (qb *ProjectQueryBuilder) WhereTag(tag string) *ProjectQueryBuilder {
+	qb.where = append(qb.where, "metadata->'tags' @> jsonb_build_array(CAST(:tag AS TEXT))")
+	qb.parameters["tag"] = tag
+	return qb
+}
+
 // StoreProject stores the project in the database.
 func (db *Db) StoreProject(ctx context.Context, project *types.Project) error {
-	query := `INSERT INTO project (owner_address, project_id, receiver_address, name, url, image_url, last_withdrawal_epoch, 
+	query := `INSERT INTO project (contract_address, owner_address, project_id, receiver_address, name, url, image_url, last_withdrawal_epoch, 
                      collected_rewards, claimed_rewards, rewards_to_claim, transactions_count, active_from_epoch, 
                      active_to_epoch) 
-		VALUES (:owner_address, :project_id, :receiver_address, :name, :url, :image_url, :last_withdrawal_epoch, :collected_rewards, 
+		VALUES (:contract_address, :owner_address, :project_id, :receiver_address, :name, :url, :image_url, :last_withdrawal_epoch, :collected_rewards, 
 		        :claimed_rewards, :rewards_to_claim, :transactions_count, :active_from_epoch, :active_to_epoch)
 		RETURNING id`
 
 	rows, err := sqlx.NamedQueryContext(ctx, db.con, query, project)
 	if err != nil {
 		db.log.Errorf("failed to store project %d: %v", project.ProjectId, err)
-		return err
+		return mapError(err)
 	}
 	defer func(rows *sqlx.Rows) {
 		err := rows.Close()
@@ -72,16 +127,22 @@ func (db *Db) StoreProject(ctx context.Context, project *types.Project) error {
 	}
 	project.Id = id
 
-	return nil
+	// the rows have to be closed before the connection is used again
+	if err := rows.Close(); err != nil {
+		db.log.Errorf("failed to close rows: %v", err)
+		return err
+	}
+	return db.storeProjectActivityPeriod(ctx, project)
 }
 
-// UpdateProject updates the project in the database.
+// UpdateProject updates the project in the database. A change of the active window is recorded
+// into the activity periods of the project, see syncProjectActivityPeriod.
 func (db *Db) UpdateProject(ctx context.Context, project *types.Project) error {
 	if project.Id == 0 {
 		return fmt.Errorf("failed to update project %d: project id is 0", project.ProjectId)
 	}
-	query := `UPDATE project SET owner_address = :owner_address, receiver_address = :receiver_address,
-                   name = :name, url = :url, image_url = :image_url, last_withdrawal_epoch = :last_withdrawal_epoch,
+	query := `UPDATE project SET contract_address = :contract_address, owner_address = :owner_address, receiver_address = :receiver_address,
+                   url = :url, last_withdrawal_epoch = :last_withdrawal_epoch,
                    collected_rewards = :collected_rewards, claimed_rewards = :claimed_rewards, rewards_to_claim = :rewards_to_claim, 
                    transactions_count = :transactions_count, active_from_epoch = :active_from_epoch, active_to_epoch = :active_to_epoch 
                WHERE id = :id`
@@ -89,8 +150,61 @@ func (db *Db) UpdateProject(ctx context.Context, project *types.Project) error {
 	_, err := sqlx.NamedExecContext(ctx, db.con, query, project)
 	if err != nil {
 		db.log.Errorf("failed to update project %d: %v", project.ProjectId, err)
-		return err
+		return mapError(err)
 	}
 
+	return db.syncProjectActivityPeriod(ctx, project)
+}
+
+// ScheduleProjectMetadata schedules the metadata of the project to be fetched as soon as possible.
+func (db *Db) ScheduleProjectMetadata(ctx context.Context, project *types.Project) error {
+	project.MetadataStatus = types.ProjectMetadataPending
+	project.MetadataError = nil
+	project.MetadataAttempts = 0
+	project.MetadataNextFetch = time.Now()
+	query := `UPDATE project SET metadata_status = :metadata_status, metadata_error = :metadata_error,
+                   metadata_attempts = :metadata_attempts, metadata_next_fetch = :metadata_next_fetch WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, db.con, query, project)
+	if err != nil {
+		db.log.Errorf("failed to schedule metadata of project %d: %v", project.ProjectId, err)
+		return err
+	}
 	return nil
 }
+
+// ScheduleProjectMetadataRefresh schedules the metadata of active projects fetched successfully
+// before the given time to be fetched again. Returns the number of scheduled projects.
+func (db *Db) ScheduleProjectMetadataRefresh(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+	query := `UPDATE project SET metadata_status = :pending, metadata_attempts = 0, metadata_next_fetch = NOW()
+				WHERE active_to_epoch IS NULL AND metadata_status = :ok AND metadata_fetched_at < :fetched_before`
+	res, err := sqlx.NamedExecContext(ctx, db.con, query, map[string]interface{}{
+		"pending":        types.ProjectMetadataPending,
+		"ok":             types.ProjectMetadataOk,
+		"fetched_before": fetchedBefore,
+	})
+	if err != nil {
+		db.log.Errorf("failed to schedule metadata refresh: %v", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// UpdateProjectMetadata updates the metadata and the metadata fetch state of the project. The update
+// is skipped if the metadata URI of the project changed meanwhile; false is returned in that case.
+func (db *Db) UpdateProjectMetadata(ctx context.Context, project *types.Project) (bool, error) {
+	query := `UPDATE project SET name = :name, image_url = :image_url, metadata = :metadata,
+                   metadata_validation_errors = :metadata_validation_errors, metadata_status = :metadata_status,
+                   metadata_error = :metadata_error, metadata_attempts = :metadata_attempts,
+                   metadata_next_fetch = :metadata_next_fetch, metadata_fetched_at = :metadata_fetched_at
+               WHERE id = :id AND url = :url`
+	res, err := sqlx.NamedExecContext(ctx, db.con, query, project)
+	if err != nil {
+		db.log.Errorf("failed to update metadata of project %d: %v", project.ProjectId, err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type ProjectActivityPeriodQueryBuilder struct {
+	queryBuilder[types.ProjectActivityPeriod, *ProjectActivityPeriodQueryBuilder]
+}
+
+// ProjectActivityPeriodQuery returns a new project activity period query builder.
+func (db *Db) ProjectActivityPeriodQuery(ctx context.Context) *ProjectActivityPeriodQueryBuilder {
+	qb := &ProjectActivityPeriodQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.ProjectActivityPeriod](ctx, db.con, "project_activity_period", qb)
+	return qb
+}
+
+// ProjectActivityPeriodColumns represents the columns project activity periods can be ordered and aggregated by.
+var ProjectActivityPeriodColumns = struct {
+	Id        Column[types.ProjectActivityPeriod, int64]
+	FromEpoch Column[types.ProjectActivityPeriod, uint64]
+}{
+	Id:        Column[types.ProjectActivityPeriod, int64]{name: "id"},
+	FromEpoch: Column[types.ProjectActivityPeriod, uint64]{name: "from_epoch"},
+}
+
+// WhereProjectId adds a where clause to the query builder.
+func (qb *ProjectActivityPeriodQueryBuilder) WhereProjectId(projectId int64) *ProjectActivityPeriodQueryBuilder {
+	qb.where = append(qb.where, "project_id = :project_id")
+	qb.parameters["project_id"] = projectId
+	return qb
+}
+
+// syncProjectActivityPeriod records the active window of the project into its activity periods.
+// The latest period is updated if the project was suspended, a new period is opened if the project
+// was enabled again, so the earlier periods are kept.
+func (db *Db) syncProjectActivityPeriod(ctx context.Context, project *types.Project) error {
+	qb := db.ProjectActivityPeriodQuery(ctx)
+	latest, err := qb.WhereProjectId(project.Id).OrderBy(ProjectActivityPeriodColumns.FromEpoch, Desc).OrderBy(ProjectActivityPeriodColumns.Id, Desc).GetFirst()
+	if err != nil {
+		db.log.Errorf("failed to get activity period of project %d: %v", project.ProjectId, err)
+		return err
+	}
+
+	if latest != nil && latest.FromEpoch == project.ActiveFromEpoch {
+		if sameEpoch(latest.ToEpoch, project.ActiveToEpoch) {
+			return nil
+		}
+		_, err = db.con.ExecContext(ctx, db.con.Rebind("UPDATE project_activity_period SET to_epoch = ? WHERE id = ?"),
+			project.ActiveToEpoch, latest.Id)
+		if err != nil {
+			db.log.Errorf("failed to update activity period of project %d: %v", project.ProjectId, err)
+			return mapError(err)
+		}
+		return nil
+	}
+
+	// the project was enabled again without being suspended, the open period ends where the new one starts
+	if latest != nil && latest.ToEpoch == nil {
+		_, err = db.con.ExecContext(ctx, db.con.Rebind("UPDATE project_activity_period SET to_epoch = ? WHERE id = ?"),
+			project.ActiveFromEpoch, latest.Id)
+		if err != nil {
+			db.log.Errorf("failed to close activity period of project %d: %v", project.ProjectId, err)
+			return mapError(err)
+		}
+	}
+	return db.storeProjectActivityPeriod(ctx, project)
+}
+
+// storeProjectActivityPeriod stores the active window of the project as a new activity period.
+func (db *Db) storeProjectActivityPeriod(ctx context.Context, project *types.Project) error {
+	period := types.ProjectActivityPeriod{
+		ProjectId: project.Id,
+		FromEpoch: project.ActiveFromEpoch,
+		ToEpoch:   project.ActiveToEpoch,
+	}
+	query := `INSERT INTO project_activity_period (project_id, from_epoch, to_epoch) VALUES (:project_id, :from_epoch, :to_epoch)`
+	if _, err := sqlx.NamedExecContext(ctx, db.con, query, &period); err != nil {
+		db.log.Errorf("failed to store activity period of project %d: %v", project.ProjectId, err)
+		return mapError(err)
+	}
+	return nil
+}
+
+// sameEpoch checks the optional epochs are equal.
+func sameEpoch(a *uint64, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -8,14 +8,23 @@ import (
 )
 
 type ProjectContractQueryBuilder struct {
-	queryBuilder[types.ProjectContract]
+	queryBuilder[types.ProjectContract, *ProjectContractQueryBuilder]
 }
 
 // ProjectContractQuery returns a new project contract query builder.
-func (db *Db) ProjectContractQuery(ctx context.Context) ProjectContractQueryBuilder {
-	return ProjectContractQueryBuilder{
-		queryBuilder: newQueryBuilder[types.ProjectContract](ctx, db.con, "project_contract"),
-	}
+func (db *Db) ProjectContractQuery(ctx context.Context) *ProjectContractQueryBuilder {
+	qb := &ProjectContractQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.ProjectContract](ctx, db.con, "project_contract", qb)
+	return qb
+}
+
+// ProjectContractColumns represents the columns project contracts can be ordered and aggregated by.
+var ProjectContractColumns = struct {
+	Id           Column[types.ProjectContract, int64]
+	AddedAtBlock Column[types.ProjectContract, uint64]
+}{
+	Id:           Column[types.ProjectContract, int64]{name: "id"},
+	AddedAtBlock: Column[types.ProjectContract, uint64]{name: "added_at_block"},
 }
 
 // WhereProjectId adds a where clause to the query builder.
@@ -46,12 +55,49 @@ func (qb *ProjectContractQueryBuilder) WhereAddress(address *types.Address) *Pro
 	return qb
 }
 
-// StoreProjectContract stores the project contract in the database.
-func (db *Db) StoreProjectContract(ctx context.Context, project *types.ProjectContract) error {
-	query := `INSERT INTO project_contract (project_id, address, is_approved) VALUES (:project_id, :address, :is_approved)`
-	_, err := sqlx.NamedExecContext(ctx, db.con, query, project)
+// WhereNotRemoved adds a where clause to the query builder.
+func (qb *ProjectContractQueryBuilder) WhereNotRemoved() *ProjectContractQueryBuilder {
+	qb.where = append(qb.where, "removed_at_block IS NULL")
+	return qb
+}
+
+// WhereMemberAtBlock adds a where clause to the query builder.
+func (qb *ProjectContractQueryBuilder) WhereMemberAtBlock(block uint64) *ProjectContractQueryBuilder {
+	qb.where = append(qb.where, "added_at_block <= :block AND (removed_at_block IS NULL OR removed_at_block > :block)")
+	qb.parameters["block"] = block
+	return qb
+}
+
+// WhereAddedAtBlock adds a where clause to the query builder.
+func (qb *ProjectContractQueryBuilder) WhereAddedAtBlock(block uint64) *ProjectContractQueryBuilder {
+	qb.where = append(qb.where, "added_at_block = :added_at_block")
+	qb.parameters["added_at_block"] = block
+	return qb
+}
+
+// StoreProjectContract stores the project contract in the database. The contract can be an open member
+// of a single project of each gas monetization contract, ErrDuplicate is returned otherwise.
+func (db *Db) StoreProjectContract(ctx context.Context, contract *types.ProjectContract) error {
+	query := `INSERT INTO project_contract (project_id, contract_address, address, is_approved, added_at_epoch, added_at_block, removed_at_epoch, removed_at_block) 
+				VALUES (:project_id, (SELECT contract_address FROM project WHERE id = :project_id), :address, :is_approved, 
+				        :added_at_epoch, :added_at_block, :removed_at_epoch, :removed_at_block)`
+	_, err := sqlx.NamedExecContext(ctx, db.con, query, contract)
+	if err != nil {
+		db.log.Errorf("failed to store project contract %s: %v", contract.Address.Hex(), err)
+		return mapError(err)
+	}
+	return nil
+}
+
+// RemoveProjectContract closes the membership of the contract in the project at the given epoch and block.
+// The contract row is kept, so the attribution of already processed blocks can be reproduced.
+func (db *Db) RemoveProjectContract(ctx context.Context, contract *types.ProjectContract, epoch uint64, block uint64) error {
+	contract.RemovedAtEpoch = &epoch
+	contract.RemovedAtBlock = &block
+	query := `UPDATE project_contract SET removed_at_epoch = :removed_at_epoch, removed_at_block = :removed_at_block WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, db.con, query, contract)
 	if err != nil {
-		db.log.Errorf("failed to store project contract %d: %v", project.Address.Hex(), err)
+		db.log.Errorf("failed to remove project contract %s: %v", contract.Address.Hex(), err)
 		return err
 	}
 	return nil
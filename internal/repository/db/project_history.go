@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type ProjectHistoryQueryBuilder struct {
+	queryBuilder[types.ProjectHistory, *ProjectHistoryQueryBuilder]
+}
+
+// ProjectHistoryQuery returns a new project history query builder.
+func (db *Db) ProjectHistoryQuery(ctx context.Context) *ProjectHistoryQueryBuilder {
+	qb := &ProjectHistoryQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.ProjectHistory](ctx, db.con, "project_history", qb)
+	return qb
+}
+
+// ProjectHistoryColumns represents the columns project attribute changes can be ordered and aggregated by.
+var ProjectHistoryColumns = struct {
+	Id          Column[types.ProjectHistory, int64]
+	BlockNumber Column[types.ProjectHistory, uint64]
+	LogIndex    Column[types.ProjectHistory, uint64]
+	Epoch       Column[types.ProjectHistory, uint64]
+}{
+	Id:          Column[types.ProjectHistory, int64]{name: "id"},
+	BlockNumber: Column[types.ProjectHistory, uint64]{name: "block_number"},
+	LogIndex:    Column[types.ProjectHistory, uint64]{name: "log_index"},
+	Epoch:       Column[types.ProjectHistory, uint64]{name: "epoch_number"},
+}
+
+// WhereProjectId adds a where clause to the query builder.
+func (qb *ProjectHistoryQueryBuilder) WhereProjectId(projectId int64) *ProjectHistoryQueryBuilder {
+	qb.where = append(qb.where, "project_id = :project_id")
+	qb.parameters["project_id"] = projectId
+	return qb
+}
+
+// WhereAttribute adds a where clause to the query builder.
+func (qb *ProjectHistoryQueryBuilder) WhereAttribute(attribute string) *ProjectHistoryQueryBuilder {
+	qb.where = append(qb.where, "attribute = :attribute")
+	qb.parameters["attribute"] = attribute
+	return qb
+}
+
+// WhereEpochLte adds a where clause to the query builder.
+func (qb *ProjectHistoryQueryBuilder) WhereEpochLte(epoch uint64) *ProjectHistoryQueryBuilder {
+	qb.where = append(qb.where, "epoch_number <= :epoch_number")
+	qb.parameters["epoch_number"] = epoch
+	return qb
+}
+
+// StoreProjectHistory stores the project attribute change into the database. A change already
+// recorded for the same log record is kept, so a replayed block does not record it again.
+func (db *Db) StoreProjectHistory(ctx context.Context, history *types.ProjectHistory) error {
+	query := `INSERT INTO project_history (project_id, attribute, old_value, new_value, block_number, log_index,
+                             epoch_number, tx_hash)
+				VALUES (:project_id, :attribute, :old_value, :new_value, :block_number, :log_index, :epoch_number, :tx_hash)
+				ON CONFLICT (project_id, block_number, log_index, attribute) DO NOTHING`
+	_, err := sqlx.NamedExecContext(ctx, db.con, query, history)
+	if err != nil {
+		db.log.Errorf("failed to store %s change of project %d: %v", history.Attribute, history.ProjectId, err)
+		return mapError(err)
+	}
+	return nil
+}
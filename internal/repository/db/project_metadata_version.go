@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/types"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type ProjectMetadataVersionQueryBuilder struct {
+	queryBuilder[types.ProjectMetadataVersion, *ProjectMetadataVersionQueryBuilder]
+}
+
+// ProjectMetadataVersionQuery returns a new project metadata version query builder.
+func (db *Db) ProjectMetadataVersionQuery(ctx context.Context) *ProjectMetadataVersionQueryBuilder {
+	qb := &ProjectMetadataVersionQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.ProjectMetadataVersion](ctx, db.con, "project_metadata_version", qb)
+	return qb
+}
+
+// ProjectMetadataVersionColumns represents the columns project metadata versions can be ordered and aggregated by.
+var ProjectMetadataVersionColumns = struct {
+	Id        Column[types.ProjectMetadataVersion, int64]
+	FetchedAt Column[types.ProjectMetadataVersion, time.Time]
+}{
+	Id:        Column[types.ProjectMetadataVersion, int64]{name: "id"},
+	FetchedAt: Column[types.ProjectMetadataVersion, time.Time]{name: "fetched_at"},
+}
+
+// WhereProjectId adds a where clause to the query builder.
+func (qb *ProjectMetadataVersionQueryBuilder) WhereProjectId(projectId int64) *ProjectMetadataVersionQueryBuilder {
+	qb.where = append(qb.where, "project_id = :project_id")
+	qb.parameters["project_id"] = projectId
+	return qb
+}
+
+// StoreProjectMetadataVersion stores the metadata document of the project unless it equals the latest
+// stored version of the project. Returns true if a new version was stored.
+func (db *Db) StoreProjectMetadataVersion(ctx context.Context, version *types.ProjectMetadataVersion) (bool, error) {
+	query := `INSERT INTO project_metadata_version (project_id, uri, content_hash, content, schema_version, fetched_at)
+				SELECT :project_id, :uri, :content_hash, :content, :schema_version, :fetched_at
+				WHERE NOT EXISTS (
+				    SELECT 1 FROM (
+				        SELECT content_hash FROM project_metadata_version WHERE project_id = :project_id ORDER BY id DESC LIMIT 1
+				    ) latest WHERE latest.content_hash = :content_hash
+				)`
+	res, err := sqlx.NamedExecContext(ctx, db.con, query, version)
+	if err != nil {
+		db.log.Errorf("failed to store metadata version of project %d: %v", version.ProjectId, err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
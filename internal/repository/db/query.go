@@ -3,27 +3,109 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"ftm-gas-monetization/internal/types"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"math/big"
 	"strings"
 )
 
-// queryBuilder is a helper to build SQL queries.
-type queryBuilder[T any] struct {
+// Order represents the direction of the query ordering.
+type Order string
+
+const (
+	// Asc orders the results from the lowest value.
+	Asc Order = "ASC"
+	// Desc orders the results from the highest value.
+	Desc Order = "DESC"
+)
+
+// Cursor represents the position in an ordered result set, i.e. values of the ordering columns of the last
+// row of the previous page. The values are bound to the query as parameters.
+type Cursor []interface{}
+
+// Column represents a column of the table of T records holding values of the type V. Columns are declared
+// along with the query builders of their tables, so no SQL provided by the callers reaches the queries.
+type Column[T any, V any] struct {
+	name string
+}
+
+// TableColumn represents a column of the table of T records regardless of the type of its values.
+type TableColumn[T any] interface {
+	columnName() string
+	// record binds the column to the records of its table, so columns of other tables are rejected
+	record() *T
+}
+
+// Query represents a query over T records the aggregates of columns are calculated over.
+type Query[T any] interface {
+	aggregate(expr string, dest interface{}) error
+	record() *T
+}
+
+// columnName returns the name of the column.
+func (c Column[T, V]) columnName() string {
+	return c.name
+}
+
+// record binds the column to the records of its table.
+func (c Column[T, V]) record() *T {
+	return nil
+}
+
+// Max returns the highest value of the column in rows matching the query, nil if there is no such row.
+func (c Column[T, V]) Max(q Query[T]) (*V, error) {
+	var max *V
+	err := q.aggregate("MAX("+c.name+")", &max)
+	return max, err
+}
+
+// Min returns the lowest value of the column in rows matching the query, nil if there is no such row.
+func (c Column[T, V]) Min(q Query[T]) (*V, error) {
+	var min *V
+	err := q.aggregate("MIN("+c.name+")", &min)
+	return min, err
+}
+
+// Sum returns the sum of the numeric column in rows matching the query, zero if there is no such row.
+func (c Column[T, V]) Sum(q Query[T]) (*big.Int, error) {
+	var sum types.Big
+	if err := q.aggregate("COALESCE(SUM("+c.name+"), 0)", &sum); err != nil {
+		return nil, err
+	}
+	return sum.ToInt(), nil
+}
+
+// orderBy represents a single ordering column of the query.
+type orderBy struct {
+	column string
+	order  Order
+}
+
+// queryBuilder is a helper to build SQL queries over T records. The methods return the query builder B
+// embedding it, so the conditions of the particular builder can be chained after the common ones.
+type queryBuilder[T any, B any] struct {
+	self       B
 	con        sqlx.ExtContext
 	ctx        context.Context
 	table      string
 	where      []string
 	parameters map[string]interface{}
 	fields     string
+	orderBy    []orderBy
+	after      Cursor
+	limit      uint64
+	offset     uint64
 }
 
-// newQueryBuilder returns a new query builder.
-func newQueryBuilder[T any](ctx context.Context, con sqlx.ExtContext, table string) queryBuilder[T] {
-	return queryBuilder[T]{
+// newQueryBuilder returns a new query builder embedded in the given one.
+func newQueryBuilder[T any, B any](ctx context.Context, con sqlx.ExtContext, table string, self B) queryBuilder[T, B] {
+	return queryBuilder[T, B]{
+		self:       self,
 		con:        con,
 		ctx:        ctx,
 		table:      table,
@@ -32,23 +114,78 @@ func newQueryBuilder[T any](ctx context.Context, con sqlx.ExtContext, table stri
 	}
 }
 
+// record binds the query to the records of its table.
+func (qb *queryBuilder[T, B]) record() *T {
+	return nil
+}
+
 // WhereId adds a where clause to the query builder.
-func (qb *queryBuilder[T]) WhereId(id int64) *queryBuilder[T] {
+func (qb *queryBuilder[T, B]) WhereId(id int64) B {
 	qb.where = append(qb.where, "id = :id")
 	qb.parameters["id"] = id
-	return qb
+	return qb.self
+}
+
+// Select sets the columns to select.
+func (qb *queryBuilder[T, B]) Select(columns ...TableColumn[T]) B {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.columnName()
+	}
+	qb.fields = strings.Join(names, ", ")
+	return qb.self
+}
+
+// OrderBy adds an ordering column to the query builder. The columns are applied in the order they are added.
+func (qb *queryBuilder[T, B]) OrderBy(column TableColumn[T], order Order) B {
+	qb.orderBy = append(qb.orderBy, orderBy{column: column.columnName(), order: order})
+	return qb.self
+}
+
+// Limit limits the number of results of the query.
+func (qb *queryBuilder[T, B]) Limit(limit uint64) B {
+	qb.limit = limit
+	return qb.self
+}
+
+// Offset skips the given number of results of the query.
+func (qb *queryBuilder[T, B]) Offset(offset uint64) B {
+	qb.offset = offset
+	return qb.self
 }
 
-// Select sets the fields to select.
-func (qb *queryBuilder[T]) Select(fields ...string) *queryBuilder[T] {
-	qb.fields = strings.Join(fields, ", ")
-	return qb
+// After limits the results to rows following the given cursor in the query ordering. The cursor
+// holds a value for each of the ordering columns.
+func (qb *queryBuilder[T, B]) After(cursor Cursor) B {
+	qb.after = cursor
+	return qb.self
+}
+
+// WhereGreaterOrEqual adds a where clause limiting the column to values from the given one, inclusive.
+func (qb *queryBuilder[T, B]) WhereGreaterOrEqual(column TableColumn[T], value interface{}) B {
+	qb.where = append(qb.where, column.columnName()+" >= "+qb.bind(value))
+	return qb.self
+}
+
+// WhereLessOrEqual adds a where clause limiting the column to values up to the given one, inclusive.
+func (qb *queryBuilder[T, B]) WhereLessOrEqual(column TableColumn[T], value interface{}) B {
+	qb.where = append(qb.where, column.columnName()+" <= "+qb.bind(value))
+	return qb.self
+}
+
+// WhereBetween adds a where clause limiting the column to the given range, inclusive.
+func (qb *queryBuilder[T, B]) WhereBetween(column TableColumn[T], from interface{}, to interface{}) B {
+	qb.where = append(qb.where, column.columnName()+" BETWEEN "+qb.bind(from)+" AND "+qb.bind(to))
+	return qb.self
 }
 
 // GetAll returns all results of the query. If no result is found, nil is returned.
-func (qb *queryBuilder[T]) GetAll() ([]T, error) {
-	query := qb.buildSelectQuery()
-	rows, err := sqlx.NamedQueryContext(qb.ctx, qb.con, query, qb.parameters)
+func (qb *queryBuilder[T, B]) GetAll() ([]T, error) {
+	query, parameters, err := qb.buildSelectQuery()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sqlx.NamedQueryContext(qb.ctx, qb.con, query, parameters)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -67,16 +204,23 @@ func (qb *queryBuilder[T]) GetAll() ([]T, error) {
 	return result, nil
 }
 
-// GetFirstOrFail returns the first result of the query. If no result is found, an error is returned.
-func (qb *queryBuilder[T]) GetFirstOrFail() (*T, error) {
-	query := qb.buildSelectQuery() + " LIMIT 1"
-	rows, err := sqlx.NamedQueryContext(qb.ctx, qb.con, query, qb.parameters)
+// GetFirstOrFail returns the first result of the query. If no result is found, an error wrapping
+// sql.ErrNoRows is returned.
+func (qb *queryBuilder[T, B]) GetFirstOrFail() (*T, error) {
+	// the limit applies to this query only, the builder can be used again
+	first := qb.clone()
+	first.limit = 1
+	query, parameters, err := first.buildSelectQuery()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sqlx.NamedQueryContext(qb.ctx, qb.con, query, parameters)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	if !rows.Next() {
-		return nil, fmt.Errorf("no result found for query: %s", query)
+		return nil, fmt.Errorf("no result found for query: %s; %w", query, sql.ErrNoRows)
 	}
 	var result T
 	if err := rows.StructScan(&result); err != nil {
@@ -86,10 +230,10 @@ func (qb *queryBuilder[T]) GetFirstOrFail() (*T, error) {
 }
 
 // GetFirst returns the first result of the query. If no result is found, nil is returned.
-func (qb *queryBuilder[T]) GetFirst() (*T, error) {
+func (qb *queryBuilder[T, B]) GetFirst() (*T, error) {
 	result, err := qb.GetFirstOrFail()
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
@@ -97,12 +241,32 @@ func (qb *queryBuilder[T]) GetFirst() (*T, error) {
 	return result, nil
 }
 
-// Delete deletes all results of the query.
-func (qb *queryBuilder[T]) Delete() error {
-	query := "DELETE FROM " + qb.table
-	if len(qb.where) > 0 {
-		query += " WHERE " + strings.Join(qb.where, " AND ")
+// Count returns the number of rows matching the query, regardless of the pagination.
+func (qb *queryBuilder[T, B]) Count() (uint64, error) {
+	var count uint64
+	err := qb.aggregate("COUNT(*)", &count)
+	return count, err
+}
+
+// CountDistinct returns the number of distinct values of the column in rows matching the query.
+func (qb *queryBuilder[T, B]) CountDistinct(column TableColumn[T]) (uint64, error) {
+	var count uint64
+	err := qb.aggregate("COUNT(DISTINCT "+column.columnName()+")", &count)
+	return count, err
+}
+
+// aggregate loads the given aggregate expression over rows matching the query into the given destination.
+func (qb *queryBuilder[T, B]) aggregate(expr string, dest interface{}) error {
+	query, args, err := sqlx.Named("SELECT "+expr+" FROM "+qb.table+qb.buildWhere(qb.where), qb.parameters)
+	if err != nil {
+		return err
 	}
+	return sqlx.GetContext(qb.ctx, qb.con, dest, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+}
+
+// Delete deletes all results of the query.
+func (qb *queryBuilder[T, B]) Delete() error {
+	query := "DELETE FROM " + qb.table + qb.buildWhere(qb.where)
 	_, err := sqlx.NamedExecContext(qb.ctx, qb.con, query, qb.parameters)
 	if err != nil {
 		return err
@@ -110,11 +274,111 @@ func (qb *queryBuilder[T]) Delete() error {
 	return nil
 }
 
-// buildSelectQuery builds the select query string.
-func (qb *queryBuilder[T]) buildSelectQuery() string {
-	query := "SELECT " + qb.fields + " FROM " + qb.table
-	if len(qb.where) > 0 {
-		query += " WHERE " + strings.Join(qb.where, " AND ")
+// bind adds the given value to the query parameters and returns its placeholder.
+func (qb *queryBuilder[T, B]) bind(value interface{}) string {
+	name := fmt.Sprintf("p%d", len(qb.parameters))
+	for _, ok := qb.parameters[name]; ok; _, ok = qb.parameters[name] {
+		name += "_"
+	}
+	qb.parameters[name] = value
+	return ":" + name
+}
+
+// clone returns a copy of the query builder, which can be changed without affecting the original.
+func (qb *queryBuilder[T, B]) clone() *queryBuilder[T, B] {
+	c := *qb
+	c.where = append([]string(nil), qb.where...)
+	c.orderBy = append([]orderBy(nil), qb.orderBy...)
+	c.parameters = make(map[string]interface{}, len(qb.parameters))
+	for k, v := range qb.parameters {
+		c.parameters[k] = v
+	}
+	return &c
+}
+
+// buildWhere builds the where clause of the given conditions, if any.
+func (qb *queryBuilder[T, B]) buildWhere(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+// buildAfter builds the keyset pagination condition of rows following the cursor, i.e.
+// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ... with the comparison following the direction of each column.
+func (qb *queryBuilder[T, B]) buildAfter() (string, error) {
+	if len(qb.after) != len(qb.orderBy) {
+		return "", fmt.Errorf("cursor of %d values does not match %d ordering columns", len(qb.after), len(qb.orderBy))
+	}
+	values := make([]string, len(qb.after))
+	for i := range qb.after {
+		values[i] = qb.bind(qb.after[i])
+	}
+	var or []string
+	for i, ob := range qb.orderBy {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, qb.orderBy[j].column+" = "+values[j])
+		}
+		op := " > "
+		if ob.order == Desc {
+			op = " < "
+		}
+		and = append(and, ob.column+op+values[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return "(" + strings.Join(or, " OR ") + ")", nil
+}
+
+// buildSelectQuery builds the select query string and its parameters. The query is built from a copy
+// of the builder, so building it does not change the builder.
+func (qb *queryBuilder[T, B]) buildSelectQuery() (string, map[string]interface{}, error) {
+	c := qb.clone()
+	if c.after != nil {
+		after, err := c.buildAfter()
+		if err != nil {
+			return "", nil, err
+		}
+		c.where = append(c.where, after)
+	}
+	query := "SELECT " + c.fields + " FROM " + c.table + c.buildWhere(c.where)
+	if len(c.orderBy) > 0 {
+		order := make([]string, len(c.orderBy))
+		for i, ob := range c.orderBy {
+			order[i] = ob.column + " " + string(ob.order)
+		}
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+	if c.limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", c.limit)
+	}
+	if c.offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", c.offset)
+	}
+	return query, c.parameters, nil
+}
+
+// EncodeCursor encodes the cursor into an opaque string to be handed over to API clients.
+func EncodeCursor(cursor Cursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes the cursor previously encoded by EncodeCursor. Numbers are kept
+// in their decimal representation, so they don't lose precision.
+func DecodeCursor(encoded string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", err)
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var cursor Cursor
+	if err := dec.Decode(&cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", err)
 	}
-	return query
+	return cursor, nil
 }
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"ftm-gas-monetization/internal/types"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestBuildSelectQuery tests ordering, pagination and range predicates are built into the select query
+func TestBuildSelectQuery(t *testing.T) {
+	qb := (&Db{}).ContractEventQuery(context.Background())
+	qb.WhereBetween(ContractEventColumns.BlockNumber, 10, 20).
+		OrderBy(ContractEventColumns.BlockNumber, Asc).
+		OrderBy(ContractEventColumns.LogIndex, Desc).
+		After(Cursor{15, 3}).
+		Limit(5).
+		Offset(10)
+	query, parameters, err := qb.buildSelectQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM contract_event WHERE block_number BETWEEN :p0 AND :p1 AND "+
+		"((block_number > :p2) OR (block_number = :p2 AND log_index < :p3)) "+
+		"ORDER BY block_number ASC, log_index DESC LIMIT 5 OFFSET 10", query)
+	assert.Equal(t, map[string]interface{}{"p0": 10, "p1": 20, "p2": 15, "p3": 3}, parameters)
+
+	// building the query does not change the builder, so it builds the same query again
+	again, parametersAgain, err := qb.buildSelectQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, query, again)
+	assert.Equal(t, parameters, parametersAgain)
+	assert.Len(t, qb.where, 1)
+	assert.Len(t, qb.parameters, 2)
+
+	qb = (&Db{}).ContractEventQuery(context.Background())
+	_, _, err = qb.OrderBy(ContractEventColumns.BlockNumber, Asc).After(Cursor{1, 2}).buildSelectQuery()
+	assert.NotNil(t, err)
+}
+
+// TestCursorEncoding tests cursors survive the encoding without losing precision
+func TestCursorEncoding(t *testing.T) {
+	encoded, err := EncodeCursor(Cursor{uint64(18446744073709551615), "0xabc"})
+	assert.Nil(t, err)
+	cursor, err := DecodeCursor(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "18446744073709551615", cursor[0].(fmt.Stringer).String())
+	assert.Equal(t, "0xabc", cursor[1])
+
+	_, err = DecodeCursor("not a cursor")
+	assert.NotNil(t, err)
+}
+
+// TestColumns tests the declared columns match the db tags of the records of their tables
+func TestColumns(t *testing.T) {
+	for _, tc := range []struct {
+		columns interface{}
+		record  interface{}
+	}{
+		{TransactionColumns, types.Transaction{}},
+		{ContractEventColumns, types.ContractEvent{}},
+		{ProjectMetadataVersionColumns, types.ProjectMetadataVersion{}},
+		{ProjectColumns, types.Project{}},
+		{ProjectActivityPeriodColumns, types.ProjectActivityPeriod{}},
+		{ProjectHistoryColumns, types.ProjectHistory{}},
+		{ProjectContractColumns, types.ProjectContract{}},
+		{EpochColumns, types.Epoch{}},
+		{WithdrawalRequestColumns, types.WithdrawalRequest{}},
+	} {
+		tags := make(map[string]bool)
+		record := reflect.TypeOf(tc.record)
+		for i := 0; i < record.NumField(); i++ {
+			if tag, ok := record.Field(i).Tag.Lookup("db"); ok {
+				tags[strings.Split(tag, ",")[0]] = true
+			}
+		}
+		columns := reflect.ValueOf(tc.columns)
+		for i := 0; i < columns.NumField(); i++ {
+			name := columns.Field(i).FieldByName("name").String()
+			assert.Truef(t, tags[name], "%s column %s is not a db tag of the record", record.Name(), name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/logger"
+	"github.com/jmoiron/sqlx"
+	"sync/atomic"
+	"time"
+)
+
+// replicaPingTimeout is the maximum time we wait for a replica to answer the health check.
+const replicaPingTimeout = 5 * time.Second
+
+// ReplicaSet routes read only queries to healthy read replicas, falling back to the primary
+// database if no replica is healthy. Writes MUST NOT be sent to the replicas.
+type ReplicaSet struct {
+	log      *logger.AppLogger
+	primary  *Db
+	replicas []*replica
+	// next is the counter used to spread the reads among the replicas
+	next    atomic.Uint64
+	tick    *time.Ticker
+	sigStop chan struct{}
+}
+
+// replica represents a single read replica and its last known health.
+type replica struct {
+	db      *Db
+	healthy atomic.Bool
+}
+
+// NewReplicaSet creates a set of the configured read replicas of the primary database
+// and starts checking their health in the configured interval.
+func NewReplicaSet(config *config.DB, primary *Db, log *logger.AppLogger) *ReplicaSet {
+	rs := ReplicaSet{
+		log:      log.ModuleLogger("replica"),
+		primary:  primary,
+		replicas: make([]*replica, 0, len(config.Replicas)),
+		sigStop:  make(chan struct{}),
+	}
+
+	for i, dsn := range config.Replicas {
+		// the connection is not verified, an unavailable replica is just not used until it recovers
+		con, err := sqlx.Open("postgres", dsn)
+		if err != nil {
+			rs.log.Errorf("failed to open read replica #%d; %s", i, err)
+			continue
+		}
+		rs.replicas = append(rs.replicas, &replica{db: &Db{
+			db:  con,
+			log: rs.log,
+			con: con,
+		}})
+	}
+
+	rs.CheckHealth()
+	if len(rs.replicas) > 0 {
+		rs.tick = time.NewTicker(time.Duration(config.ReplicaCheckInterval) * time.Second)
+		go rs.monitor()
+	}
+	return &rs
+}
+
+// Reader returns the database to run read only queries against; a healthy replica
+// if there is any, the primary database otherwise.
+func (rs *ReplicaSet) Reader() *Db {
+	count := uint64(len(rs.replicas))
+	start := rs.next.Add(1)
+	for i := uint64(0); i < count; i++ {
+		r := rs.replicas[(start+i)%count]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return rs.primary
+}
+
+// CheckHealth pings all the replicas and updates their health.
+func (rs *ReplicaSet) CheckHealth() {
+	for i, r := range rs.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+		err := r.db.db.PingContext(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				rs.log.Noticef("read replica #%d is healthy", i)
+			} else {
+				rs.log.Warningf("read replica #%d is unhealthy, reads fall back; %s", i, err)
+			}
+		}
+	}
+}
+
+// Close stops the health checks and closes the replica connections.
+func (rs *ReplicaSet) Close() {
+	if rs.tick != nil {
+		rs.tick.Stop()
+		close(rs.sigStop)
+	}
+	for _, r := range rs.replicas {
+		if err := r.db.db.Close(); err != nil {
+			rs.log.Errorf("failed to close read replica connection; %s", err)
+		}
+	}
+}
+
+// monitor periodically checks the health of the replicas.
+func (rs *ReplicaSet) monitor() {
+	for {
+		select {
+		case <-rs.sigStop:
+			return
+		case <-rs.tick.C:
+			rs.CheckHealth()
+		}
+	}
+}
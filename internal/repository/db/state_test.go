@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/assert"
 	"math/big"
 )
@@ -97,3 +99,24 @@ func (s *DbTestSuite) TestTotalTransactionsCount() {
 	assert.Nil(s.T(), err)
 	assert.EqualValues(s.T(), 400, count)
 }
+
+func (s *DbTestSuite) TestRebuildStats() {
+	project := s.storeTestProject(1)
+	project.CollectedRewards = &types.Big{Big: hexutil.Big(*big.NewInt(300))}
+	project.ClaimedRewards = &types.Big{Big: hexutil.Big(*big.NewInt(100))}
+	assert.Nil(s.T(), s.db.UpdateProject(context.Background(), project))
+	// the transactions count is kept, the counted transactions may be deleted or expired already
+	assert.Nil(s.T(), s.db.StoreTransaction(context.Background(), testTransaction(project.Id)))
+	assert.Nil(s.T(), s.db.IncreaseTotalAmountCollected(context.Background(), big.NewInt(1)))
+	assert.Nil(s.T(), s.db.IncreaseTotalTransactionsCount(context.Background(), 5))
+
+	stats, err := s.db.RebuildStats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 300, stats.TotalAmountCollected.ToInt().Int64())
+	assert.EqualValues(s.T(), 100, stats.TotalAmountClaimed.ToInt().Int64())
+	assert.EqualValues(s.T(), 5, stats.TotalTransactionsCount)
+
+	amount, err := s.db.TotalAmountCollected(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 300, amount.Int64())
+}
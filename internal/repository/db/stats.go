@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jmoiron/sqlx"
+	"math/big"
+)
+
+// statsUpsert is the statement updating a column of the stats row, creating the row if it does not exist yet.
+// The column is substituted, the value is the only parameter.
+const statsUpsert = "INSERT INTO stats (id, %[1]s) VALUES (TRUE, $1) ON CONFLICT (id) DO UPDATE SET %[1]s = %[2]s"
+
+// Stats returns the global totals.
+func (db *Db) Stats(ctx context.Context) (*types.Stats, error) {
+	var stats types.Stats
+	err := sqlx.GetContext(ctx, db.con, &stats, `SELECT total_amount_collected, total_amount_claimed, total_transactions_count 
+		FROM stats WHERE id`)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			db.log.Warningf("no stats found, assuming zero totals")
+			return &types.Stats{}, nil
+		}
+		db.log.Errorf("failed to get stats: %s", err)
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// TotalAmountCollected returns the total amount collected.
+func (db *Db) TotalAmountCollected(ctx context.Context) (*big.Int, error) {
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stats.TotalAmountCollected.ToInt(), nil
+}
+
+// SetTotalAmountCollected sets the total amount collected.
+func (db *Db) SetTotalAmountCollected(ctx context.Context, amount *big.Int) error {
+	if err := db.updateStats(ctx, "total_amount_collected", false, &types.Big{Big: hexutil.Big(*amount)}); err != nil {
+		db.log.Errorf("failed to set total amount collected: %s", err)
+		return err
+	}
+	db.log.Noticef("total amount collected set to %d", amount)
+	return nil
+}
+
+// IncreaseTotalAmountCollected increases the total amount collected.
+func (db *Db) IncreaseTotalAmountCollected(ctx context.Context, amount *big.Int) error {
+	if err := db.updateStats(ctx, "total_amount_collected", true, &types.Big{Big: hexutil.Big(*amount)}); err != nil {
+		db.log.Errorf("failed to increase total amount collected: %s", err)
+		return err
+	}
+	db.log.Noticef("total amount collected increased by %d", amount)
+	return nil
+}
+
+// TotalAmountClaimed returns the total amount claimed.
+func (db *Db) TotalAmountClaimed(ctx context.Context) (*big.Int, error) {
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stats.TotalAmountClaimed.ToInt(), nil
+}
+
+// SetTotalAmountClaimed sets the total amount claimed.
+func (db *Db) SetTotalAmountClaimed(ctx context.Context, amount *big.Int) error {
+	if err := db.updateStats(ctx, "total_amount_claimed", false, &types.Big{Big: hexutil.Big(*amount)}); err != nil {
+		db.log.Errorf("failed to set total amount claimed: %s", err)
+		return err
+	}
+	db.log.Noticef("total amount claimed set to %d", amount)
+	return nil
+}
+
+// IncreaseTotalAmountClaimed increases the total amount claimed.
+func (db *Db) IncreaseTotalAmountClaimed(ctx context.Context, amount *big.Int) error {
+	if err := db.updateStats(ctx, "total_amount_claimed", true, &types.Big{Big: hexutil.Big(*amount)}); err != nil {
+		db.log.Errorf("failed to increase total amount claimed: %s", err)
+		return err
+	}
+	db.log.Noticef("total amount claimed increased by %d", amount)
+	return nil
+}
+
+// TotalTransactionsCount returns the total number of transactions.
+func (db *Db) TotalTransactionsCount(ctx context.Context) (uint64, error) {
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return stats.TotalTransactionsCount, nil
+}
+
+// SetTotalTransactionsCount sets the total number of transactions.
+func (db *Db) SetTotalTransactionsCount(ctx context.Context, count uint64) error {
+	if err := db.updateStats(ctx, "total_transactions_count", false, count); err != nil {
+		db.log.Errorf("failed to set total transactions count: %s", err)
+		return err
+	}
+	db.log.Noticef("total transactions count set to %d", count)
+	return nil
+}
+
+// IncreaseTotalTransactionsCount increases the total number of transactions.
+func (db *Db) IncreaseTotalTransactionsCount(ctx context.Context, count uint64) error {
+	if err := db.updateStats(ctx, "total_transactions_count", true, count); err != nil {
+		db.log.Errorf("failed to increase total transactions count: %s", err)
+		return err
+	}
+	db.log.Noticef("total transactions count increased by %d", count)
+	return nil
+}
+
+// RebuildStats recalculates the total amounts collected and claimed from the rewards of the projects.
+// The total transactions count is kept, it can not be derived from the stored transactions, which are
+// deleted once withdrawn and expire with their partitions past the retention period.
+func (db *Db) RebuildStats(ctx context.Context) (*types.Stats, error) {
+	var stats types.Stats
+	err := sqlx.GetContext(ctx, db.con, &stats, `INSERT INTO stats (id, total_amount_collected, total_amount_claimed)
+		SELECT TRUE,
+		       (SELECT COALESCE(SUM(collected_rewards), 0) FROM project),
+		       (SELECT COALESCE(SUM(claimed_rewards), 0) FROM project)
+		ON CONFLICT (id) DO UPDATE SET total_amount_collected = EXCLUDED.total_amount_collected,
+		    total_amount_claimed = EXCLUDED.total_amount_claimed
+		RETURNING total_amount_collected, total_amount_claimed, total_transactions_count`)
+	if err != nil {
+		db.log.Errorf("failed to rebuild stats: %s", err)
+		return nil, err
+	}
+	db.log.Noticef("stats rebuilt; collected %d, claimed %d, %d transactions",
+		stats.TotalAmountCollected.ToInt(), stats.TotalAmountClaimed.ToInt(), stats.TotalTransactionsCount)
+	return &stats, nil
+}
+
+// updateStats sets or increases the given column of the stats row by the given value in a single statement.
+func (db *Db) updateStats(ctx context.Context, column string, increase bool, value interface{}) error {
+	update := "EXCLUDED." + column
+	if increase {
+		update = "stats." + column + " + EXCLUDED." + column
+	}
+	_, err := db.con.ExecContext(ctx, fmt.Sprintf(statsUpsert, column, update), value)
+	return err
+}
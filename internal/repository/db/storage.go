@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// Db implements the storage of the gas monetization state, keeping the transactions in partitions.
+var (
+	_ storage.Storage     = (*Db)(nil)
+	_ storage.Partitioned = (*Db)(nil)
+)
+
+// FindProjects returns projects matching the filter ordered by their id.
+func (db *Db) FindProjects(ctx context.Context, filter storage.ProjectFilter) ([]types.Project, error) {
+	qb := db.ProjectQuery(ctx)
+	if filter.Contract != nil {
+		qb.WhereContract(filter.Contract)
+	}
+	if filter.ProjectId != nil {
+		qb.WhereProjectId(*filter.ProjectId)
+	}
+	if filter.Owner != nil {
+		qb.WhereOwner(filter.Owner)
+	}
+	if filter.ActiveInEpoch != nil {
+		qb.WhereActiveInEpoch(*filter.ActiveInEpoch)
+	}
+	if filter.Id != nil {
+		qb.WhereId(*filter.Id)
+	}
+	if filter.Category != nil {
+		qb.WhereCategory(*filter.Category)
+	}
+	if filter.Tag != nil {
+		qb.WhereTag(*filter.Tag)
+	}
+	return qb.OrderBy(ProjectColumns.Id, Asc).GetAll()
+}
+
+// FindProjectActivityPeriods returns the periods the project was active in ordered by their first epoch.
+func (db *Db) FindProjectActivityPeriods(ctx context.Context, projectId int64) ([]types.ProjectActivityPeriod, error) {
+	qb := db.ProjectActivityPeriodQuery(ctx)
+	return qb.WhereProjectId(projectId).
+		OrderBy(ProjectActivityPeriodColumns.FromEpoch, Asc).
+		OrderBy(ProjectActivityPeriodColumns.Id, Asc).
+		GetAll()
+}
+
+// FindProjectMetadataVersions returns the distinct metadata documents of the project ordered by their id.
+func (db *Db) FindProjectMetadataVersions(ctx context.Context, projectId int64) ([]types.ProjectMetadataVersion, error) {
+	qb := db.ProjectMetadataVersionQuery(ctx)
+	return qb.WhereProjectId(projectId).OrderBy(ProjectMetadataVersionColumns.Id, Asc).GetAll()
+}
+
+// FindProjectHistory returns changes of the project attributes matching the filter ordered by their id.
+func (db *Db) FindProjectHistory(ctx context.Context, filter storage.ProjectHistoryFilter) ([]types.ProjectHistory, error) {
+	qb := db.ProjectHistoryQuery(ctx)
+	if filter.ProjectId != nil {
+		qb.WhereProjectId(*filter.ProjectId)
+	}
+	if filter.Attribute != nil {
+		qb.WhereAttribute(*filter.Attribute)
+	}
+	return qb.OrderBy(ProjectHistoryColumns.Id, Asc).GetAll()
+}
+
+// ProjectAttributeAt returns the last change of the project attribute made up to the given epoch,
+// nil if the attribute was not set by then.
+func (db *Db) ProjectAttributeAt(ctx context.Context, projectId int64, attribute string, epoch uint64) (*types.ProjectHistory, error) {
+	return db.ProjectHistoryQuery(ctx).
+		WhereProjectId(projectId).
+		WhereAttribute(attribute).
+		WhereEpochLte(epoch).
+		OrderBy(ProjectHistoryColumns.BlockNumber, Desc).
+		OrderBy(ProjectHistoryColumns.LogIndex, Desc).
+		GetFirst()
+}
+
+// FindProjectContracts returns project contracts matching the filter ordered by their id.
+func (db *Db) FindProjectContracts(ctx context.Context, filter storage.ProjectContractFilter) ([]types.ProjectContract, error) {
+	qb := db.ProjectContractQuery(ctx)
+	if filter.ProjectId != nil {
+		qb.WhereProjectId(*filter.ProjectId)
+	}
+	if filter.Address != nil {
+		qb.WhereAddress(filter.Address)
+	}
+	if filter.Approved != nil {
+		qb.WhereIsApproved(*filter.Approved)
+	}
+	if filter.NotRemoved {
+		qb.WhereNotRemoved()
+	}
+	if filter.MemberAtBlock != nil {
+		qb.WhereMemberAtBlock(*filter.MemberAtBlock)
+	}
+	if filter.AddedAtBlock != nil {
+		qb.WhereAddedAtBlock(*filter.AddedAtBlock)
+	}
+	return qb.OrderBy(ProjectContractColumns.Id, Asc).GetAll()
+}
+
+// FindTransactions returns rewarded transactions matching the filter ordered by their id.
+func (db *Db) FindTransactions(ctx context.Context, filter storage.TransactionFilter) ([]types.Transaction, error) {
+	qb := db.transactionQuery(ctx, filter)
+	if filter.Latest > 0 {
+		return qb.OrderBy(TransactionColumns.Id, Desc).Limit(filter.Latest).GetAll()
+	}
+	return qb.OrderBy(TransactionColumns.Id, Asc).GetAll()
+}
+
+// DeleteTransactions deletes rewarded transactions matching the filter.
+func (db *Db) DeleteTransactions(ctx context.Context, filter storage.TransactionFilter) error {
+	return db.transactionQuery(ctx, filter).Delete()
+}
+
+// transactionQuery returns the query of rewarded transactions matching the filter.
+func (db *Db) transactionQuery(ctx context.Context, filter storage.TransactionFilter) *TransactionQueryBuilder {
+	qb := db.TransactionQuery(ctx)
+	if filter.ProjectId != nil {
+		qb.WhereProjectId(*filter.ProjectId)
+	}
+	if filter.Contract != nil {
+		qb.WhereContract(filter.Contract)
+	}
+	if filter.Epoch != nil {
+		qb.WhereEpoch(*filter.Epoch)
+	}
+	if filter.EpochBefore != nil {
+		qb.WhereEpochLt(*filter.EpochBefore)
+	}
+	if filter.Hash != nil {
+		qb.WhereHash(filter.Hash)
+	}
+	return qb
+}
+
+// PrepareTransactionPartitions creates the missing partitions of the given size covering the given epochs.
+func (db *Db) PrepareTransactionPartitions(ctx context.Context, fromEpoch uint64, toEpoch uint64, size uint64) error {
+	_, err := db.EnsureTransactionPartitions(ctx, fromEpoch, toEpoch, size)
+	return err
+}
+
+// FindWithdrawalRequests returns withdrawal requests matching the filter ordered by their id.
+func (db *Db) FindWithdrawalRequests(ctx context.Context, filter storage.WithdrawalRequestFilter) ([]types.WithdrawalRequest, error) {
+	qb := db.WithdrawalRequestQuery(ctx)
+	if filter.ProjectId != nil {
+		qb.WhereProjectId(*filter.ProjectId)
+	}
+	if filter.Contract != nil {
+		qb.WhereContract(filter.Contract)
+	}
+	if filter.RequestEpoch != nil {
+		qb.WhereRequestEpoch(*filter.RequestEpoch)
+	}
+	if filter.NotWithdrawn {
+		qb.WhereNotWithdrawn()
+	}
+	return qb.OrderBy(WithdrawalRequestColumns.Id, Asc).GetAll()
+}
+
+// FindContractEvents returns contract events matching the filter ordered by their block and log index.
+func (db *Db) FindContractEvents(ctx context.Context, filter storage.ContractEventFilter) ([]types.ContractEvent, error) {
+	qb := db.ContractEventQuery(ctx)
+	if filter.Contract != nil {
+		qb.WhereContract(filter.Contract)
+	}
+	if filter.TxHash != nil {
+		qb.WhereTxHash(filter.TxHash)
+	}
+	if filter.LogIndex != nil {
+		qb.WhereLogIndex(*filter.LogIndex)
+	}
+	if filter.Name != nil {
+		qb.WhereName(*filter.Name)
+	}
+	if filter.Outcome != nil {
+		qb.WhereOutcome(*filter.Outcome)
+	}
+	if filter.FromBlock != nil {
+		qb.WhereBlockFrom(*filter.FromBlock)
+	}
+	if filter.ToBlock != nil {
+		qb.WhereBlockTo(*filter.ToBlock)
+	}
+	qb.OrderBy(ContractEventColumns.BlockNumber, Asc).OrderBy(ContractEventColumns.LogIndex, Asc)
+	if filter.After != nil {
+		qb.After(Cursor{filter.After.BlockNumber, filter.After.LogIndex})
+	}
+	if filter.Limit > 0 {
+		qb.Limit(filter.Limit)
+	}
+	return qb.GetAll()
+}
+
+// Atomic runs the given function in a database transaction. The function joins the running
+// transaction if the storage is already scoped to one.
+func (db *Db) Atomic(ctx context.Context, fn func(context.Context, storage.Storage) error) error {
+	if _, ok := db.con.(*sqlx.Tx); ok {
+		return fn(ctx, db)
+	}
+	return db.DatabaseTransaction(ctx, func(ctx context.Context, db *Db) error {
+		return fn(ctx, db)
+	})
+}
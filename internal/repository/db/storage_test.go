@@ -0,0 +1,16 @@
+package db
+
+import (
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/repository/storage/storagetest"
+	"github.com/stretchr/testify/suite"
+)
+
+func (s *DbTestSuite) TestStorageConformance() {
+	suite.Run(s.T(), &storagetest.Suite{New: func() storage.Storage {
+		// start each conformance test with empty tables
+		s.Require().Nil(s.db.Drop())
+		s.Require().Nil(s.db.Migrate())
+		return s.db.Db
+	}})
+}
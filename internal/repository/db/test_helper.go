@@ -17,11 +17,16 @@ const (
 	testDbPass = "test_password"
 	testDbPort = "5432"
 	testDbHost = "localhost"
+
+	// testPartitionEpochs is the number of epochs of the transaction partition created for the tests
+	testPartitionEpochs = 1000
 )
 
 type TestDatabase struct {
 	*Db
 	container testcontainers.Container
+	// dsn is the connection string of the test database
+	dsn string
 }
 
 // SetupTestDatabase creates a test container for postgres database and returns a database instance
@@ -33,15 +38,15 @@ func SetupTestDatabase(logger *logger.AppLogger) *TestDatabase {
 	if err != nil {
 		logger.Fatal("failed to setup test database", err)
 	}
-	db := New(
-		&config.DB{
-			Name:     testDbName,
-			User:     testDbUser,
-			Password: testDbPass,
-			Host:     testDbHost,
-			Port:     port,
-		},
-		logger)
+	cfg := &config.DB{
+		Name:        testDbName,
+		User:        testDbUser,
+		Password:    testDbPass,
+		Host:        testDbHost,
+		Port:        port,
+		AutoMigrate: true,
+	}
+	db := New(cfg, logger)
 	if db == nil {
 		_ = container.Terminate(context.Background())
 		logger.Fatal("failed to create database instance")
@@ -49,12 +54,17 @@ func SetupTestDatabase(logger *logger.AppLogger) *TestDatabase {
 	return &TestDatabase{
 		Db:        db,
 		container: container,
+		dsn:       connectionString(cfg),
 	}
 }
 
-// Migrate runs the database migrations
+// Migrate runs the database migrations and creates the transaction partition of the first testPartitionEpochs epochs
 func (tdb *TestDatabase) Migrate() error {
-	return tdb.migrateTables()
+	if err := tdb.migrateTables(); err != nil {
+		return err
+	}
+	_, err := tdb.EnsureTransactionPartitions(context.Background(), 0, testPartitionEpochs, testPartitionEpochs)
+	return err
 }
 
 // Drop drops all tables
@@ -7,14 +7,31 @@ import (
 )
 
 type TransactionQueryBuilder struct {
-	queryBuilder[types.Transaction]
+	queryBuilder[types.Transaction, *TransactionQueryBuilder]
 }
 
 // TransactionQuery returns a new transaction query builder.
-func (db *Db) TransactionQuery(ctx context.Context) TransactionQueryBuilder {
-	return TransactionQueryBuilder{
-		queryBuilder: newQueryBuilder[types.Transaction](ctx, db.con, "transaction"),
-	}
+func (db *Db) TransactionQuery(ctx context.Context) *TransactionQueryBuilder {
+	qb := &TransactionQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.Transaction](ctx, db.con, "transaction", qb)
+	return qb
+}
+
+// TransactionColumns represents the columns transactions can be ordered and aggregated by.
+var TransactionColumns = struct {
+	Id            Column[types.Transaction, int64]
+	Hash          Column[types.Transaction, types.Hash]
+	BlockNumber   Column[types.Transaction, uint64]
+	Epoch         Column[types.Transaction, uint64]
+	GasUsed       Column[types.Transaction, uint64]
+	RewardToClaim Column[types.Transaction, types.Big]
+}{
+	Id:            Column[types.Transaction, int64]{name: "id"},
+	Hash:          Column[types.Transaction, types.Hash]{name: "hash"},
+	BlockNumber:   Column[types.Transaction, uint64]{name: "block_number"},
+	Epoch:         Column[types.Transaction, uint64]{name: "epoch_number"},
+	GasUsed:       Column[types.Transaction, uint64]{name: "gas_used"},
+	RewardToClaim: Column[types.Transaction, types.Big]{name: "reward_to_claim"},
 }
 
 // WhereEpoch adds a where clause to the query builder.
@@ -38,15 +55,29 @@ func (qb *TransactionQueryBuilder) WhereProjectId(id int64) *TransactionQueryBui
 	return qb
 }
 
+// WhereContract adds a where clause to the query builder.
+func (qb *TransactionQueryBuilder) WhereContract(contract *types.Address) *TransactionQueryBuilder {
+	qb.where = append(qb.where, "contract_address = :contract_address")
+	qb.parameters["contract_address"] = contract
+	return qb
+}
+
+// WhereHash adds a where clause to the query builder.
+func (qb *TransactionQueryBuilder) WhereHash(hash *types.Hash) *TransactionQueryBuilder {
+	qb.where = append(qb.where, "hash = :hash")
+	qb.parameters["hash"] = hash
+	return qb
+}
+
 // StoreTransaction stores a transaction reference in connected persistent storage.
 func (db *Db) StoreTransaction(ctx context.Context, trx *types.Transaction) error {
-	query := `INSERT INTO transaction (project_id, hash, block_hash, block_number, epoch_number, timestamp, from_address, to_address, gas_used, gas_price, reward_to_claim) 
-		VALUES (:project_id, :hash, :block_hash, :block_number, :epoch_number, :timestamp, :from_address, :to_address, :gas_used, :gas_price, :reward_to_claim)`
+	query := `INSERT INTO transaction (project_id, contract_address, hash, block_hash, block_number, epoch_number, timestamp, from_address, to_address, trace_address, call_type, selector, gas_used, gas_price, effective_gas_price, base_fee, gas_price_source, reward_to_claim) 
+		VALUES (:project_id, :contract_address, :hash, :block_hash, :block_number, :epoch_number, :timestamp, :from_address, :to_address, :trace_address, :call_type, :selector, :gas_used, :gas_price, :effective_gas_price, :base_fee, :gas_price_source, :reward_to_claim)`
 
 	_, err := sqlx.NamedExecContext(ctx, db.con, query, trx)
 	if err != nil {
 		db.log.Errorf("failed to store transaction %s: %v", trx.Hash.String(), err)
-		return err
+		return mapError(err)
 	}
 
 	// add transaction to the db
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// transactionColumns are the stored columns of the transaction table in the order of transactionRow.
+var transactionColumns = []string{
+	"project_id", "contract_address", "hash", "block_hash", "block_number", "epoch_number", "timestamp",
+	"from_address", "to_address", "trace_address", "call_type", "selector", "gas_used", "gas_price",
+	"effective_gas_price", "base_fee", "gas_price_source", "reward_to_claim",
+}
+
+// TransactionBatch accumulates rewarded transactions and writes them all at once using COPY,
+// which is much faster than inserting them one by one when there are thousands of them.
+type TransactionBatch struct {
+	db   *Db
+	size int
+	rows []*types.Transaction
+}
+
+// NewTransactionBatch creates a new batch of transactions. The batch is written automatically
+// whenever it holds the given number of transactions, the rest is written by Flush.
+func (db *Db) NewTransactionBatch(size int) storage.TransactionBatch {
+	return &TransactionBatch{
+		db:   db,
+		size: size,
+		rows: make([]*types.Transaction, 0, size),
+	}
+}
+
+// Add adds the transaction to the batch. The transaction MUST NOT be modified until the batch is written.
+func (b *TransactionBatch) Add(ctx context.Context, trx *types.Transaction) error {
+	b.rows = append(b.rows, trx)
+	if len(b.rows) >= b.size {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+// Len returns the number of transactions waiting to be written.
+func (b *TransactionBatch) Len() int {
+	return len(b.rows)
+}
+
+// Flush writes all the transactions of the batch. The batch joins the database transaction
+// of its repository, a new database transaction is used otherwise.
+func (b *TransactionBatch) Flush(ctx context.Context) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+	if err := b.db.copyTransactions(ctx, b.rows); err != nil {
+		return err
+	}
+	b.db.log.Debugf("%d transactions added to database", len(b.rows))
+	b.rows = b.rows[:0]
+	return nil
+}
+
+// copyTransactions writes the transactions using COPY, which has to run in a database transaction.
+func (db *Db) copyTransactions(ctx context.Context, rows []*types.Transaction) error {
+	tx, ok := db.con.(*sqlx.Tx)
+	if !ok {
+		return db.DatabaseTransaction(ctx, func(ctx context.Context, db *Db) error {
+			return db.copyTransactions(ctx, rows)
+		})
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("transaction", transactionColumns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare transactions copy: %w", err)
+	}
+	defer func() {
+		// the statement is already closed if the copy succeeded
+		_ = stmt.Close()
+	}()
+
+	for _, trx := range rows {
+		if _, err := stmt.ExecContext(ctx, transactionRow(trx)...); err != nil {
+			db.log.Errorf("failed to copy transaction %s: %v", trx.Hash.String(), err)
+			return mapError(err)
+		}
+	}
+	// the empty exec sends the buffered rows and finishes the copy
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		db.log.Errorf("failed to copy %d transactions: %v", len(rows), err)
+		return mapError(err)
+	}
+	return stmt.Close()
+}
+
+// transactionRow returns the stored values of the transaction in the order of transactionColumns.
+func transactionRow(trx *types.Transaction) []interface{} {
+	return []interface{}{
+		trx.ProjectId, trx.ContractAddress, trx.Hash, trx.BlockHash, trx.BlockNumber, trx.Epoch, trx.Timestamp,
+		trx.From, trx.To, trx.TraceAddress, trx.CallType, trx.Selector, trx.GasUsed, trx.GasPrice,
+		trx.EffectiveGasPrice, trx.BaseFee, trx.GasPriceSource, trx.RewardToClaim,
+	}
+}
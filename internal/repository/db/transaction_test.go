@@ -2,29 +2,156 @@ package db
 
 import (
 	"context"
+	"errors"
 	"ftm-gas-monetization/internal/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"time"
 )
 
-func (s *DbTestSuite) TestStoreTransaction() {
+// storeTestProject stores a project of the given id the transactions can refer to
+func (s *DbTestSuite) storeTestProject(projectId uint64) *types.Project {
+	addr := types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")}
+	project := &types.Project{
+		ProjectId:       projectId,
+		ContractAddress: &addr,
+		OwnerAddress:    &addr,
+		ReceiverAddress: &addr,
+	}
+	assert.Nil(s.T(), s.db.StoreProject(context.Background(), project))
+	return project
+}
+
+// testTransaction returns a transaction of the given project
+func testTransaction(projectId int64) *types.Transaction {
 	blockNumber := hexutil.Uint64(57190053)
 	gasUsed := hexutil.Uint64(21000)
 	big, _ := hexutil.DecodeBig("0x75bcd15")
 	gasPrice := types.Big{Big: hexutil.Big(*big)}
-	err := s.db.StoreTransaction(context.Background(), &types.Transaction{
-		ProjectId:     1,
-		Hash:          &types.Hash{Hash: common.HexToHash("0x48b50bc6f9679c37a283b308ec4cdcf14a43d818fa43e6dcbe8d9c7d28331096")},
-		BlockHash:     &types.Hash{Hash: common.HexToHash("0x0002fcf20000016fb9f7ffabf8757b0d3f5f36e86bebbd09f1a03d8d4c4ae306")},
-		BlockNumber:   &blockNumber,
-		Timestamp:     time.Unix(1678285698, 0),
-		From:          &types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")},
-		To:            &types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")},
-		GasUsed:       &gasUsed,
-		GasPrice:      &gasPrice,
-		RewardToClaim: &types.Big{Big: hexutil.Big(*big)},
+	return &types.Transaction{
+		ProjectId:         projectId,
+		Hash:              &types.Hash{Hash: common.HexToHash("0x48b50bc6f9679c37a283b308ec4cdcf14a43d818fa43e6dcbe8d9c7d28331096")},
+		BlockHash:         &types.Hash{Hash: common.HexToHash("0x0002fcf20000016fb9f7ffabf8757b0d3f5f36e86bebbd09f1a03d8d4c4ae306")},
+		BlockNumber:       &blockNumber,
+		Timestamp:         time.Unix(1678285698, 0),
+		From:              &types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")},
+		To:                &types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")},
+		GasUsed:           &gasUsed,
+		GasPrice:          &gasPrice,
+		EffectiveGasPrice: &gasPrice,
+		GasPriceSource:    types.GasPriceSourceReceipt,
+		RewardToClaim:     &types.Big{Big: hexutil.Big(*big)},
+	}
+}
+
+func (s *DbTestSuite) TestStoreTransaction() {
+	project := s.storeTestProject(1)
+	err := s.db.StoreTransaction(context.Background(), testTransaction(project.Id))
+	assert.Nil(s.T(), err)
+}
+
+func (s *DbTestSuite) TestStoreTransactionOfUnknownProject() {
+	err := s.db.StoreTransaction(context.Background(), testTransaction(42))
+	assert.True(s.T(), errors.Is(err, ErrMissingReference))
+}
+
+func (s *DbTestSuite) TestStoreDuplicateProject() {
+	s.storeTestProject(1)
+	err := s.db.StoreProject(context.Background(), &types.Project{
+		ProjectId:       1,
+		ContractAddress: &types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")},
+		OwnerAddress:    &types.Address{},
+		ReceiverAddress: &types.Address{},
+	})
+	assert.True(s.T(), errors.Is(err, ErrDuplicate))
+}
+
+func (s *DbTestSuite) TestSumTransactionRewards() {
+	project := s.storeTestProject(1)
+	assert.Nil(s.T(), s.db.StoreTransaction(context.Background(), testTransaction(project.Id)))
+	assert.Nil(s.T(), s.db.StoreTransaction(context.Background(), testTransaction(project.Id)))
+	var total types.Big
+	err := sqlx.GetContext(context.Background(), s.db.con, &total, "SELECT SUM(reward_to_claim) FROM transaction WHERE project_id = $1", project.Id)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(2*0x75bcd15), total.ToInt().Int64())
+}
+
+func (s *DbTestSuite) TestAggregateTransactions() {
+	project := s.storeTestProject(1)
+	for i := 0; i < 3; i++ {
+		assert.Nil(s.T(), s.db.StoreTransaction(context.Background(), testTransaction(project.Id)))
+	}
+	tq := s.db.TransactionQuery(context.Background())
+	count, err := tq.WhereProjectId(project.Id).Count()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 3, count)
+
+	tq = s.db.TransactionQuery(context.Background())
+	hashes, err := tq.WhereProjectId(project.Id).CountDistinct(TransactionColumns.Hash)
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 1, hashes)
+
+	tq = s.db.TransactionQuery(context.Background())
+	sum, err := TransactionColumns.RewardToClaim.Sum(tq.WhereProjectId(project.Id))
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(3*0x75bcd15), sum.Int64())
+
+	tq = s.db.TransactionQuery(context.Background())
+	block, err := TransactionColumns.BlockNumber.Max(tq.WhereProjectId(project.Id))
+	assert.Nil(s.T(), err)
+	assert.NotNil(s.T(), block)
+
+	tq = s.db.TransactionQuery(context.Background())
+	none, err := TransactionColumns.BlockNumber.Max(tq.WhereProjectId(project.Id + 1))
+	assert.Nil(s.T(), err)
+	assert.Nil(s.T(), none)
+
+	tq = s.db.TransactionQuery(context.Background())
+	page, err := tq.WhereProjectId(project.Id).OrderBy(TransactionColumns.Id, Asc).After(Cursor{1}).Limit(1).GetAll()
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), page, 1)
+	assert.EqualValues(s.T(), 2, page[0].Id)
+}
+
+func (s *DbTestSuite) TestTransactionBatch() {
+	project := s.storeTestProject(1)
+
+	// the batch joins the database transaction and writes when it is full
+	err := s.db.DatabaseTransaction(context.Background(), func(ctx context.Context, db *Db) error {
+		batch := db.NewTransactionBatch(2)
+		for i := 0; i < 5; i++ {
+			if err := batch.Add(ctx, testTransaction(project.Id)); err != nil {
+				return err
+			}
+		}
+		assert.Equal(s.T(), 1, batch.Len())
+		return batch.Flush(ctx)
 	})
 	assert.Nil(s.T(), err)
+
+	// the batch runs its own database transaction otherwise
+	batch := s.db.NewTransactionBatch(10)
+	assert.Nil(s.T(), batch.Add(context.Background(), testTransaction(project.Id)))
+	assert.Nil(s.T(), batch.Flush(context.Background()))
+
+	tq := s.db.TransactionQuery(context.Background())
+	trxs, err := tq.WhereProjectId(project.Id).GetAll()
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), trxs, 6)
+	assert.Equal(s.T(), testTransaction(project.Id).Hash.Hash, trxs[0].Hash.Hash)
+	assert.Equal(s.T(), int64(0x75bcd15), trxs[0].RewardToClaim.ToInt().Int64())
+	assert.Equal(s.T(), types.GasPriceSourceReceipt, trxs[0].GasPriceSource)
+
+	// the whole batch is rejected if any of the transactions is
+	batch = s.db.NewTransactionBatch(10)
+	assert.Nil(s.T(), batch.Add(context.Background(), testTransaction(project.Id)))
+	assert.Nil(s.T(), batch.Add(context.Background(), testTransaction(42)))
+	err = batch.Flush(context.Background())
+	assert.True(s.T(), errors.Is(err, ErrMissingReference))
+	tq = s.db.TransactionQuery(context.Background())
+	count, err := tq.Count()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 6, count)
 }
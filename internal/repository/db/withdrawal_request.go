@@ -8,14 +8,25 @@ import (
 )
 
 type WithdrawalRequestQueryBuilder struct {
-	queryBuilder[types.WithdrawalRequest]
+	queryBuilder[types.WithdrawalRequest, *WithdrawalRequestQueryBuilder]
 }
 
 // WithdrawalRequestQuery returns a new withdrawal request query builder.
-func (db *Db) WithdrawalRequestQuery(ctx context.Context) WithdrawalRequestQueryBuilder {
-	return WithdrawalRequestQueryBuilder{
-		queryBuilder: newQueryBuilder[types.WithdrawalRequest](ctx, db.con, "withdrawal_request"),
-	}
+func (db *Db) WithdrawalRequestQuery(ctx context.Context) *WithdrawalRequestQueryBuilder {
+	qb := &WithdrawalRequestQueryBuilder{}
+	qb.queryBuilder = newQueryBuilder[types.WithdrawalRequest](ctx, db.con, "withdrawal_request", qb)
+	return qb
+}
+
+// WithdrawalRequestColumns represents the columns withdrawal requests can be ordered and aggregated by.
+var WithdrawalRequestColumns = struct {
+	Id           Column[types.WithdrawalRequest, int64]
+	RequestEpoch Column[types.WithdrawalRequest, uint64]
+	Amount       Column[types.WithdrawalRequest, types.Big]
+}{
+	Id:           Column[types.WithdrawalRequest, int64]{name: "id"},
+	RequestEpoch: Column[types.WithdrawalRequest, uint64]{name: "request_epoch"},
+	Amount:       Column[types.WithdrawalRequest, types.Big]{name: "amount"},
 }
 
 // WhereProjectId adds a where clause to the query builder.
@@ -25,6 +36,13 @@ func (qb *WithdrawalRequestQueryBuilder) WhereProjectId(projectId int64) *Withdr
 	return qb
 }
 
+// WhereContract adds a where clause to the query builder.
+func (qb *WithdrawalRequestQueryBuilder) WhereContract(contract *types.Address) *WithdrawalRequestQueryBuilder {
+	qb.where = append(qb.where, "contract_address = :contract_address")
+	qb.parameters["contract_address"] = contract
+	return qb
+}
+
 // WhereRequestEpoch adds a where clause to the query builder.
 func (qb *WithdrawalRequestQueryBuilder) WhereRequestEpoch(epoch uint64) *WithdrawalRequestQueryBuilder {
 	qb.where = append(qb.where, "request_epoch = :request_epoch")
@@ -39,14 +57,20 @@ func (qb *WithdrawalRequestQueryBuilder) WhereWithdrawEpoch(epoch uint64) *Withd
 	return qb
 }
 
+// WhereNotWithdrawn adds a where clause to the query builder.
+func (qb *WithdrawalRequestQueryBuilder) WhereNotWithdrawn() *WithdrawalRequestQueryBuilder {
+	qb.where = append(qb.where, "withdraw_epoch IS NULL")
+	return qb
+}
+
 // StoreWithdrawalRequest stores a new withdrawal request into the database.
 func (db *Db) StoreWithdrawalRequest(ctx context.Context, request *types.WithdrawalRequest) error {
-	query := `INSERT INTO withdrawal_request (project_id, request_epoch, withdraw_epoch, amount) 
-				VALUES (:project_id, :request_epoch, :withdraw_epoch, :amount)`
+	query := `INSERT INTO withdrawal_request (project_id, contract_address, request_epoch, withdraw_epoch, amount, recipient_address) 
+				VALUES (:project_id, :contract_address, :request_epoch, :withdraw_epoch, :amount, :recipient_address)`
 	_, err := sqlx.NamedExecContext(ctx, db.con, query, request)
 	if err != nil {
 		db.log.Errorf("failed to store withdrawal request %d: %v", request.Id, err)
-		return err
+		return mapError(err)
 	}
 	return nil
 }
@@ -56,12 +80,12 @@ func (db *Db) UpdateWithdrawalRequest(ctx context.Context, request *types.Withdr
 	if request.Id == 0 {
 		return fmt.Errorf("failed to update withdrawal. request id is 0")
 	}
-	query := `UPDATE withdrawal_request SET project_id = :project_id, request_epoch = :request_epoch,
-                              withdraw_epoch = :withdraw_epoch, amount = :amount WHERE id = :id`
+	query := `UPDATE withdrawal_request SET project_id = :project_id, contract_address = :contract_address, request_epoch = :request_epoch,
+                              withdraw_epoch = :withdraw_epoch, amount = :amount, recipient_address = :recipient_address WHERE id = :id`
 	_, err := sqlx.NamedExecContext(ctx, db.con, query, request)
 	if err != nil {
 		db.log.Errorf("failed to update withdrawal request %d: %v", request.Id, err)
-		return err
+		return mapError(err)
 	}
 	return nil
 }
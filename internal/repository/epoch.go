@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/types"
+	"time"
 )
 
 // CurrentEpoch returns the current epoch number.
@@ -17,3 +20,24 @@ func (repo *Repository) UpdateCurrentEpoch(id uint64) error {
 	defer cancel()
 	return repo.db.UpdateCurrentEpoch(ctx, id)
 }
+
+// EpochQuery returns a new epoch query builder.
+func (repo *Repository) EpochQuery() *db.EpochQueryBuilder {
+	return repo.db.EpochQuery(context.Background())
+}
+
+// Epoch returns the observed epoch of the given number, nil if not observed.
+func (repo *Repository) Epoch(number uint64) (*types.Epoch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	eq := repo.db.EpochQuery(ctx)
+	return eq.WhereNumber(number).GetFirst()
+}
+
+// EpochAt returns the observed epoch in progress at the given time, nil if not observed.
+func (repo *Repository) EpochAt(at time.Time) (*types.Epoch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	eq := repo.db.EpochQuery(ctx)
+	return eq.WhereAt(at).GetFirst()
+}
@@ -2,20 +2,42 @@ package repository
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	eth "github.com/ethereum/go-ethereum/core/types"
 	"math/big"
 )
 
-// CompleteWithdrawal completes withdrawal of the given amount from the given project.
-func (repo *Repository) CompleteWithdrawal(projectId uint64, epoch uint64, amount *big.Int) error {
-	return repo.rpc.CompleteWithdrawal(projectId, epoch, amount)
+// CompleteWithdrawal completes withdrawal of the given amount from the given project of the given contract.
+func (repo *Repository) CompleteWithdrawal(contract common.Address, projectId uint64, epoch uint64, amount *big.Int) error {
+	return repo.rpc.CompleteWithdrawal(contract, projectId, epoch, amount)
 }
 
-// HasPendingWithdrawal returns true if there is a pending withdrawal for the given project.
-func (repo *Repository) HasPendingWithdrawal(projectId uint64, epoch uint64) (bool, error) {
-	return repo.rpc.HasPendingWithdrawal(projectId, epoch)
+// HasPendingWithdrawal returns true if there is a pending withdrawal for the given project of the given contract.
+func (repo *Repository) HasPendingWithdrawal(contract common.Address, projectId uint64, epoch uint64) (bool, error) {
+	return repo.rpc.HasPendingWithdrawal(contract, projectId, epoch)
 }
 
-// GasMonetizationAddress returns the address of the gas monetization contract.
+// HasPendingWithdrawalAt returns true if there was a pending withdrawal for the given project of the given contract
+// at the given block.
+func (repo *Repository) HasPendingWithdrawalAt(contract common.Address, projectId uint64, epoch uint64, block uint64) (bool, error) {
+	return repo.rpc.HasPendingWithdrawalAt(contract, projectId, epoch, block)
+}
+
+// GasMonetizationLogs returns all logs emitted by the given gas monetization contract in the given block range.
+func (repo *Repository) GasMonetizationLogs(contract common.Address, from uint64, to uint64) ([]eth.Log, error) {
+	return repo.rpc.GasMonetizationLogs(contract, from, to)
+}
+
+// GasMonetizationAddress returns the address of the primary gas monetization contract.
 func (repo *Repository) GasMonetizationAddress() common.Address {
 	return repo.rpc.GasMonetizationAddress()
 }
+
+// GasMonetizationAddresses returns addresses of all indexed gas monetization contract deployments.
+func (repo *Repository) GasMonetizationAddresses() []common.Address {
+	return repo.rpc.GasMonetizationAddresses()
+}
+
+// GasMonetizationStartBlock returns the block number the given gas monetization contract is indexed from.
+func (repo *Repository) GasMonetizationStartBlock(contract common.Address) (uint64, error) {
+	return repo.rpc.GasMonetizationStartBlock(contract)
+}
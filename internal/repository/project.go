@@ -5,6 +5,7 @@ import (
 	"ftm-gas-monetization/internal/repository/db"
 	"ftm-gas-monetization/internal/types"
 	"math/big"
+	"time"
 )
 
 // StoreProject stores the project in the database.
@@ -21,11 +22,25 @@ func (repo *Repository) UpdateProject(project *types.Project) error {
 	return repo.db.UpdateProject(ctx, project)
 }
 
+// UpdateProjectMetadata updates the metadata and the metadata fetch state of the project.
+func (repo *Repository) UpdateProjectMetadata(project *types.Project) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	return repo.db.UpdateProjectMetadata(ctx, project)
+}
+
 // ProjectQuery returns a new project query builder.
-func (repo *Repository) ProjectQuery() db.ProjectQueryBuilder {
+func (repo *Repository) ProjectQuery() *db.ProjectQueryBuilder {
 	return repo.db.ProjectQuery(context.Background())
 }
 
+// RebuildStats recalculates the total amounts collected and claimed from the projects.
+func (repo *Repository) RebuildStats() (*types.Stats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	return repo.db.RebuildStats(ctx)
+}
+
 // TotalAmountCollected returns the total amount collected for all projects.
 func (repo *Repository) TotalAmountCollected() (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
@@ -88,3 +103,10 @@ func (repo *Repository) IncreaseTotalTransactionsCount(amount uint64) error {
 	defer cancel()
 	return repo.db.IncreaseTotalTransactionsCount(ctx, amount)
 }
+
+// ScheduleProjectMetadataRefresh schedules the metadata of active projects fetched before the given time to be fetched again.
+func (repo *Repository) ScheduleProjectMetadataRefresh(fetchedBefore time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	return repo.db.ScheduleProjectMetadataRefresh(ctx, fetchedBefore)
+}
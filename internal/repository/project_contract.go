@@ -6,6 +6,6 @@ import (
 )
 
 // ProjectContractQuery returns a new project contract query builder.
-func (repo *Repository) ProjectContractQuery() db.ProjectContractQueryBuilder {
+func (repo *Repository) ProjectContractQuery() *db.ProjectContractQueryBuilder {
 	return repo.db.ProjectContractQuery(context.Background())
 }
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/repository/db"
+)
+
+// ProjectHistoryQuery returns a new project history query builder.
+func (repo *Repository) ProjectHistoryQuery() *db.ProjectHistoryQueryBuilder {
+	return repo.db.ProjectHistoryQuery(context.Background())
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/types"
+)
+
+// ProjectMetadataVersionQuery returns a new project metadata version query builder.
+func (repo *Repository) ProjectMetadataVersionQuery() *db.ProjectMetadataVersionQueryBuilder {
+	return repo.db.ProjectMetadataVersionQuery(context.Background())
+}
+
+// StoreProjectMetadataVersion stores the metadata document of the project if it differs from the latest version.
+func (repo *Repository) StoreProjectMetadataVersion(version *types.ProjectMetadataVersion) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	return repo.db.StoreProjectMetadataVersion(ctx, version)
+}
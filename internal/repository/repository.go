@@ -6,6 +6,8 @@ import (
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/logger"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/db"
 	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/rpc"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/repository/storage"
+	"github.com/Mike-CZ/ftm-gas-monetization/internal/types"
 	"time"
 )
 
@@ -17,6 +19,10 @@ type Repository struct {
 	tracer tracing.TracerInterface
 	db     *db.Db
 	log    *logger.AppLogger
+	// dbCfg is the database configuration used to connect the read replicas on first use
+	dbCfg        *config.DB
+	replicas     *db.ReplicaSet
+	replicasOnce sync.Once
 }
 
 // config represents the configuration setup used by the repository
@@ -52,9 +58,10 @@ func New(cfg *config.Config, log *logger.AppLogger) *Repository {
 	repoLogger := log.ModuleLogger("repository")
 	repo := Repository{
 		db:     db.New(&cfg.DB, repoLogger),
-		rpc:    rpc.New(&cfg.Rpc, &cfg.GasMonetization, repoLogger),
+		rpc:    rpc.New(&cfg.Rpc, cfg.GasMonetization, repoLogger),
 		tracer: tracing.New(&cfg.Rpc, repoLogger),
 		log:    repoLogger,
+		dbCfg:  &cfg.DB,
 	}
 
 	if repo.rpc == nil || repo.db == nil {
@@ -62,6 +69,14 @@ func New(cfg *config.Config, log *logger.AppLogger) *Repository {
 		return nil
 	}
 
+	// data stored before contracts were scoped belong to the primary contract
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	if err := repo.db.AssignUnscopedRows(ctx, &types.Address{Address: repo.rpc.GasMonetizationAddress()}); err != nil {
+		repoLogger.Panicf("failed to assign unscoped data to the primary contract; %s", err.Error())
+		return nil
+	}
+
 	return &repo
 }
 
@@ -88,12 +103,43 @@ func NewWithInstances(db *db.Db, rpc *rpc.Rpc, tracer tracing.TracerInterface, l
 	return &repo
 }
 
-// DatabaseTransaction runs the given function in a database transaction. The callback function is passed the repository
-// instance with the transaction as the connection. The transaction is automatically committed if the callback function
-// returns nil, otherwise it is rolled back. The callback function is passed a context that is cancelled after
-// dbQueryTimeoutDuration. If the callback function does not return within this time, the transaction is rolled back.
-func (repo *Repository) DatabaseTransaction(fn func(context.Context, *db.Db) error) error {
-	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
-	defer cancel()
-	return repo.db.DatabaseTransaction(ctx, fn)
+// Replica returns a view of the repository running database reads against a healthy read replica,
+// or the repository itself if there is none. The view MUST NOT be used to write.
+func (repo *Repository) Replica() *Repository {
+	repo.replicasOnce.Do(func() {
+		if repo.dbCfg != nil && len(repo.dbCfg.Replicas) > 0 {
+			repo.replicas = db.NewReplicaSet(repo.dbCfg, repo.db, repo.log)
+		}
+	})
+	if repo.replicas == nil {
+		return repo
+	}
+
+	reader := repo.replicas.Reader()
+	if reader == repo.db {
+		return repo
+	}
+	return &Repository{
+		rpc:    repo.rpc,
+		tracer: repo.tracer,
+		db:     reader,
+		log:    repo.log,
+	}
+}
+
+// Close stops the health checks of the read replicas and closes the database connections.
+func (repo *Repository) Close() {
+	// make sure the replicas are not connected after they are closed
+	repo.replicasOnce.Do(func() {})
+	if repo.replicas != nil {
+		repo.replicas.Close()
+	}
+	if err := repo.db.Close(); err != nil {
+		repo.log.Errorf("failed to close database connection; %s", err.Error())
+	}
+}
+
+// Storage returns the persistent storage of the repository.
+func (repo *Repository) Storage() storage.Storage {
+	return repo.db
 }
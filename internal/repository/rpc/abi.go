@@ -2,9 +2,17 @@
 // node through RPC interface.
 package rpc
 
-import "github.com/ethereum/go-ethereum/accounts/abi"
+import (
+	"ftm-gas-monetization/internal/repository/rpc/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
 
 // GasMonetizationAbi provides access to decoded ABI of Fantom Gas Monetization contract.
 func (rpc *Rpc) GasMonetizationAbi() *abi.ABI {
 	return rpc.abiGasMonetization
 }
+
+// GasMonetizationEvents provides access to typed event decoders of Fantom Gas Monetization contract.
+func (rpc *Rpc) GasMonetizationEvents() *contracts.GasMonetizationFilterer {
+	return rpc.gasMonetizationEvents
+}
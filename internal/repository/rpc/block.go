@@ -14,8 +14,15 @@ const (
 )
 
 // StartFromBlock returns the block number from which the processing should start.
+// It is the lowest start block of all the configured gas monetization contracts.
 func (rpc *Rpc) StartFromBlock() uint64 {
-	return rpc.startFromBlock
+	start := rpc.gasMonetization[0].startFromBlock
+	for _, gm := range rpc.gasMonetization[1:] {
+		if gm.startFromBlock < start {
+			start = gm.startFromBlock
+		}
+	}
+	return start
 }
 
 // BlockHeight returns the current block height of the Opera blockchain.
@@ -1,29 +1,94 @@
 package rpc
 
 import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"math/big"
 )
 
-// CompleteWithdrawal completes withdrawal of the given amount from the given project.
-func (rpc *Rpc) CompleteWithdrawal(projectId uint64, epoch uint64, amount *big.Int) error {
-	_, err := rpc.dataProviderSession.CompleteWithdrawal(
+// CompleteWithdrawal completes withdrawal of the given amount from the given project of the given contract.
+func (rpc *Rpc) CompleteWithdrawal(contract common.Address, projectId uint64, epoch uint64, amount *big.Int) error {
+	gm, err := rpc.deployment(contract)
+	if err != nil {
+		return err
+	}
+	_, err = gm.dataProviderSession.CompleteWithdrawal(
 		new(big.Int).SetUint64(projectId), new(big.Int).SetUint64(epoch), amount)
 	return err
 }
 
-// HasPendingWithdrawal returns true if there is a pending withdrawal for the given project.
-func (rpc *Rpc) HasPendingWithdrawal(projectId uint64, epoch uint64) (bool, error) {
-	return rpc.dataProviderSession.HasPendingWithdrawal(new(big.Int).SetUint64(projectId), new(big.Int).SetUint64(epoch))
+// HasPendingWithdrawal returns true if there is a pending withdrawal for the given project of the given contract.
+func (rpc *Rpc) HasPendingWithdrawal(contract common.Address, projectId uint64, epoch uint64) (bool, error) {
+	gm, err := rpc.deployment(contract)
+	if err != nil {
+		return false, err
+	}
+	return gm.dataProviderSession.HasPendingWithdrawal(new(big.Int).SetUint64(projectId), new(big.Int).SetUint64(epoch))
 }
 
-// GasMonetizationAddress returns the address of the gas monetization contract.
+// HasPendingWithdrawalAt returns true if there was a pending withdrawal for the given project of the given contract
+// at the given block.
+func (rpc *Rpc) HasPendingWithdrawalAt(contract common.Address, projectId uint64, epoch uint64, block uint64) (bool, error) {
+	gm, err := rpc.deployment(contract)
+	if err != nil {
+		return false, err
+	}
+	return gm.dataProviderSession.Contract.HasPendingWithdrawal(
+		&bind.CallOpts{BlockNumber: new(big.Int).SetUint64(block)},
+		new(big.Int).SetUint64(projectId), new(big.Int).SetUint64(epoch))
+}
+
+// GasMonetizationLogs returns all logs emitted by the given gas monetization contract in the given block range.
+func (rpc *Rpc) GasMonetizationLogs(contract common.Address, from uint64, to uint64) ([]eth.Log, error) {
+	rpc.log.Debugf("loading logs of %s in blocks <#%d, #%d>", contract.Hex(), from, to)
+	return ethclient.NewClient(rpc.ftm).FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{contract},
+	})
+}
+
+// GasMonetizationAddress returns the address of the primary gas monetization contract,
+// which is the first one configured.
 func (rpc *Rpc) GasMonetizationAddress() common.Address {
-	return rpc.gasMonetizationAddress
+	return rpc.gasMonetization[0].address
+}
+
+// GasMonetizationAddresses returns addresses of all indexed gas monetization contract deployments.
+func (rpc *Rpc) GasMonetizationAddresses() []common.Address {
+	list := make([]common.Address, len(rpc.gasMonetization))
+	for i, gm := range rpc.gasMonetization {
+		list[i] = gm.address
+	}
+	return list
+}
+
+// GasMonetizationStartBlock returns the block number the given gas monetization contract is indexed from.
+func (rpc *Rpc) GasMonetizationStartBlock(contract common.Address) (uint64, error) {
+	gm, err := rpc.deployment(contract)
+	if err != nil {
+		return 0, err
+	}
+	return gm.startFromBlock, nil
 }
 
-// SetGasMonetizationAddress sets the address of the gas monetization contract.
+// SetGasMonetizationAddress sets the address of the primary gas monetization contract.
 // This is used for testing purposes only.
 func (rpc *Rpc) SetGasMonetizationAddress(addr common.Address) {
-	rpc.gasMonetizationAddress = addr
+	rpc.gasMonetization[0].address = addr
+}
+
+// deployment returns the gas monetization deployment of the given address.
+func (rpc *Rpc) deployment(contract common.Address) (*gasMonetization, error) {
+	for _, gm := range rpc.gasMonetization {
+		if gm.address == contract {
+			return gm, nil
+		}
+	}
+	return nil, fmt.Errorf("gas monetization contract %s is not configured", contract.Hex())
 }
@@ -25,16 +25,27 @@ type Rpc struct {
 	ftm *client.Client
 	log *logger.AppLogger
 
-	startFromBlock         uint64
-	gasMonetizationAddress common.Address
-	abiGasMonetization     *abi.ABI
-	dataProviderSession    *contracts.GasMonetizationSession
+	gasMonetization       []*gasMonetization
+	abiGasMonetization    *abi.ABI
+	gasMonetizationEvents *contracts.GasMonetizationFilterer
+}
+
+// gasMonetization represents a single indexed deployment of the gas monetization contract.
+type gasMonetization struct {
+	address             common.Address
+	startFromBlock      uint64
+	dataProviderSession *contracts.GasMonetizationSession
 }
 
 // New creates a new instance of the RPC client.
-func New(rpcCfg *config.Rpc, gmCfg *config.GasMonetization, log *logger.AppLogger) *Rpc {
+func New(rpcCfg *config.Rpc, gmCfg []config.GasMonetization, log *logger.AppLogger) *Rpc {
 	rpcLogger := log.ModuleLogger("rpc")
 
+	if len(gmCfg) == 0 {
+		rpcLogger.Critical("no gas monetization contract configured")
+		return nil
+	}
+
 	c, err := connect(rpcCfg.OperaRpcUrl)
 	if err != nil {
 		rpcLogger.Criticalf("can not connect to the Opera node; %s", err.Error())
@@ -42,10 +53,9 @@ func New(rpcCfg *config.Rpc, gmCfg *config.GasMonetization, log *logger.AppLogge
 	}
 
 	rpc := &Rpc{
-		ftm:                    c,
-		log:                    rpcLogger,
-		gasMonetizationAddress: common.HexToAddress(gmCfg.ContractAddress),
-		startFromBlock:         gmCfg.StartFromBlock,
+		ftm:             c,
+		log:             rpcLogger,
+		gasMonetization: make([]*gasMonetization, 0, len(gmCfg)),
 	}
 
 	// load and parse ABIs
@@ -54,19 +64,29 @@ func New(rpcCfg *config.Rpc, gmCfg *config.GasMonetization, log *logger.AppLogge
 		return nil
 	}
 
-	// initialize data provider session
-	if err = loadDataProviderSession(rpc, gmCfg); err != nil {
-		rpcLogger.Criticalf("can not initialize data provider session; %s", err.Error())
+	// initialize typed decoder of the contract events
+	if err = loadGasMonetizationEvents(rpc); err != nil {
+		rpcLogger.Criticalf("can not initialize gas monetization events decoder; %s", err.Error())
 		return nil
 	}
 
+	// initialize deployments, each with its own data provider session
+	for i := range gmCfg {
+		gm, err := loadGasMonetization(rpc, &gmCfg[i])
+		if err != nil {
+			rpcLogger.Criticalf("can not initialize data provider session of %s; %s", gmCfg[i].ContractAddress, err.Error())
+			return nil
+		}
+		rpc.gasMonetization = append(rpc.gasMonetization, gm)
+	}
+
 	return rpc
 }
 
-// SetDataProviderSession sets the data provider session.
+// SetDataProviderSession sets the data provider session of the primary deployment.
 // This is intended to be used only for testing purposes.
 func (rpc *Rpc) SetDataProviderSession(session *contracts.GasMonetizationSession) {
-	rpc.dataProviderSession = session
+	rpc.gasMonetization[0].dataProviderSession = session
 }
 
 // connect opens RPC connection to the Opera node.
@@ -101,26 +121,36 @@ func loadABIFile(path string) (*abi.ABI, error) {
 	return &decoded, nil
 }
 
-// initializeDataProviderSession initializes the data provider session.
-func loadDataProviderSession(rpc *Rpc, cfg *config.GasMonetization) error {
+// loadGasMonetizationEvents initializes the binding used to decode gas monetization contract events.
+// The binding is not tied to any deployment, it is used to decode logs of all of them.
+func loadGasMonetizationEvents(rpc *Rpc) (err error) {
+	rpc.gasMonetizationEvents, err = contracts.NewGasMonetizationFilterer(common.Address{}, ethclient.NewClient(rpc.ftm))
+	return err
+}
+
+// loadGasMonetization initializes the gas monetization deployment along with its data provider session.
+func loadGasMonetization(rpc *Rpc, cfg *config.GasMonetization) (*gasMonetization, error) {
 	key, err := crypto.HexToECDSA(cfg.DataProviderPK)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// create gas monetization instance
 	ethClient := ethclient.NewClient(rpc.ftm)
 	gm, err := contracts.NewGasMonetization(common.HexToAddress(cfg.ContractAddress), ethClient)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// get chain id
 	chainId, err := ethClient.ChainID(context.Background())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// create data provider session
 	auth, err := bind.NewKeyedTransactorWithChainID(key, chainId)
-	rpc.dataProviderSession = &contracts.GasMonetizationSession{
+	if err != nil {
+		return nil, err
+	}
+	session := &contracts.GasMonetizationSession{
 		Contract: gm,
 		CallOpts: bind.CallOpts{},
 		TransactOpts: bind.TransactOpts{
@@ -129,5 +159,9 @@ func loadDataProviderSession(rpc *Rpc, cfg *config.GasMonetization) error {
 			GasLimit: 0,
 		},
 	}
-	return nil
+	return &gasMonetization{
+		address:             common.HexToAddress(cfg.ContractAddress),
+		startFromBlock:      cfg.StartFromBlock,
+		dataProviderSession: session,
+	}, nil
 }
@@ -24,8 +24,9 @@ func (rpc *Rpc) Transaction(hash *common.Hash) (*types.Transaction, error) {
 	if trx.BlockNumber != nil {
 		// get transaction receipt
 		var rec struct {
-			GasUsed hexutil.Uint64 `json:"gasUsed"`
-			Logs    []eth.Log      `json:"logs"`
+			GasUsed           hexutil.Uint64 `json:"gasUsed"`
+			EffectiveGasPrice *types.Big     `json:"effectiveGasPrice"`
+			Logs              []eth.Log      `json:"logs"`
 		}
 
 		// call for the transaction receipt data
@@ -37,6 +38,7 @@ func (rpc *Rpc) Transaction(hash *common.Hash) (*types.Transaction, error) {
 
 		// copy some data
 		trx.GasUsed = &rec.GasUsed
+		trx.EffectiveGasPrice = rec.EffectiveGasPrice
 		trx.Logs = rec.Logs
 	}
 
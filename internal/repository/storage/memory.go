@@ -0,0 +1,955 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory is an in-memory storage with the semantics of the database storage, including
+// its unique and foreign key constraints. It is intended for tests and local runs.
+type Memory struct {
+	mu    sync.Mutex
+	store memoryStore
+}
+
+// NewMemory creates a new empty in-memory storage.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// memoryStore holds the data of the in-memory storage. It is not synchronized, the Memory is.
+type memoryStore struct {
+	projects     []types.Project
+	periods      []types.ProjectActivityPeriod
+	versions     []types.ProjectMetadataVersion
+	history      []types.ProjectHistory
+	contracts    []types.ProjectContract
+	transactions []types.Transaction
+	withdrawals  []types.WithdrawalRequest
+	events       []types.ContractEvent
+	epochs       []types.Epoch
+	lastBlock    uint64
+	currentEpoch uint64
+	checkpoint   uint64
+	stats        types.Stats
+	// sequence is the last id assigned to a record of any kind
+	sequence int64
+}
+
+// FindProjects returns projects matching the filter ordered by their id.
+func (m *Memory) FindProjects(ctx context.Context, filter ProjectFilter) ([]types.Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindProjects(ctx, filter)
+}
+
+// StoreProject stores a new project and sets its id.
+func (m *Memory) StoreProject(ctx context.Context, project *types.Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreProject(ctx, project)
+}
+
+// UpdateProject updates the registry attributes and the rewards of the project.
+func (m *Memory) UpdateProject(ctx context.Context, project *types.Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.UpdateProject(ctx, project)
+}
+
+// FindProjectActivityPeriods returns the periods the project was active in ordered by their first epoch.
+func (m *Memory) FindProjectActivityPeriods(ctx context.Context, projectId int64) ([]types.ProjectActivityPeriod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindProjectActivityPeriods(ctx, projectId)
+}
+
+// ScheduleProjectMetadata schedules the metadata of the project to be fetched as soon as possible.
+func (m *Memory) ScheduleProjectMetadata(ctx context.Context, project *types.Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.ScheduleProjectMetadata(ctx, project)
+}
+
+// UpdateProjectMetadata updates the metadata and the metadata fetch state of the project.
+func (m *Memory) UpdateProjectMetadata(ctx context.Context, project *types.Project) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.UpdateProjectMetadata(ctx, project)
+}
+
+// FindProjectMetadataVersions returns the distinct metadata documents of the project ordered by their id.
+func (m *Memory) FindProjectMetadataVersions(ctx context.Context, projectId int64) ([]types.ProjectMetadataVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindProjectMetadataVersions(ctx, projectId)
+}
+
+// StoreProjectMetadataVersion stores the metadata document of the project unless it equals the latest version.
+func (m *Memory) StoreProjectMetadataVersion(ctx context.Context, version *types.ProjectMetadataVersion) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreProjectMetadataVersion(ctx, version)
+}
+
+// FindProjectHistory returns changes of the project attributes matching the filter ordered by their id.
+func (m *Memory) FindProjectHistory(ctx context.Context, filter ProjectHistoryFilter) ([]types.ProjectHistory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindProjectHistory(ctx, filter)
+}
+
+// StoreProjectHistory stores a change of the project attribute.
+func (m *Memory) StoreProjectHistory(ctx context.Context, history *types.ProjectHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreProjectHistory(ctx, history)
+}
+
+// ProjectAttributeAt returns the last change of the project attribute made up to the given epoch.
+func (m *Memory) ProjectAttributeAt(ctx context.Context, projectId int64, attribute string, epoch uint64) (*types.ProjectHistory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.ProjectAttributeAt(ctx, projectId, attribute, epoch)
+}
+
+// FindProjectContracts returns project contracts matching the filter ordered by their id.
+func (m *Memory) FindProjectContracts(ctx context.Context, filter ProjectContractFilter) ([]types.ProjectContract, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindProjectContracts(ctx, filter)
+}
+
+// StoreProjectContract stores a new membership of the contract in the project.
+func (m *Memory) StoreProjectContract(ctx context.Context, contract *types.ProjectContract) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreProjectContract(ctx, contract)
+}
+
+// RemoveProjectContract closes the membership of the contract in the project at the given epoch and block.
+func (m *Memory) RemoveProjectContract(ctx context.Context, contract *types.ProjectContract, epoch uint64, block uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.RemoveProjectContract(ctx, contract, epoch, block)
+}
+
+// FindTransactions returns rewarded transactions matching the filter ordered by their id.
+func (m *Memory) FindTransactions(ctx context.Context, filter TransactionFilter) ([]types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindTransactions(ctx, filter)
+}
+
+// StoreTransaction stores a rewarded call frame of a transaction.
+func (m *Memory) StoreTransaction(ctx context.Context, trx *types.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreTransaction(ctx, trx)
+}
+
+// NewTransactionBatch creates a new batch of rewarded call frames written at once.
+func (m *Memory) NewTransactionBatch(size int) TransactionBatch {
+	return newMemoryBatch(m, size)
+}
+
+// DeleteTransactions deletes rewarded transactions matching the filter.
+func (m *Memory) DeleteTransactions(ctx context.Context, filter TransactionFilter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.DeleteTransactions(ctx, filter)
+}
+
+// FindWithdrawalRequests returns withdrawal requests matching the filter ordered by their id.
+func (m *Memory) FindWithdrawalRequests(ctx context.Context, filter WithdrawalRequestFilter) ([]types.WithdrawalRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindWithdrawalRequests(ctx, filter)
+}
+
+// StoreWithdrawalRequest stores a new withdrawal request.
+func (m *Memory) StoreWithdrawalRequest(ctx context.Context, request *types.WithdrawalRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreWithdrawalRequest(ctx, request)
+}
+
+// UpdateWithdrawalRequest updates the withdrawal request.
+func (m *Memory) UpdateWithdrawalRequest(ctx context.Context, request *types.WithdrawalRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.UpdateWithdrawalRequest(ctx, request)
+}
+
+// FindContractEvents returns contract events matching the filter ordered by their block and log index.
+func (m *Memory) FindContractEvents(ctx context.Context, filter ContractEventFilter) ([]types.ContractEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.FindContractEvents(ctx, filter)
+}
+
+// StoreContractEvent stores the contract event, replacing the event already stored for the same log.
+func (m *Memory) StoreContractEvent(ctx context.Context, event *types.ContractEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.StoreContractEvent(ctx, event)
+}
+
+// OpenEpoch records the start of the epoch at the given block.
+func (m *Memory) OpenEpoch(ctx context.Context, number uint64, firstBlock uint64, start time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.OpenEpoch(ctx, number, firstBlock, start)
+}
+
+// SealEpoch records the end of the epoch and marks it sealed.
+func (m *Memory) SealEpoch(ctx context.Context, number uint64, lastBlock uint64, end time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.SealEpoch(ctx, number, lastBlock, end)
+}
+
+// LastProcessedBlock returns the last processed block.
+func (m *Memory) LastProcessedBlock(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.LastProcessedBlock(ctx)
+}
+
+// UpdateLastProcessedBlock updates the last processed block.
+func (m *Memory) UpdateLastProcessedBlock(ctx context.Context, block uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.UpdateLastProcessedBlock(ctx, block)
+}
+
+// CurrentEpoch returns the current epoch.
+func (m *Memory) CurrentEpoch(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.CurrentEpoch(ctx)
+}
+
+// UpdateCurrentEpoch updates the current epoch.
+func (m *Memory) UpdateCurrentEpoch(ctx context.Context, epoch uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.UpdateCurrentEpoch(ctx, epoch)
+}
+
+// BootstrapCheckpoint returns the block the interrupted bootstrap continues from.
+func (m *Memory) BootstrapCheckpoint(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.BootstrapCheckpoint(ctx)
+}
+
+// UpdateBootstrapCheckpoint updates the block the bootstrap continues from.
+func (m *Memory) UpdateBootstrapCheckpoint(ctx context.Context, block uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.UpdateBootstrapCheckpoint(ctx, block)
+}
+
+// Stats returns the global totals.
+func (m *Memory) Stats(ctx context.Context) (*types.Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.Stats(ctx)
+}
+
+// IncreaseTotalAmountCollected increases the total amount collected.
+func (m *Memory) IncreaseTotalAmountCollected(ctx context.Context, amount *big.Int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.IncreaseTotalAmountCollected(ctx, amount)
+}
+
+// IncreaseTotalAmountClaimed increases the total amount claimed.
+func (m *Memory) IncreaseTotalAmountClaimed(ctx context.Context, amount *big.Int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.IncreaseTotalAmountClaimed(ctx, amount)
+}
+
+// IncreaseTotalTransactionsCount increases the total number of transactions.
+func (m *Memory) IncreaseTotalTransactionsCount(ctx context.Context, count uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store.IncreaseTotalTransactionsCount(ctx, count)
+}
+
+// Atomic runs the given function over the storage locked for the whole run. The data are restored
+// from a snapshot if the function fails.
+func (m *Memory) Atomic(ctx context.Context, fn func(context.Context, Storage) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := m.store.snapshot()
+	if err := fn(ctx, &m.store); err != nil {
+		m.store = snapshot
+		return fmt.Errorf("failed to run the storage transaction: %w", err)
+	}
+	return nil
+}
+
+// FindProjects returns projects matching the filter ordered by their id.
+func (ms *memoryStore) FindProjects(_ context.Context, filter ProjectFilter) ([]types.Project, error) {
+	var out []types.Project
+	for _, p := range ms.projects {
+		if (filter.Id == nil || p.Id == *filter.Id) &&
+			(filter.Contract == nil || sameAddress(p.ContractAddress, filter.Contract)) &&
+			(filter.ProjectId == nil || p.ProjectId == *filter.ProjectId) &&
+			(filter.Owner == nil || sameAddress(p.OwnerAddress, filter.Owner)) &&
+			(filter.ActiveInEpoch == nil || ms.isActiveIn(p.Id, *filter.ActiveInEpoch)) &&
+			(filter.Category == nil || p.Metadata != nil && p.Metadata.Category == *filter.Category) &&
+			(filter.Tag == nil || p.Metadata != nil && hasTag(p.Metadata.Tags, *filter.Tag)) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// StoreProject stores a new project and sets its id.
+func (ms *memoryStore) StoreProject(_ context.Context, project *types.Project) error {
+	if err := ms.checkUniqueProject(project); err != nil {
+		return err
+	}
+	ms.sequence++
+	project.Id = ms.sequence
+	// only the registry attributes are stored, the metadata fetch state starts over
+	p := *project
+	p.MetadataStatus = types.ProjectMetadataPending
+	p.MetadataError = nil
+	p.MetadataAttempts = 0
+	p.MetadataNextFetch = time.Now()
+	p.MetadataFetchedAt = nil
+	p.Metadata = nil
+	p.MetadataValidationErrors = nil
+	ms.projects = append(ms.projects, p)
+	ms.storePeriod(project)
+	return nil
+}
+
+// UpdateProject updates the registry attributes and the rewards of the project.
+func (ms *memoryStore) UpdateProject(_ context.Context, project *types.Project) error {
+	if project.Id == 0 {
+		return fmt.Errorf("failed to update project %d: project id is 0", project.ProjectId)
+	}
+	if err := ms.checkUniqueProject(project); err != nil {
+		return err
+	}
+	for i := range ms.projects {
+		p := &ms.projects[i]
+		if p.Id != project.Id {
+			continue
+		}
+		p.ContractAddress = project.ContractAddress
+		p.OwnerAddress = project.OwnerAddress
+		p.ReceiverAddress = project.ReceiverAddress
+		p.Url = project.Url
+		p.LastWithdrawalEpoch = project.LastWithdrawalEpoch
+		p.CollectedRewards = project.CollectedRewards
+		p.ClaimedRewards = project.ClaimedRewards
+		p.RewardsToClaim = project.RewardsToClaim
+		p.TransactionsCount = project.TransactionsCount
+		p.ActiveFromEpoch = project.ActiveFromEpoch
+		p.ActiveToEpoch = project.ActiveToEpoch
+		ms.syncPeriod(project)
+	}
+	return nil
+}
+
+// syncPeriod records the active window of the project into its activity periods
+// the same way the database storage does.
+func (ms *memoryStore) syncPeriod(project *types.Project) {
+	var latest *types.ProjectActivityPeriod
+	for i := range ms.periods {
+		ap := &ms.periods[i]
+		if ap.ProjectId == project.Id && (latest == nil || ap.FromEpoch >= latest.FromEpoch) {
+			latest = ap
+		}
+	}
+	if latest != nil && latest.FromEpoch == project.ActiveFromEpoch {
+		latest.ToEpoch = copyEpoch(project.ActiveToEpoch)
+		return
+	}
+	if latest != nil && latest.ToEpoch == nil {
+		latest.ToEpoch = copyEpoch(&project.ActiveFromEpoch)
+	}
+	ms.storePeriod(project)
+}
+
+// storePeriod stores the active window of the project as a new activity period.
+func (ms *memoryStore) storePeriod(project *types.Project) {
+	ms.sequence++
+	ms.periods = append(ms.periods, types.ProjectActivityPeriod{
+		Id:        ms.sequence,
+		ProjectId: project.Id,
+		FromEpoch: project.ActiveFromEpoch,
+		ToEpoch:   copyEpoch(project.ActiveToEpoch),
+	})
+}
+
+// isActiveIn checks any activity period of the project covers the epoch.
+func (ms *memoryStore) isActiveIn(projectId int64, epoch uint64) bool {
+	for i := range ms.periods {
+		if ms.periods[i].ProjectId == projectId && ms.periods[i].IsActiveIn(epoch) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyEpoch returns a copy of the optional epoch, so the stored period does not share it with the caller.
+func copyEpoch(epoch *uint64) *uint64 {
+	if epoch == nil {
+		return nil
+	}
+	e := *epoch
+	return &e
+}
+
+// checkUniqueProject checks no other project is registered with the same id in the same contract.
+func (ms *memoryStore) checkUniqueProject(project *types.Project) error {
+	if project.ContractAddress == nil {
+		return nil
+	}
+	for _, p := range ms.projects {
+		if p.Id != project.Id && p.ProjectId == project.ProjectId && sameAddress(p.ContractAddress, project.ContractAddress) {
+			return fmt.Errorf("project #%d of %s: %w", project.ProjectId, project.ContractAddress.Hex(), ErrDuplicate)
+		}
+	}
+	return nil
+}
+
+// FindProjectActivityPeriods returns the periods the project was active in ordered by their first epoch.
+func (ms *memoryStore) FindProjectActivityPeriods(_ context.Context, projectId int64) ([]types.ProjectActivityPeriod, error) {
+	var out []types.ProjectActivityPeriod
+	for _, ap := range ms.periods {
+		if ap.ProjectId == projectId {
+			out = append(out, ap)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].FromEpoch < out[j].FromEpoch
+	})
+	return out, nil
+}
+
+// ScheduleProjectMetadata schedules the metadata of the project to be fetched as soon as possible.
+func (ms *memoryStore) ScheduleProjectMetadata(_ context.Context, project *types.Project) error {
+	project.MetadataStatus = types.ProjectMetadataPending
+	project.MetadataError = nil
+	project.MetadataAttempts = 0
+	project.MetadataNextFetch = time.Now()
+	if p := ms.project(project.Id); p != nil {
+		p.MetadataStatus = project.MetadataStatus
+		p.MetadataError = nil
+		p.MetadataAttempts = 0
+		p.MetadataNextFetch = project.MetadataNextFetch
+	}
+	return nil
+}
+
+// UpdateProjectMetadata updates the metadata and the metadata fetch state of the project, unless
+// the metadata URI of the project changed meanwhile.
+func (ms *memoryStore) UpdateProjectMetadata(_ context.Context, project *types.Project) (bool, error) {
+	p := ms.project(project.Id)
+	if p == nil || p.Url != project.Url {
+		return false, nil
+	}
+	p.Name = project.Name
+	p.ImageUrl = project.ImageUrl
+	p.Metadata = project.Metadata
+	p.MetadataValidationErrors = project.MetadataValidationErrors
+	p.MetadataStatus = project.MetadataStatus
+	p.MetadataError = project.MetadataError
+	p.MetadataAttempts = project.MetadataAttempts
+	p.MetadataNextFetch = project.MetadataNextFetch
+	p.MetadataFetchedAt = project.MetadataFetchedAt
+	return true, nil
+}
+
+// FindProjectMetadataVersions returns the distinct metadata documents of the project ordered by their id.
+func (ms *memoryStore) FindProjectMetadataVersions(_ context.Context, projectId int64) ([]types.ProjectMetadataVersion, error) {
+	var out []types.ProjectMetadataVersion
+	for _, v := range ms.versions {
+		if v.ProjectId == projectId {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// StoreProjectMetadataVersion stores the metadata document of the project unless it equals the latest version.
+func (ms *memoryStore) StoreProjectMetadataVersion(_ context.Context, version *types.ProjectMetadataVersion) (bool, error) {
+	if !ms.projectExists(version.ProjectId) {
+		return false, fmt.Errorf("metadata version of project %d: %w", version.ProjectId, ErrMissingReference)
+	}
+	for i := len(ms.versions) - 1; i >= 0; i-- {
+		if ms.versions[i].ProjectId != version.ProjectId {
+			continue
+		}
+		if sameHash(ms.versions[i].ContentHash, version.ContentHash) {
+			return false, nil
+		}
+		break
+	}
+	ms.sequence++
+	v := *version
+	v.Id = ms.sequence
+	ms.versions = append(ms.versions, v)
+	return true, nil
+}
+
+// FindProjectHistory returns changes of the project attributes matching the filter ordered by their id.
+func (ms *memoryStore) FindProjectHistory(_ context.Context, filter ProjectHistoryFilter) ([]types.ProjectHistory, error) {
+	var out []types.ProjectHistory
+	for _, h := range ms.history {
+		if (filter.ProjectId == nil || h.ProjectId == *filter.ProjectId) &&
+			(filter.Attribute == nil || h.Attribute == *filter.Attribute) {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// StoreProjectHistory stores a change of the project attribute, unless it is stored already.
+func (ms *memoryStore) StoreProjectHistory(_ context.Context, history *types.ProjectHistory) error {
+	if !ms.projectExists(history.ProjectId) {
+		return fmt.Errorf("%s change of project %d: %w", history.Attribute, history.ProjectId, ErrMissingReference)
+	}
+	for _, h := range ms.history {
+		if h.ProjectId == history.ProjectId && h.BlockNumber == history.BlockNumber &&
+			h.LogIndex == history.LogIndex && h.Attribute == history.Attribute {
+			return nil
+		}
+	}
+	ms.sequence++
+	h := *history
+	h.Id = ms.sequence
+	ms.history = append(ms.history, h)
+	return nil
+}
+
+// ProjectAttributeAt returns the last change of the project attribute made up to the given epoch.
+func (ms *memoryStore) ProjectAttributeAt(_ context.Context, projectId int64, attribute string, epoch uint64) (*types.ProjectHistory, error) {
+	var last *types.ProjectHistory
+	for i, h := range ms.history {
+		if h.ProjectId != projectId || h.Attribute != attribute || h.Epoch > epoch {
+			continue
+		}
+		if last == nil || h.BlockNumber > last.BlockNumber || h.BlockNumber == last.BlockNumber && h.LogIndex > last.LogIndex {
+			last = &ms.history[i]
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	out := *last
+	return &out, nil
+}
+
+// FindProjectContracts returns project contracts matching the filter ordered by their id.
+func (ms *memoryStore) FindProjectContracts(_ context.Context, filter ProjectContractFilter) ([]types.ProjectContract, error) {
+	var out []types.ProjectContract
+	for _, c := range ms.contracts {
+		if (filter.ProjectId == nil || c.ProjectId == *filter.ProjectId) &&
+			(filter.Address == nil || sameAddress(c.Address, filter.Address)) &&
+			(filter.Approved == nil || c.Approved == *filter.Approved) &&
+			(!filter.NotRemoved || c.RemovedAtBlock == nil) &&
+			(filter.MemberAtBlock == nil || c.IsMemberAt(*filter.MemberAtBlock)) &&
+			(filter.AddedAtBlock == nil || c.AddedAtBlock == *filter.AddedAtBlock) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// StoreProjectContract stores a new membership of the contract in the project.
+func (ms *memoryStore) StoreProjectContract(_ context.Context, contract *types.ProjectContract) error {
+	project := ms.project(contract.ProjectId)
+	if project == nil {
+		return fmt.Errorf("project contract %s: %w", contract.Address.Hex(), ErrMissingReference)
+	}
+	// the contract can be an open member of a single project of each gas monetization contract
+	if contract.RemovedAtBlock == nil {
+		for _, c := range ms.contracts {
+			if c.RemovedAtBlock == nil && sameAddress(c.ContractAddress, project.ContractAddress) && sameAddress(c.Address, contract.Address) {
+				return fmt.Errorf("project contract %s: %w", contract.Address.Hex(), ErrDuplicate)
+			}
+		}
+	}
+	ms.sequence++
+	c := *contract
+	c.Id = ms.sequence
+	c.ContractAddress = project.ContractAddress
+	ms.contracts = append(ms.contracts, c)
+	return nil
+}
+
+// RemoveProjectContract closes the membership of the contract in the project at the given epoch and block.
+func (ms *memoryStore) RemoveProjectContract(_ context.Context, contract *types.ProjectContract, epoch uint64, block uint64) error {
+	contract.RemovedAtEpoch = &epoch
+	contract.RemovedAtBlock = &block
+	for i := range ms.contracts {
+		if ms.contracts[i].Id == contract.Id {
+			ms.contracts[i].RemovedAtEpoch = &epoch
+			ms.contracts[i].RemovedAtBlock = &block
+		}
+	}
+	return nil
+}
+
+// FindTransactions returns rewarded transactions matching the filter ordered by their id.
+func (ms *memoryStore) FindTransactions(_ context.Context, filter TransactionFilter) ([]types.Transaction, error) {
+	var out []types.Transaction
+	for _, t := range ms.transactions {
+		if filter.matches(&t) {
+			out = append(out, t)
+		}
+	}
+	if filter.Latest == 0 {
+		return out, nil
+	}
+	// the latest transactions are listed from the newest
+	latest := make([]types.Transaction, 0, filter.Latest)
+	for i := len(out) - 1; i >= 0 && uint64(len(latest)) < filter.Latest; i-- {
+		latest = append(latest, out[i])
+	}
+	return latest, nil
+}
+
+// DeleteTransactions deletes rewarded transactions matching the filter.
+func (ms *memoryStore) DeleteTransactions(_ context.Context, filter TransactionFilter) error {
+	kept := ms.transactions[:0:0]
+	for _, t := range ms.transactions {
+		if !filter.matches(&t) {
+			kept = append(kept, t)
+		}
+	}
+	ms.transactions = kept
+	return nil
+}
+
+// NewTransactionBatch creates a new batch of rewarded call frames joining the running transaction.
+func (ms *memoryStore) NewTransactionBatch(size int) TransactionBatch {
+	return newMemoryBatch(ms, size)
+}
+
+// matches checks the transaction matches the conditions of the filter.
+func (filter *TransactionFilter) matches(t *types.Transaction) bool {
+	return (filter.ProjectId == nil || t.ProjectId == *filter.ProjectId) &&
+		(filter.Contract == nil || sameAddress(t.ContractAddress, filter.Contract)) &&
+		(filter.Epoch == nil || uint64(t.Epoch) == *filter.Epoch) &&
+		(filter.EpochBefore == nil || uint64(t.Epoch) < *filter.EpochBefore) &&
+		(filter.Hash == nil || t.Hash != nil && t.Hash.Hash == filter.Hash.Hash)
+}
+
+// StoreTransaction stores a rewarded call frame of a transaction.
+func (ms *memoryStore) StoreTransaction(_ context.Context, trx *types.Transaction) error {
+	if !ms.projectExists(trx.ProjectId) {
+		return fmt.Errorf("transaction %s: %w", trx.Hash.String(), ErrMissingReference)
+	}
+	ms.sequence++
+	t := *trx
+	t.Id = ms.sequence
+	// the fee caps and logs are not stored
+	t.MaxFeePerGas = nil
+	t.MaxPriorityFeePerGas = nil
+	t.Logs = nil
+	ms.transactions = append(ms.transactions, t)
+	return nil
+}
+
+// FindWithdrawalRequests returns withdrawal requests matching the filter ordered by their id.
+func (ms *memoryStore) FindWithdrawalRequests(_ context.Context, filter WithdrawalRequestFilter) ([]types.WithdrawalRequest, error) {
+	var out []types.WithdrawalRequest
+	for _, w := range ms.withdrawals {
+		if (filter.ProjectId == nil || w.ProjectId == *filter.ProjectId) &&
+			(filter.Contract == nil || sameAddress(w.ContractAddress, filter.Contract)) &&
+			(filter.RequestEpoch == nil || w.RequestEpoch == *filter.RequestEpoch) &&
+			(!filter.NotWithdrawn || w.WithdrawEpoch == nil) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+// StoreWithdrawalRequest stores a new withdrawal request.
+func (ms *memoryStore) StoreWithdrawalRequest(_ context.Context, request *types.WithdrawalRequest) error {
+	if !ms.projectExists(request.ProjectId) {
+		return fmt.Errorf("withdrawal request of project %d: %w", request.ProjectId, ErrMissingReference)
+	}
+	ms.sequence++
+	w := *request
+	w.Id = ms.sequence
+	ms.withdrawals = append(ms.withdrawals, w)
+	return nil
+}
+
+// UpdateWithdrawalRequest updates the withdrawal request.
+func (ms *memoryStore) UpdateWithdrawalRequest(_ context.Context, request *types.WithdrawalRequest) error {
+	if request.Id == 0 {
+		return fmt.Errorf("failed to update withdrawal. request id is 0")
+	}
+	if !ms.projectExists(request.ProjectId) {
+		return fmt.Errorf("withdrawal request of project %d: %w", request.ProjectId, ErrMissingReference)
+	}
+	for i := range ms.withdrawals {
+		if ms.withdrawals[i].Id == request.Id {
+			ms.withdrawals[i] = *request
+		}
+	}
+	return nil
+}
+
+// FindContractEvents returns contract events matching the filter ordered by their block and log index.
+func (ms *memoryStore) FindContractEvents(_ context.Context, filter ContractEventFilter) ([]types.ContractEvent, error) {
+	var out []types.ContractEvent
+	for _, e := range ms.events {
+		if (filter.Contract == nil || sameAddress(e.ContractAddress, filter.Contract)) &&
+			(filter.TxHash == nil || sameHash(e.TxHash, filter.TxHash)) &&
+			(filter.LogIndex == nil || e.LogIndex == *filter.LogIndex) &&
+			(filter.Name == nil || e.Name != nil && *e.Name == *filter.Name) &&
+			(filter.Outcome == nil || e.Outcome == *filter.Outcome) &&
+			(filter.FromBlock == nil || e.BlockNumber >= *filter.FromBlock) &&
+			(filter.ToBlock == nil || e.BlockNumber <= *filter.ToBlock) &&
+			(filter.After == nil || e.BlockNumber > filter.After.BlockNumber ||
+				e.BlockNumber == filter.After.BlockNumber && e.LogIndex > filter.After.LogIndex) {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].BlockNumber != out[j].BlockNumber {
+			return out[i].BlockNumber < out[j].BlockNumber
+		}
+		return out[i].LogIndex < out[j].LogIndex
+	})
+	if filter.Limit > 0 && uint64(len(out)) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out, nil
+}
+
+// StoreContractEvent stores the contract event, replacing the event already stored for the same log.
+func (ms *memoryStore) StoreContractEvent(_ context.Context, event *types.ContractEvent) error {
+	for i := range ms.events {
+		if sameHash(ms.events[i].TxHash, event.TxHash) && ms.events[i].LogIndex == event.LogIndex {
+			e := *event
+			e.Id = ms.events[i].Id
+			ms.events[i] = e
+			return nil
+		}
+	}
+	ms.sequence++
+	e := *event
+	e.Id = ms.sequence
+	ms.events = append(ms.events, e)
+	return nil
+}
+
+// OpenEpoch records the start of the epoch at the given block. An epoch already recorded is kept.
+func (ms *memoryStore) OpenEpoch(_ context.Context, number uint64, firstBlock uint64, start time.Time) error {
+	if ms.epoch(number) != nil {
+		return nil
+	}
+	ms.epochs = append(ms.epochs, types.Epoch{Number: number, FirstBlock: &firstBlock, StartTime: &start})
+	return nil
+}
+
+// SealEpoch records the end of the epoch and marks it sealed. It returns false if the epoch was sealed already.
+func (ms *memoryStore) SealEpoch(_ context.Context, number uint64, lastBlock uint64, end time.Time) (bool, error) {
+	e := ms.epoch(number)
+	if e == nil {
+		ms.epochs = append(ms.epochs, types.Epoch{Number: number})
+		e = &ms.epochs[len(ms.epochs)-1]
+	}
+	if e.Sealed {
+		return false, nil
+	}
+	e.LastBlock = &lastBlock
+	e.EndTime = &end
+	e.Sealed = true
+	return true, nil
+}
+
+// epoch returns the recorded epoch of the given number, nil if there is none.
+func (ms *memoryStore) epoch(number uint64) *types.Epoch {
+	for i := range ms.epochs {
+		if ms.epochs[i].Number == number {
+			return &ms.epochs[i]
+		}
+	}
+	return nil
+}
+
+// LastProcessedBlock returns the last processed block.
+func (ms *memoryStore) LastProcessedBlock(context.Context) (uint64, error) {
+	return ms.lastBlock, nil
+}
+
+// UpdateLastProcessedBlock updates the last processed block.
+func (ms *memoryStore) UpdateLastProcessedBlock(_ context.Context, block uint64) error {
+	ms.lastBlock = block
+	return nil
+}
+
+// CurrentEpoch returns the current epoch.
+func (ms *memoryStore) CurrentEpoch(context.Context) (uint64, error) {
+	return ms.currentEpoch, nil
+}
+
+// UpdateCurrentEpoch updates the current epoch.
+func (ms *memoryStore) UpdateCurrentEpoch(_ context.Context, epoch uint64) error {
+	ms.currentEpoch = epoch
+	return nil
+}
+
+// BootstrapCheckpoint returns the block the interrupted bootstrap continues from.
+func (ms *memoryStore) BootstrapCheckpoint(context.Context) (uint64, error) {
+	return ms.checkpoint, nil
+}
+
+// UpdateBootstrapCheckpoint updates the block the bootstrap continues from.
+func (ms *memoryStore) UpdateBootstrapCheckpoint(_ context.Context, block uint64) error {
+	ms.checkpoint = block
+	return nil
+}
+
+// Stats returns the global totals.
+func (ms *memoryStore) Stats(context.Context) (*types.Stats, error) {
+	stats := ms.stats
+	return &stats, nil
+}
+
+// IncreaseTotalAmountCollected increases the total amount collected.
+func (ms *memoryStore) IncreaseTotalAmountCollected(_ context.Context, amount *big.Int) error {
+	ms.stats.TotalAmountCollected = types.Big{Big: hexutil.Big(*new(big.Int).Add(ms.stats.TotalAmountCollected.ToInt(), amount))}
+	return nil
+}
+
+// IncreaseTotalAmountClaimed increases the total amount claimed.
+func (ms *memoryStore) IncreaseTotalAmountClaimed(_ context.Context, amount *big.Int) error {
+	ms.stats.TotalAmountClaimed = types.Big{Big: hexutil.Big(*new(big.Int).Add(ms.stats.TotalAmountClaimed.ToInt(), amount))}
+	return nil
+}
+
+// IncreaseTotalTransactionsCount increases the total number of transactions.
+func (ms *memoryStore) IncreaseTotalTransactionsCount(_ context.Context, count uint64) error {
+	ms.stats.TotalTransactionsCount += count
+	return nil
+}
+
+// Atomic joins the running transaction of the locked storage.
+func (ms *memoryStore) Atomic(ctx context.Context, fn func(context.Context, Storage) error) error {
+	return fn(ctx, ms)
+}
+
+// snapshot returns a copy of the data the storage can be restored to. Records are replaced, never
+// modified in place, so copying the lists is enough.
+func (ms *memoryStore) snapshot() memoryStore {
+	out := *ms
+	out.projects = append([]types.Project(nil), ms.projects...)
+	out.periods = append([]types.ProjectActivityPeriod(nil), ms.periods...)
+	out.versions = append([]types.ProjectMetadataVersion(nil), ms.versions...)
+	out.history = append([]types.ProjectHistory(nil), ms.history...)
+	out.contracts = append([]types.ProjectContract(nil), ms.contracts...)
+	out.transactions = append([]types.Transaction(nil), ms.transactions...)
+	out.withdrawals = append([]types.WithdrawalRequest(nil), ms.withdrawals...)
+	out.events = append([]types.ContractEvent(nil), ms.events...)
+	out.epochs = append([]types.Epoch(nil), ms.epochs...)
+	return out
+}
+
+// projectExists checks the project of the given id is stored.
+func (ms *memoryStore) projectExists(id int64) bool {
+	for _, p := range ms.projects {
+		if p.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// project returns the stored project of the given id, nil if there is none.
+func (ms *memoryStore) project(id int64) *types.Project {
+	for i := range ms.projects {
+		if ms.projects[i].Id == id {
+			return &ms.projects[i]
+		}
+	}
+	return nil
+}
+
+// sameHash checks both hashes are set and equal, like the SQL comparison does.
+func sameHash(a *types.Hash, b *types.Hash) bool {
+	return a != nil && b != nil && a.Hash == b.Hash
+}
+
+// hasTag checks the tags of the metadata contain the given tag.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAddress checks both addresses are set and equal, like the SQL comparison does.
+func sameAddress(a *types.Address, b *types.Address) bool {
+	return a != nil && b != nil && a.Address == b.Address
+}
+
+// memoryBatch collects rewarded call frames and stores them all at once on flush.
+type memoryBatch struct {
+	st   Storage
+	size int
+	rows []*types.Transaction
+}
+
+// newMemoryBatch creates a new batch of rewarded call frames stored into the given storage.
+func newMemoryBatch(st Storage, size int) *memoryBatch {
+	return &memoryBatch{st: st, size: size, rows: make([]*types.Transaction, 0, size)}
+}
+
+// Add adds the call frame to the batch, the batch is flushed when it is full.
+func (b *memoryBatch) Add(ctx context.Context, trx *types.Transaction) error {
+	b.rows = append(b.rows, trx)
+	if len(b.rows) >= b.size {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+// Len returns the number of call frames waiting to be written.
+func (b *memoryBatch) Len() int {
+	return len(b.rows)
+}
+
+// Flush stores all the call frames of the batch, none of them is stored on failure.
+func (b *memoryBatch) Flush(ctx context.Context) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+	err := b.st.Atomic(ctx, func(ctx context.Context, st Storage) error {
+		for _, trx := range b.rows {
+			if err := st.StoreTransaction(ctx, trx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	b.rows = b.rows[:0]
+	return nil
+}
@@ -0,0 +1,14 @@
+package storage_test
+
+import (
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/repository/storage/storagetest"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	suite.Run(t, &storagetest.Suite{New: func() storage.Storage {
+		return storage.NewMemory()
+	}})
+}
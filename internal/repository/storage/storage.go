@@ -0,0 +1,199 @@
+// Package storage defines the persistent storage of the gas monetization state, so the services
+// do not depend on a particular database. The Postgres implementation lives in the db package,
+// the in-memory implementation in this package.
+package storage
+
+import (
+	"context"
+	"errors"
+	"ftm-gas-monetization/internal/types"
+	"math/big"
+	"time"
+)
+
+var (
+	// ErrDuplicate represents a violation of a unique constraint.
+	ErrDuplicate = errors.New("duplicate record")
+
+	// ErrMissingReference represents a violation of a foreign key constraint.
+	ErrMissingReference = errors.New("referenced record does not exist")
+)
+
+// Storage represents the persistent storage of projects, their contracts, rewarded transactions,
+// withdrawal requests and the processing state.
+type Storage interface {
+	// FindProjects returns projects matching the filter ordered by their id.
+	FindProjects(ctx context.Context, filter ProjectFilter) ([]types.Project, error)
+	// StoreProject stores a new project and sets its id. The metadata fetch state starts as pending.
+	StoreProject(ctx context.Context, project *types.Project) error
+	// UpdateProject updates the registry attributes and the rewards of the project.
+	UpdateProject(ctx context.Context, project *types.Project) error
+	// FindProjectActivityPeriods returns the periods the project was active in ordered by their first epoch.
+	FindProjectActivityPeriods(ctx context.Context, projectId int64) ([]types.ProjectActivityPeriod, error)
+
+	// ScheduleProjectMetadata schedules the metadata of the project to be fetched as soon as possible.
+	ScheduleProjectMetadata(ctx context.Context, project *types.Project) error
+	// UpdateProjectMetadata updates the metadata and the metadata fetch state of the project. The update
+	// is skipped if the metadata URI of the project changed meanwhile; false is returned in that case.
+	UpdateProjectMetadata(ctx context.Context, project *types.Project) (bool, error)
+	// FindProjectMetadataVersions returns the distinct metadata documents of the project ordered by their id.
+	FindProjectMetadataVersions(ctx context.Context, projectId int64) ([]types.ProjectMetadataVersion, error)
+	// StoreProjectMetadataVersion stores the metadata document of the project unless it equals the latest
+	// stored version of the project. Returns true if a new version was stored.
+	StoreProjectMetadataVersion(ctx context.Context, version *types.ProjectMetadataVersion) (bool, error)
+
+	// FindProjectHistory returns changes of the project attributes matching the filter ordered by their id.
+	FindProjectHistory(ctx context.Context, filter ProjectHistoryFilter) ([]types.ProjectHistory, error)
+	// StoreProjectHistory stores a change of the project attribute. A change already stored for the same
+	// block, log index and attribute is kept.
+	StoreProjectHistory(ctx context.Context, history *types.ProjectHistory) error
+	// ProjectAttributeAt returns the last change of the project attribute made up to the given epoch,
+	// nil if the attribute was not set by then.
+	ProjectAttributeAt(ctx context.Context, projectId int64, attribute string, epoch uint64) (*types.ProjectHistory, error)
+
+	// FindProjectContracts returns project contracts matching the filter ordered by their id.
+	FindProjectContracts(ctx context.Context, filter ProjectContractFilter) ([]types.ProjectContract, error)
+	// StoreProjectContract stores a new membership of the contract in the project.
+	StoreProjectContract(ctx context.Context, contract *types.ProjectContract) error
+	// RemoveProjectContract closes the membership of the contract in the project at the given epoch and block.
+	RemoveProjectContract(ctx context.Context, contract *types.ProjectContract, epoch uint64, block uint64) error
+
+	// FindTransactions returns rewarded transactions matching the filter ordered by their id.
+	FindTransactions(ctx context.Context, filter TransactionFilter) ([]types.Transaction, error)
+	// StoreTransaction stores a rewarded call frame of a transaction.
+	StoreTransaction(ctx context.Context, trx *types.Transaction) error
+	// NewTransactionBatch creates a new batch of rewarded call frames written at once. The batch is written
+	// whenever it holds the given number of call frames, the rest is written by Flush.
+	NewTransactionBatch(size int) TransactionBatch
+	// DeleteTransactions deletes rewarded transactions matching the filter.
+	DeleteTransactions(ctx context.Context, filter TransactionFilter) error
+
+	// FindWithdrawalRequests returns withdrawal requests matching the filter ordered by their id.
+	FindWithdrawalRequests(ctx context.Context, filter WithdrawalRequestFilter) ([]types.WithdrawalRequest, error)
+	// StoreWithdrawalRequest stores a new withdrawal request.
+	StoreWithdrawalRequest(ctx context.Context, request *types.WithdrawalRequest) error
+	// UpdateWithdrawalRequest updates the withdrawal request.
+	UpdateWithdrawalRequest(ctx context.Context, request *types.WithdrawalRequest) error
+
+	// FindContractEvents returns contract events matching the filter ordered by their block and log index.
+	FindContractEvents(ctx context.Context, filter ContractEventFilter) ([]types.ContractEvent, error)
+	// StoreContractEvent stores the contract event. An event already stored for the same log is replaced,
+	// so a retried block overwrites the outcome of the previous attempt.
+	StoreContractEvent(ctx context.Context, event *types.ContractEvent) error
+
+	// OpenEpoch records the start of the epoch at the given block. An epoch already recorded is kept.
+	OpenEpoch(ctx context.Context, number uint64, firstBlock uint64, start time.Time) error
+	// SealEpoch records the end of the epoch and marks it sealed. It returns false if the epoch was sealed already.
+	SealEpoch(ctx context.Context, number uint64, lastBlock uint64, end time.Time) (bool, error)
+
+	// LastProcessedBlock returns the last processed block, zero if no block was processed yet.
+	LastProcessedBlock(ctx context.Context) (uint64, error)
+	// UpdateLastProcessedBlock updates the last processed block.
+	UpdateLastProcessedBlock(ctx context.Context, block uint64) error
+	// CurrentEpoch returns the current epoch, zero if not known yet.
+	CurrentEpoch(ctx context.Context) (uint64, error)
+	// UpdateCurrentEpoch updates the current epoch.
+	UpdateCurrentEpoch(ctx context.Context, epoch uint64) error
+	// BootstrapCheckpoint returns the block the interrupted bootstrap continues from, zero if none is running.
+	BootstrapCheckpoint(ctx context.Context) (uint64, error)
+	// UpdateBootstrapCheckpoint updates the block the bootstrap continues from, zero marks it finished.
+	UpdateBootstrapCheckpoint(ctx context.Context, block uint64) error
+	// Stats returns the global totals.
+	Stats(ctx context.Context) (*types.Stats, error)
+	// IncreaseTotalAmountCollected increases the total amount collected.
+	IncreaseTotalAmountCollected(ctx context.Context, amount *big.Int) error
+	// IncreaseTotalAmountClaimed increases the total amount claimed.
+	IncreaseTotalAmountClaimed(ctx context.Context, amount *big.Int) error
+	// IncreaseTotalTransactionsCount increases the total number of transactions.
+	IncreaseTotalTransactionsCount(ctx context.Context, count uint64) error
+
+	// Atomic runs the given function over a storage applying all its changes at once. The changes are
+	// discarded if the function fails. Atomic called inside of the function joins the running one.
+	Atomic(ctx context.Context, fn func(context.Context, Storage) error) error
+}
+
+// TransactionBatch represents a batch of rewarded call frames written to the storage at once.
+type TransactionBatch interface {
+	// Add adds the call frame to the batch. The call frame MUST NOT be modified until the batch is written.
+	Add(ctx context.Context, trx *types.Transaction) error
+	// Len returns the number of call frames waiting to be written.
+	Len() int
+	// Flush writes all the call frames of the batch.
+	Flush(ctx context.Context) error
+}
+
+// Partitioned represents a storage keeping the rewarded transactions in partitions by epoch,
+// which have to exist before the transactions of their epochs are stored.
+type Partitioned interface {
+	// PrepareTransactionPartitions creates the missing partitions of the given size covering the given epochs.
+	PrepareTransactionPartitions(ctx context.Context, fromEpoch uint64, toEpoch uint64, size uint64) error
+}
+
+// ProjectFilter represents conditions of the projects to be found, nil conditions are not applied.
+type ProjectFilter struct {
+	Id            *int64
+	Contract      *types.Address
+	ProjectId     *uint64
+	Owner         *types.Address
+	ActiveInEpoch *uint64
+	// Category and Tag match the metadata of the project.
+	Category *string
+	Tag      *string
+}
+
+// ProjectContractFilter represents conditions of the project contracts to be found, nil conditions are not applied.
+type ProjectContractFilter struct {
+	ProjectId     *int64
+	Address       *types.Address
+	Approved      *bool
+	NotRemoved    bool
+	MemberAtBlock *uint64
+	AddedAtBlock  *uint64
+}
+
+// TransactionFilter represents conditions of the rewarded transactions to be found, nil conditions are not applied.
+type TransactionFilter struct {
+	ProjectId   *int64
+	Contract    *types.Address
+	Epoch       *uint64
+	EpochBefore *uint64
+	Hash        *types.Hash
+	// Latest limits the found transactions to the given number of the latest ones ordered from the newest.
+	// It is not applied by DeleteTransactions.
+	Latest uint64
+}
+
+// WithdrawalRequestFilter represents conditions of the withdrawal requests to be found, nil conditions are not applied.
+type WithdrawalRequestFilter struct {
+	ProjectId    *int64
+	Contract     *types.Address
+	RequestEpoch *uint64
+	NotWithdrawn bool
+}
+
+// ProjectHistoryFilter represents conditions of the project attribute changes to be found, nil conditions are not applied.
+type ProjectHistoryFilter struct {
+	ProjectId *int64
+	Attribute *string
+}
+
+// ContractEventFilter represents conditions of the contract events to be found, nil conditions are not applied.
+type ContractEventFilter struct {
+	Contract  *types.Address
+	TxHash    *types.Hash
+	LogIndex  *uint64
+	Name      *string
+	Outcome   *string
+	FromBlock *uint64
+	ToBlock   *uint64
+	// After limits the found events to the ones following the given position.
+	After *EventPosition
+	// Limit limits the number of the found events, zero means no limit.
+	Limit uint64
+}
+
+// EventPosition represents the position of a contract event in the block and log index order.
+type EventPosition struct {
+	BlockNumber uint64
+	LogIndex    uint64
+}
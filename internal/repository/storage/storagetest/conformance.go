@@ -0,0 +1,509 @@
+// Package storagetest provides the conformance test suite every storage implementation must pass.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"math/big"
+	"time"
+)
+
+var (
+	testRegistry = &types.Address{Address: common.HexToAddress("0x391b50362bbb5adb5e0c55b120e6104363a036ab")}
+	testOwner    = &types.Address{Address: common.HexToAddress("0x2dbcd1d2d1aa8ecd8e2a5d4c8e2a5d4c8e2a5d4c")}
+	testContract = &types.Address{Address: common.HexToAddress("0x83a6524be9213b1ce36bcc0dcefb5eb51d87ad10")}
+)
+
+// Suite verifies a storage implementation behaves the way the services expect.
+type Suite struct {
+	suite.Suite
+	// New returns an empty storage for each test.
+	New func() storage.Storage
+	st  storage.Storage
+}
+
+func (s *Suite) SetupTest() {
+	s.st = s.New()
+}
+
+func (s *Suite) TestStoreProject() {
+	first := s.storeProject(1)
+	second := s.storeProject(2)
+	assert.NotZero(s.T(), first.Id)
+	assert.NotEqual(s.T(), first.Id, second.Id)
+
+	// the same project can not be registered twice
+	err := s.st.StoreProject(context.Background(), testProject(1))
+	assert.True(s.T(), errors.Is(err, storage.ErrDuplicate))
+
+	projects, err := s.st.FindProjects(context.Background(), storage.ProjectFilter{})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 2)
+	assert.Equal(s.T(), first.Id, projects[0].Id)
+	assert.Equal(s.T(), types.ProjectMetadataPending, projects[0].MetadataStatus)
+}
+
+func (s *Suite) TestFindProjects() {
+	first := s.storeProject(1)
+	second := s.storeProject(2)
+	to := uint64(5)
+	second.ActiveToEpoch = &to
+	assert.Nil(s.T(), s.st.UpdateProject(context.Background(), second))
+
+	projectId := uint64(2)
+	projects, err := s.st.FindProjects(context.Background(), storage.ProjectFilter{ProjectId: &projectId})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 1)
+	assert.Equal(s.T(), second.Id, projects[0].Id)
+
+	projects, err = s.st.FindProjects(context.Background(), storage.ProjectFilter{Id: &first.Id, Owner: testOwner, Contract: testRegistry})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 1)
+	assert.Equal(s.T(), first.Id, projects[0].Id)
+
+	projects, err = s.st.FindProjects(context.Background(), storage.ProjectFilter{Owner: testContract})
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), projects)
+
+	// the project is active until its last epoch, excluding it
+	for epoch, count := range map[uint64]int{4: 2, 5: 1} {
+		epoch := epoch
+		projects, err = s.st.FindProjects(context.Background(), storage.ProjectFilter{ActiveInEpoch: &epoch})
+		assert.Nil(s.T(), err)
+		assert.Len(s.T(), projects, count, "epoch %d", epoch)
+	}
+}
+
+func (s *Suite) TestActivityPeriods() {
+	project := s.storeProject(1)
+
+	// suspend the project and enable it again later
+	to := uint64(5)
+	project.ActiveToEpoch = &to
+	assert.Nil(s.T(), s.st.UpdateProject(context.Background(), project))
+	project.ActiveFromEpoch = 8
+	project.ActiveToEpoch = nil
+	assert.Nil(s.T(), s.st.UpdateProject(context.Background(), project))
+
+	// the project is inactive while it was suspended only
+	for epoch, count := range map[uint64]int{0: 0, 3: 1, 6: 0, 9: 1} {
+		epoch := epoch
+		projects, err := s.st.FindProjects(context.Background(), storage.ProjectFilter{ActiveInEpoch: &epoch})
+		assert.Nil(s.T(), err)
+		assert.Len(s.T(), projects, count, "epoch %d", epoch)
+	}
+}
+
+func (s *Suite) TestUpdateProject() {
+	project := s.storeProject(1)
+	project.Url = "https://example.com"
+	project.TransactionsCount = 3
+	project.CollectedRewards = testBig("123456789012345678901234567890")
+	assert.Nil(s.T(), s.st.UpdateProject(context.Background(), project))
+
+	projects, err := s.st.FindProjects(context.Background(), storage.ProjectFilter{Id: &project.Id})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 1)
+	assert.Equal(s.T(), "https://example.com", projects[0].Url)
+	assert.EqualValues(s.T(), 3, projects[0].TransactionsCount)
+	assert.Zero(s.T(), project.CollectedRewards.ToInt().Cmp(projects[0].CollectedRewards.ToInt()))
+
+	// the update can not collide with another project
+	other := s.storeProject(2)
+	other.ProjectId = 1
+	err = s.st.UpdateProject(context.Background(), other)
+	assert.True(s.T(), errors.Is(err, storage.ErrDuplicate))
+
+	// unknown projects can not be updated
+	assert.NotNil(s.T(), s.st.UpdateProject(context.Background(), testProject(3)))
+}
+
+func (s *Suite) TestProjectHistory() {
+	project := s.storeProject(1)
+
+	// the change must belong to an existing project
+	err := s.st.StoreProjectHistory(context.Background(), testProjectHistory(project.Id+100, 10, 0, 1, "0x01"))
+	assert.True(s.T(), errors.Is(err, storage.ErrMissingReference))
+
+	for _, h := range []*types.ProjectHistory{
+		testProjectHistory(project.Id, 10, 0, 1, "0x01"),
+		testProjectHistory(project.Id, 20, 1, 2, "0x02"),
+		testProjectHistory(project.Id, 20, 3, 2, "0x03"),
+		testProjectHistory(project.Id, 30, 0, 4, "0x04"),
+	} {
+		assert.Nil(s.T(), s.st.StoreProjectHistory(context.Background(), h))
+	}
+	// the change of a replayed log record is kept
+	assert.Nil(s.T(), s.st.StoreProjectHistory(context.Background(), testProjectHistory(project.Id, 20, 3, 2, "0x05")))
+	attribute := types.ProjectAttributeRecipient
+	history, err := s.st.FindProjectHistory(context.Background(), storage.ProjectHistoryFilter{
+		ProjectId: &project.Id,
+		Attribute: &attribute,
+	})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), history, 4)
+
+	// the recipient at the epoch is the last one set up to the end of the epoch
+	for epoch, recipient := range map[uint64]string{1: "0x01", 2: "0x03", 3: "0x03", 5: "0x04"} {
+		h, err := s.st.ProjectAttributeAt(context.Background(), project.Id, types.ProjectAttributeRecipient, epoch)
+		assert.Nil(s.T(), err)
+		assert.Equal(s.T(), recipient, h.NewValue)
+	}
+	h, err := s.st.ProjectAttributeAt(context.Background(), project.Id, types.ProjectAttributeRecipient, 0)
+	assert.Nil(s.T(), err)
+	assert.Nil(s.T(), h)
+	h, err = s.st.ProjectAttributeAt(context.Background(), project.Id, types.ProjectAttributeOwner, 5)
+	assert.Nil(s.T(), err)
+	assert.Nil(s.T(), h)
+}
+
+func (s *Suite) TestProjectContracts() {
+	project := s.storeProject(1)
+
+	// the contract must belong to an existing project
+	err := s.st.StoreProjectContract(context.Background(), testProjectContract(project.Id+100, 10))
+	assert.True(s.T(), errors.Is(err, storage.ErrMissingReference))
+
+	assert.Nil(s.T(), s.st.StoreProjectContract(context.Background(), testProjectContract(project.Id, 10)))
+
+	// the contract can be an open member of the project only once
+	err = s.st.StoreProjectContract(context.Background(), testProjectContract(project.Id, 15))
+	assert.True(s.T(), errors.Is(err, storage.ErrDuplicate))
+
+	// neither it can be a member of another project of the same gas monetization contract
+	other := s.storeProject(2)
+	err = s.st.StoreProjectContract(context.Background(), testProjectContract(other.Id, 15))
+	assert.True(s.T(), errors.Is(err, storage.ErrDuplicate))
+
+	// projects of another gas monetization contract are independent
+	migrated := testProject(1)
+	migrated.ContractAddress = testOwner
+	assert.Nil(s.T(), s.st.StoreProject(context.Background(), migrated))
+	assert.Nil(s.T(), s.st.StoreProjectContract(context.Background(), testProjectContract(migrated.Id, 15)))
+
+	contracts, err := s.st.FindProjectContracts(context.Background(), storage.ProjectContractFilter{ProjectId: &project.Id, NotRemoved: true})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), contracts, 1)
+	assert.NotZero(s.T(), contracts[0].Id)
+	assert.Equal(s.T(), testContract.Address, contracts[0].Address.Address)
+
+	// removed contract can be added again
+	assert.Nil(s.T(), s.st.RemoveProjectContract(context.Background(), &contracts[0], 2, 20))
+	assert.EqualValues(s.T(), 20, *contracts[0].RemovedAtBlock)
+	assert.Nil(s.T(), s.st.StoreProjectContract(context.Background(), testProjectContract(project.Id, 30)))
+
+	contracts, err = s.st.FindProjectContracts(context.Background(), storage.ProjectContractFilter{ProjectId: &project.Id})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), contracts, 2)
+	assert.EqualValues(s.T(), 20, *contracts[0].RemovedAtBlock)
+	assert.Nil(s.T(), contracts[1].RemovedAtBlock)
+	assert.Equal(s.T(), testRegistry.Address, contracts[1].ContractAddress.Address)
+
+	for block, count := range map[uint64]int{9: 0, 10: 1, 15: 2, 20: 1, 25: 1, 30: 2} {
+		block := block
+		contracts, err = s.st.FindProjectContracts(context.Background(), storage.ProjectContractFilter{MemberAtBlock: &block})
+		assert.Nil(s.T(), err)
+		assert.Len(s.T(), contracts, count, "block %d", block)
+	}
+
+	approved := true
+	contracts, err = s.st.FindProjectContracts(context.Background(), storage.ProjectContractFilter{Approved: &approved})
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), contracts)
+}
+
+func (s *Suite) TestTransactions() {
+	project := s.storeProject(1)
+
+	// the transaction must belong to an existing project
+	err := s.st.StoreTransaction(context.Background(), testTransaction(project.Id+100, 1, 1))
+	assert.True(s.T(), errors.Is(err, storage.ErrMissingReference))
+
+	assert.Nil(s.T(), s.st.StoreTransaction(context.Background(), testTransaction(project.Id, 1, 1)))
+	assert.Nil(s.T(), s.st.StoreTransaction(context.Background(), testTransaction(project.Id, 2, 1)))
+	assert.Nil(s.T(), s.st.StoreTransaction(context.Background(), testTransaction(project.Id, 3, 2)))
+
+	trxs, err := s.st.FindTransactions(context.Background(), storage.TransactionFilter{ProjectId: &project.Id})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), trxs, 3)
+	assert.Less(s.T(), trxs[0].Id, trxs[1].Id)
+	assert.Equal(s.T(), "123456789012345678901234567890", trxs[0].RewardToClaim.ToInt().String())
+	assert.Nil(s.T(), trxs[0].MaxFeePerGas)
+
+	epoch := uint64(1)
+	trxs, err = s.st.FindTransactions(context.Background(), storage.TransactionFilter{Epoch: &epoch, Contract: testContract})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), trxs, 2)
+
+	hash := testHash(3)
+	trxs, err = s.st.FindTransactions(context.Background(), storage.TransactionFilter{Hash: hash})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), trxs, 1)
+	assert.EqualValues(s.T(), 2, trxs[0].Epoch)
+}
+
+func (s *Suite) TestWithdrawalRequests() {
+	project := s.storeProject(1)
+
+	// the request must belong to an existing project
+	err := s.st.StoreWithdrawalRequest(context.Background(), testWithdrawalRequest(project.Id+100, 1))
+	assert.True(s.T(), errors.Is(err, storage.ErrMissingReference))
+
+	assert.Nil(s.T(), s.st.StoreWithdrawalRequest(context.Background(), testWithdrawalRequest(project.Id, 1)))
+	assert.Nil(s.T(), s.st.StoreWithdrawalRequest(context.Background(), testWithdrawalRequest(project.Id, 2)))
+
+	requests, err := s.st.FindWithdrawalRequests(context.Background(), storage.WithdrawalRequestFilter{ProjectId: &project.Id, NotWithdrawn: true})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), requests, 2)
+
+	// withdraw the first request
+	withdrawn := uint64(3)
+	requests[0].WithdrawEpoch = &withdrawn
+	assert.Nil(s.T(), s.st.UpdateWithdrawalRequest(context.Background(), &requests[0]))
+
+	requests, err = s.st.FindWithdrawalRequests(context.Background(), storage.WithdrawalRequestFilter{Contract: testRegistry, NotWithdrawn: true})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), requests, 1)
+	assert.EqualValues(s.T(), 2, requests[0].RequestEpoch)
+
+	epoch := uint64(1)
+	requests, err = s.st.FindWithdrawalRequests(context.Background(), storage.WithdrawalRequestFilter{RequestEpoch: &epoch})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), requests, 1)
+	assert.EqualValues(s.T(), 3, *requests[0].WithdrawEpoch)
+
+	// requests are updated by their id only
+	assert.NotNil(s.T(), s.st.UpdateWithdrawalRequest(context.Background(), testWithdrawalRequest(project.Id, 1)))
+}
+
+func (s *Suite) TestContractEvents() {
+	at := func(block uint64, logIndex uint64) storage.EventPosition {
+		return storage.EventPosition{BlockNumber: block, LogIndex: logIndex}
+	}
+	// the events are stored out of order
+	for _, pos := range []storage.EventPosition{at(20, 1), at(10, 0), at(20, 0), at(30, 2), at(10, 4)} {
+		assert.Nil(s.T(), s.st.StoreContractEvent(context.Background(), &types.ContractEvent{
+			ContractAddress: testRegistry,
+			BlockNumber:     pos.BlockNumber,
+			TxHash:          &types.Hash{Hash: common.BigToHash(new(big.Int).SetUint64(pos.BlockNumber))},
+			LogIndex:        pos.LogIndex,
+			Data:            "0x",
+			Outcome:         types.ContractEventProcessed,
+		}))
+	}
+
+	// the events are paged in the block and log index order
+	var pages [][]storage.EventPosition
+	filter := storage.ContractEventFilter{Contract: testRegistry, Limit: 2}
+	for {
+		events, err := s.st.FindContractEvents(context.Background(), filter)
+		assert.Nil(s.T(), err)
+		if len(events) == 0 {
+			break
+		}
+		var page []storage.EventPosition
+		for _, e := range events {
+			page = append(page, at(e.BlockNumber, e.LogIndex))
+		}
+		pages = append(pages, page)
+		filter.After = &page[len(page)-1]
+	}
+	assert.Equal(s.T(), [][]storage.EventPosition{
+		{at(10, 0), at(10, 4)},
+		{at(20, 0), at(20, 1)},
+		{at(30, 2)},
+	}, pages)
+}
+
+func (s *Suite) TestState() {
+	block, err := s.st.LastProcessedBlock(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), block)
+	epoch, err := s.st.CurrentEpoch(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), epoch)
+
+	assert.Nil(s.T(), s.st.UpdateLastProcessedBlock(context.Background(), 10))
+	assert.Nil(s.T(), s.st.UpdateCurrentEpoch(context.Background(), 2))
+
+	block, err = s.st.LastProcessedBlock(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 10, block)
+	epoch, err = s.st.CurrentEpoch(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 2, epoch)
+
+	checkpoint, err := s.st.BootstrapCheckpoint(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), checkpoint)
+	assert.Nil(s.T(), s.st.UpdateBootstrapCheckpoint(context.Background(), 5000))
+	checkpoint, err = s.st.BootstrapCheckpoint(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 5000, checkpoint)
+}
+
+func (s *Suite) TestStats() {
+	stats, err := s.st.Stats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), stats.TotalAmountCollected.ToInt().Sign())
+	assert.Zero(s.T(), stats.TotalTransactionsCount)
+
+	amount := testBig("123456789012345678901234567890").ToInt()
+	assert.Nil(s.T(), s.st.IncreaseTotalAmountCollected(context.Background(), amount))
+	assert.Nil(s.T(), s.st.IncreaseTotalAmountCollected(context.Background(), amount))
+	assert.Nil(s.T(), s.st.IncreaseTotalAmountClaimed(context.Background(), big.NewInt(5)))
+	assert.Nil(s.T(), s.st.IncreaseTotalTransactionsCount(context.Background(), 2))
+	assert.Nil(s.T(), s.st.IncreaseTotalTransactionsCount(context.Background(), 3))
+
+	stats, err = s.st.Stats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "246913578024691357802469135780", stats.TotalAmountCollected.ToInt().String())
+	assert.EqualValues(s.T(), 5, stats.TotalAmountClaimed.ToInt().Int64())
+	assert.EqualValues(s.T(), 5, stats.TotalTransactionsCount)
+}
+
+func (s *Suite) TestAtomic() {
+	// all changes are discarded on failure
+	failure := errors.New("failure")
+	err := s.st.Atomic(context.Background(), func(ctx context.Context, st storage.Storage) error {
+		assert.Nil(s.T(), st.StoreProject(ctx, testProject(1)))
+		assert.Nil(s.T(), st.UpdateLastProcessedBlock(ctx, 10))
+		return failure
+	})
+	assert.NotNil(s.T(), err)
+	projects, err := s.st.FindProjects(context.Background(), storage.ProjectFilter{})
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), projects)
+	block, err := s.st.LastProcessedBlock(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), block)
+
+	// all changes are applied on success, the nested call joins the running one
+	err = s.st.Atomic(context.Background(), func(ctx context.Context, st storage.Storage) error {
+		project := testProject(1)
+		if err := st.StoreProject(ctx, project); err != nil {
+			return err
+		}
+		return st.Atomic(ctx, func(ctx context.Context, st storage.Storage) error {
+			if err := st.StoreTransaction(ctx, testTransaction(project.Id, 1, 1)); err != nil {
+				return err
+			}
+			return st.UpdateLastProcessedBlock(ctx, 10)
+		})
+	})
+	assert.Nil(s.T(), err)
+	projects, err = s.st.FindProjects(context.Background(), storage.ProjectFilter{})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 1)
+	trxs, err := s.st.FindTransactions(context.Background(), storage.TransactionFilter{})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), trxs, 1)
+	block, err = s.st.LastProcessedBlock(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 10, block)
+
+	// the failure of the nested call discards the changes of the outer one
+	err = s.st.Atomic(context.Background(), func(ctx context.Context, st storage.Storage) error {
+		if err := st.StoreProject(ctx, testProject(2)); err != nil {
+			return err
+		}
+		return st.Atomic(ctx, func(ctx context.Context, st storage.Storage) error {
+			return st.StoreProject(ctx, testProject(1))
+		})
+	})
+	assert.NotNil(s.T(), err)
+	projects, err = s.st.FindProjects(context.Background(), storage.ProjectFilter{})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 1)
+}
+
+// storeProject stores a test project with the given id.
+func (s *Suite) storeProject(projectId uint64) *types.Project {
+	project := testProject(projectId)
+	assert.Nil(s.T(), s.st.StoreProject(context.Background(), project))
+	return project
+}
+
+// testProject returns a project registered in the test registry.
+func testProject(projectId uint64) *types.Project {
+	return &types.Project{
+		ProjectId:       projectId,
+		ContractAddress: testRegistry,
+		OwnerAddress:    testOwner,
+		ReceiverAddress: testOwner,
+		ActiveFromEpoch: 1,
+	}
+}
+
+// testProjectContract returns the test contract added to the project at the given block.
+func testProjectContract(projectId int64, block uint64) *types.ProjectContract {
+	return &types.ProjectContract{
+		ProjectId:    projectId,
+		Address:      testContract,
+		AddedAtEpoch: 1,
+		AddedAtBlock: block,
+	}
+}
+
+// testProjectHistory returns the change of the project recipient made by the given log record.
+func testProjectHistory(projectId int64, block uint64, logIndex uint64, epoch uint64, recipient string) *types.ProjectHistory {
+	return &types.ProjectHistory{
+		ProjectId:   projectId,
+		Attribute:   types.ProjectAttributeRecipient,
+		NewValue:    recipient,
+		BlockNumber: block,
+		LogIndex:    logIndex,
+		Epoch:       epoch,
+		TxHash:      &types.Hash{Hash: common.BigToHash(new(big.Int).SetUint64(block))},
+	}
+}
+
+// testTransaction returns a transaction of the project calling the test contract.
+func testTransaction(projectId int64, hash uint64, epoch uint64) *types.Transaction {
+	blockNumber := hexutil.Uint64(hash)
+	gasUsed := hexutil.Uint64(21000)
+	return &types.Transaction{
+		ProjectId:         projectId,
+		ContractAddress:   testContract,
+		Hash:              testHash(hash),
+		BlockNumber:       &blockNumber,
+		Epoch:             hexutil.Uint64(epoch),
+		Timestamp:         time.Unix(1678285698, 0),
+		From:              testOwner,
+		To:                testContract,
+		GasUsed:           &gasUsed,
+		GasPrice:          testBig("1000000000"),
+		MaxFeePerGas:      testBig("2000000000"),
+		EffectiveGasPrice: testBig("1000000000"),
+		GasPriceSource:    types.GasPriceSourceReceipt,
+		RewardToClaim:     testBig("123456789012345678901234567890"),
+	}
+}
+
+// testWithdrawalRequest returns a pending withdrawal request of the project.
+func testWithdrawalRequest(projectId int64, epoch uint64) *types.WithdrawalRequest {
+	return &types.WithdrawalRequest{
+		ProjectId:        projectId,
+		ContractAddress:  testRegistry,
+		RequestEpoch:     epoch,
+		Amount:           testBig("1000"),
+		RecipientAddress: testOwner,
+	}
+}
+
+// testHash returns a transaction hash derived from the given number.
+func testHash(n uint64) *types.Hash {
+	return &types.Hash{Hash: common.BigToHash(new(big.Int).SetUint64(n))}
+}
+
+// testBig returns a big value of the given decimal number.
+func testBig(value string) *types.Big {
+	v, _ := new(big.Int).SetString(value, 10)
+	return &types.Big{Big: hexutil.Big(*v)}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 // TransactionQuery returns a new transaction query builder.
-func (repo *Repository) TransactionQuery() db.TransactionQueryBuilder {
+func (repo *Repository) TransactionQuery() *db.TransactionQueryBuilder {
 	return repo.db.TransactionQuery(context.Background())
 }
 
@@ -22,3 +22,18 @@ func (repo *Repository) Transaction(hash *common.Hash) (*types.Transaction, erro
 func (repo *Repository) TraceTransaction(hash common.Hash) ([]types.TransactionTrace, error) {
 	return repo.tracer.TraceTransaction(hash)
 }
+
+// EnsureTransactionPartitions creates the missing transaction partitions of the given number of epochs,
+// so all the epochs from the given epoch up to the given one, excluding it, can be stored.
+func (repo *Repository) EnsureTransactionPartitions(fromEpoch uint64, toEpoch uint64, size uint64) ([]db.TransactionPartition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	return repo.db.EnsureTransactionPartitions(ctx, fromEpoch, toEpoch, size)
+}
+
+// ExpireTransactionPartitions detaches, or drops, the transaction partitions holding only epochs before the given one.
+func (repo *Repository) ExpireTransactionPartitions(beforeEpoch uint64, detach bool) ([]db.TransactionPartition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeoutDuration)
+	defer cancel()
+	return repo.db.ExpireTransactionPartitions(ctx, beforeEpoch, detach)
+}
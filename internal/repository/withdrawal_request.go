@@ -7,7 +7,7 @@ import (
 )
 
 // WithdrawalRequestQuery returns a new withdrawal request query builder.
-func (repo *Repository) WithdrawalRequestQuery() db.WithdrawalRequestQueryBuilder {
+func (repo *Repository) WithdrawalRequestQuery() *db.WithdrawalRequestQueryBuilder {
 	return repo.db.WithdrawalRequestQuery(context.Background())
 }
 
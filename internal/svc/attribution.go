@@ -0,0 +1,141 @@
+package svc
+
+import (
+	"fmt"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultAttribution represents the attribution used when none is configured.
+var defaultAttribution = config.Attribution{DelegateCall: config.AttributeDelegateCallToProxy}
+
+// checkAttribution checks the attribution configuration is valid.
+func checkAttribution(cfg *config.Attribution) error {
+	switch cfg.DelegateCall {
+	case config.AttributeDelegateCallToProxy, config.AttributeDelegateCallToImplementation:
+		return nil
+	default:
+		return fmt.Errorf("unknown delegate call attribution %q", cfg.DelegateCall)
+	}
+}
+
+// attributionTarget returns the contract the gas of the given trace frame is attributed to.
+// False is returned if the frame is excluded from the attribution.
+func attributionTarget(cfg *config.Attribution, trace *types.TransactionTrace) (common.Address, bool) {
+	if trace.Action == nil {
+		return common.Address{}, false
+	}
+	switch trace.CallType() {
+	case types.CallTypeCreate:
+		// the constructor runs in the context of the created contract
+		created := trace.Created()
+		if cfg.ExcludeCreations || created == nil {
+			return common.Address{}, false
+		}
+		return *created, true
+	case types.CallTypeStaticCall:
+		if cfg.ExcludeStaticCalls {
+			return common.Address{}, false
+		}
+	case types.CallTypeDelegateCall, types.CallTypeCallCode:
+		// the implementation code runs in the context of the proxy, which is the caller of the frame
+		if cfg.DelegateCall == config.AttributeDelegateCallToProxy {
+			if trace.Action.From == nil {
+				return common.Address{}, false
+			}
+			return *trace.Action.From, true
+		}
+	}
+	if trace.Type == types.TraceTypeSuicide || trace.Action.To == nil {
+		return common.Address{}, false
+	}
+	return *trace.Action.To, true
+}
+
+// attributedFrame represents the self gas of a single call frame attributed to a contract.
+type attributedFrame struct {
+	Path     string         `json:"path"`
+	CallType string         `json:"callType"`
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Target   common.Address `json:"target"`
+	Selector *string        `json:"selector,omitempty"`
+	GasUsed  uint64         `json:"gasUsed"`
+}
+
+// attributeFrames walks the whole call tree of a transaction and returns every frame attributable to a contract
+// along with its self gas.
+func attributeFrames(cfg *config.Attribution, traces []types.TransactionTrace) []attributedFrame {
+	selfGas := frameSelfGas(traces)
+	// attribute the self gas of every alive frame in the order of the trace
+	var out []attributedFrame
+	for i := range traces {
+		trace := &traces[i]
+		gas, ok := selfGas[trace.StringPath()]
+		if !ok {
+			continue
+		}
+		target, ok := attributionTarget(cfg, trace)
+		if !ok {
+			continue
+		}
+		frame := attributedFrame{
+			Path:     trace.StringPath(),
+			CallType: trace.CallType(),
+			Target:   target,
+			Selector: trace.Action.Selector(),
+			GasUsed:  gas,
+		}
+		if trace.Action.From != nil {
+			frame.From = *trace.Action.From
+		}
+		if created := trace.Created(); created != nil {
+			frame.To = *created
+		} else if trace.Action.To != nil {
+			frame.To = *trace.Action.To
+		}
+		out = append(out, frame)
+	}
+	return out
+}
+
+// frameSelfGas returns the self gas of every successful frame of the call tree by the frame path, i.e. the gas used
+// by the frame without the gas of its successful sub-calls. Failed frames and their subtrees are reverted,
+// so their gas stays with the nearest successful ancestor.
+func frameSelfGas(traces []types.TransactionTrace) map[string]uint64 {
+	// index frames by their path, a frame is alive if neither it nor any of its ancestors failed
+	frames := make(map[string]*types.TransactionTrace, len(traces))
+	for i := range traces {
+		frames[traces[i].StringPath()] = &traces[i]
+	}
+	alive := func(trace *types.TransactionTrace) bool {
+		for depth := len(trace.TraceAddress); depth >= 0; depth-- {
+			frame, ok := frames[types.TracePath(trace.TraceAddress[:depth])]
+			if !ok || frame.Error != nil {
+				return false
+			}
+		}
+		return true
+	}
+	// subtract the gas of successful sub-calls from their parents
+	selfGas := make(map[string]uint64, len(traces))
+	for i := range traces {
+		if alive(&traces[i]) {
+			selfGas[traces[i].StringPath()] = traces[i].GasUsed()
+		}
+	}
+	for i := range traces {
+		parent := traces[i].ParentStringPath()
+		if _, ok := selfGas[traces[i].StringPath()]; parent == nil || !ok {
+			continue
+		}
+		// the gas of the sub-calls can not exceed the parent gas in a consistent trace
+		if used := traces[i].GasUsed(); selfGas[*parent] > used {
+			selfGas[*parent] -= used
+		} else {
+			selfGas[*parent] = 0
+		}
+	}
+	return selfGas
+}
@@ -0,0 +1,150 @@
+package svc
+
+import (
+	"encoding/json"
+	"flag"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden rewrites the golden files by the current attribution output
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// attributionTraces represents a proxied call with a static call, a contract creation and a self-destruct
+const attributionTraces = `[
+	{"type": "call", "action": {"callType": "call", "from": "0x00000000000000000000000000000000000000aa", "to": "0x0000000000000000000000000000000000000001", "value": "0x10", "input": "0xa9059cbb0000"}, "result": {"gasUsed": "0x9000"}, "traceAddress": []},
+	{"type": "call", "action": {"callType": "delegatecall", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000002", "value": "0x0", "input": "0xa9059cbb0000"}, "result": {"gasUsed": "0x5000"}, "traceAddress": [0]},
+	{"type": "call", "action": {"callType": "staticcall", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000003", "value": "0x0", "input": "0x"}, "result": {"gasUsed": "0x1000"}, "traceAddress": [0, 0]},
+	{"type": "create", "action": {"from": "0x0000000000000000000000000000000000000001", "value": "0x0", "gas": "0x2000", "init": "0x6080"}, "result": {"gasUsed": "0x800", "address": "0x0000000000000000000000000000000000000004", "code": "0x"}, "traceAddress": [0, 1]},
+	{"type": "suicide", "action": {"address": "0x0000000000000000000000000000000000000004", "refundAddress": "0x00000000000000000000000000000000000000aa", "balance": "0x0"}, "result": null, "traceAddress": [0, 2]}
+]`
+
+// TestTraceDecoding tests call types, selectors, values and created addresses are decoded from traces
+func TestTraceDecoding(t *testing.T) {
+	var traces []types.TransactionTrace
+	assert.Nil(t, json.Unmarshal([]byte(attributionTraces), &traces))
+	assert.Len(t, traces, 5)
+
+	assert.Equal(t, types.CallTypeCall, traces[0].CallType())
+	assert.Equal(t, "0xa9059cbb", *traces[0].Action.Selector())
+	assert.EqualValues(t, 16, traces[0].Action.Value.ToInt().Int64())
+	assert.Nil(t, traces[0].Created())
+	assert.EqualValues(t, 0x9000, traces[0].GasUsed())
+
+	assert.Equal(t, types.CallTypeDelegateCall, traces[1].CallType())
+	assert.Equal(t, types.CallTypeStaticCall, traces[2].CallType())
+	assert.Nil(t, traces[2].Action.Selector())
+
+	assert.Equal(t, types.CallTypeCreate, traces[3].CallType())
+	assert.Nil(t, traces[3].Action.To)
+	assert.Equal(t, common.HexToAddress("0x04"), *traces[3].Created())
+
+	assert.Equal(t, types.TraceTypeSuicide, traces[4].Type)
+	assert.Nil(t, traces[4].Result)
+	assert.Zero(t, traces[4].GasUsed())
+}
+
+// TestAttributionTarget tests the gas of call frames is attributed according to the attribution configuration
+func TestAttributionTarget(t *testing.T) {
+	var traces []types.TransactionTrace
+	assert.Nil(t, json.Unmarshal([]byte(attributionTraces), &traces))
+	none := common.Address{}
+
+	for _, test := range []struct {
+		cfg     config.Attribution
+		targets []common.Address
+	}{
+		{
+			cfg:     defaultAttribution,
+			targets: []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x01"), common.HexToAddress("0x03"), common.HexToAddress("0x04"), none},
+		},
+		{
+			cfg:     config.Attribution{DelegateCall: config.AttributeDelegateCallToImplementation, ExcludeStaticCalls: true, ExcludeCreations: true},
+			targets: []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), none, none, none},
+		},
+	} {
+		for i := range traces {
+			target, ok := attributionTarget(&test.cfg, &traces[i])
+			assert.Equal(t, test.targets[i] != none, ok, i)
+			assert.Equal(t, test.targets[i], target, i)
+		}
+	}
+
+	assert.Nil(t, checkAttribution(&defaultAttribution))
+	assert.NotNil(t, checkAttribution(&config.Attribution{DelegateCall: "caller"}))
+}
+
+// TestAttributeFramesGolden tests the call tree of recorded traces is attributed as captured in the golden files
+func TestAttributeFramesGolden(t *testing.T) {
+	fixtures, err := filepath.Glob("testdata/attribution/*.json")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, fixtures)
+	for _, fixture := range fixtures {
+		if strings.HasSuffix(fixture, ".golden.json") {
+			continue
+		}
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			assert.Nil(t, err)
+			var traces []types.TransactionTrace
+			assert.Nil(t, json.Unmarshal(data, &traces))
+			out, err := json.MarshalIndent(attributeFrames(&defaultAttribution, traces), "", "  ")
+			assert.Nil(t, err)
+			golden := strings.TrimSuffix(fixture, ".json") + ".golden.json"
+			if *updateGolden {
+				assert.Nil(t, os.WriteFile(golden, append(out, '\n'), 0644))
+			}
+			expected, err := os.ReadFile(golden)
+			assert.Nil(t, err)
+			assert.JSONEq(t, string(expected), string(out))
+		})
+	}
+}
+
+// TestAttributeFramesSelfGas tests the self gas of the frames sums up to the gas of the top level call
+func TestAttributeFramesSelfGas(t *testing.T) {
+	data, err := os.ReadFile("testdata/attribution/router.json")
+	assert.Nil(t, err)
+	var traces []types.TransactionTrace
+	assert.Nil(t, json.Unmarshal(data, &traces))
+	var total uint64
+	for _, frame := range attributeFrames(&defaultAttribution, traces) {
+		total += frame.GasUsed
+	}
+	assert.Equal(t, traces[0].GasUsed(), total)
+	// static calls excluded from the attribution are not rewarded to anyone
+	total = 0
+	for _, frame := range attributeFrames(&config.Attribution{DelegateCall: config.AttributeDelegateCallToProxy, ExcludeStaticCalls: true}, traces) {
+		assert.NotEqual(t, types.CallTypeStaticCall, frame.CallType)
+		total += frame.GasUsed
+	}
+	assert.Equal(t, traces[0].GasUsed()-3000-2500, total)
+}
+
+// TestFrameSelfGasFailedSubtree tests failed frames and their sub-calls are left out of the self gas breakdown
+func TestFrameSelfGasFailedSubtree(t *testing.T) {
+	data, err := os.ReadFile("testdata/attribution/failed_subtree.json")
+	assert.Nil(t, err)
+	var traces []types.TransactionTrace
+	assert.Nil(t, json.Unmarshal(data, &traces))
+	selfGas := frameSelfGas(traces)
+	for _, path := range []string{"0", "0,0", "1,0"} {
+		_, ok := selfGas[path]
+		assert.False(t, ok, path)
+	}
+	assert.Equal(t, uint64(50000), selfGas[""])
+	assert.Equal(t, uint64(20000), selfGas["1"])
+}
+
+// TestCalculateReward tests the reward is the policy share of the paid fee
+func TestCalculateReward(t *testing.T) {
+	assert.Equal(t, big.NewInt(150_000_000_000_000), calculateReward(big.NewInt(1_000_000_000), 1_000_000))
+	assert.Zero(t, calculateReward(big.NewInt(6), 1).Sign())
+}
@@ -3,9 +3,11 @@ package svc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"ftm-gas-monetization/internal/config"
 	"ftm-gas-monetization/internal/notifier"
-	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/repository/storage"
 	"ftm-gas-monetization/internal/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -16,20 +18,43 @@ import (
 // rewardPercentage represents the percentage of the reward to be paid to the project.
 const rewardsPercentage = 15
 
+// transactionBatchSize is the maximal number of rewarded call frames written to the database at once.
+const transactionBatchSize = 1000
+
+// storageTimeoutDuration represents the maximal duration of the storage operations of a single block.
+const storageTimeoutDuration = 30 * time.Second
+
 // blkDispatcher implements a service responsible for processing new blocks on the blockchain.
 type blkDispatcher struct {
 	service
+	storage       storage.Storage
 	notifier      notifier.Notifier
+	attribution   *config.Attribution
+	partitions    *config.Partitions
 	inBlock       chan *types.Block
 	outDispatched chan uint64
 	// topics represents a map of topics to their respective event handlers.
 	topics map[common.Hash]EventHandler
+	// events represents the registry of the gas monetization contract events.
+	events *eventRegistry
+	// deployments represents a map of gas monetization contracts to their tracked state.
+	deployments map[common.Address]*gasMonetization
+	// currentEpochId represents the current epoch id.
+	currentEpochId uint64
+	// bootstrapping is set while the registry events are replayed without the rewards data.
+	bootstrapping bool
+}
+
+// gasMonetization represents the tracked state of a single gas monetization contract deployment.
+type gasMonetization struct {
+	// address represents the address of the gas monetization contract.
+	address types.Address
+	// startFromBlock represents the first block the contract events are processed in.
+	startFromBlock uint64
 	// watchedContracts represents a map of contracts to their respective project instances.
 	watchedContracts map[common.Address]*types.Project
 	// watchedProjectIds represents a map of projects where key is `project_id` provided by contract.
 	watchedProjectIds map[uint64]*types.Project
-	// currentEpochId represents the current epoch id.
-	currentEpochId uint64
 }
 
 // name returns the name of the service used by orchestrator.
@@ -41,6 +66,20 @@ func (bld *blkDispatcher) name() string {
 func (bld *blkDispatcher) init() {
 	bld.sigStop = make(chan struct{})
 	bld.outDispatched = make(chan uint64, blsBlockBufferCapacity)
+	if bld.attribution == nil {
+		bld.attribution = &defaultAttribution
+	}
+	if err := checkAttribution(bld.attribution); err != nil {
+		bld.log.Fatalf("invalid gas attribution; %s", err.Error())
+	}
+	// the registry state of an interrupted bootstrap is incomplete
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
+	if checkpoint, err := bld.storage.BootstrapCheckpoint(ctx); err != nil {
+		bld.log.Fatalf("failed to get bootstrap checkpoint; %s", err.Error())
+	} else if checkpoint > 0 {
+		bld.log.Fatalf("bootstrap interrupted at block #%d has to be finished first", checkpoint)
+	}
 	bld.initializeTopics()
 	bld.initializeTrackedData()
 }
@@ -102,52 +141,80 @@ func (bld *blkDispatcher) process(blk *types.Block) bool {
 	return true
 }
 
-// processTxs loops all the transactions in the block and process them.
+// processTxs loops all the transactions in the block and process them. A late block of an epoch sealed already
+// is recorded in its own epoch and the rewards of its transactions are added to the sealed epoch right away.
 func (bld *blkDispatcher) processTxs(blk *types.Block) bool {
-	if uint64(blk.Epoch) < bld.currentEpochId {
-		bld.log.Debugf("block #%d is from an old epoch, skipping", blk.Number)
-		return true
-	}
-	if blk.Txs == nil || len(blk.Txs) == 0 {
+	// the first block of a new epoch closes the previous one, even if empty
+	newEpoch := uint64(blk.Epoch) > bld.currentEpochId
+	late := uint64(blk.Epoch) < bld.currentEpochId
+	if !newEpoch && (blk.Txs == nil || len(blk.Txs) == 0) {
 		bld.log.Debugf("empty block #%d processed", blk.Number)
 		return true
 	}
-	// process all data in database transaction to ensure all transactions are processed or none
-	err := bld.repo.DatabaseTransaction(func(ctx context.Context, db *db.Db) error {
+	// process all data atomically to ensure all transactions are processed or none
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
+	err := bld.atomic(ctx, func(ctx context.Context, st storage.Storage) error {
+		if late {
+			processed, err := bld.lateBlockProcessed(ctx, st, blk)
+			if err != nil || processed {
+				return err
+			}
+			// the changes made by the block belong to its own epoch
+			current := bld.currentEpochId
+			bld.currentEpochId = uint64(blk.Epoch)
+			defer func() { bld.currentEpochId = current }()
+		}
+		// we moved to a new epoch, close the previous one
+		if newEpoch {
+			if err := bld.closeEpoch(ctx, st, blk); err != nil {
+				return fmt.Errorf("failed to close epoch %d: %s", bld.currentEpochId, err.Error())
+			}
+		}
+		// rewarded call frames of the block are written at once
+		var rewarded []types.Transaction
+		batch := st.NewTransactionBatch(transactionBatchSize)
 		for _, th := range blk.Txs {
 			trx := bld.load(blk, th)
 			if trx == nil {
 				return fmt.Errorf("failed to load transaction %s", th.String())
 			}
-			// we moved to a new epoch, store the previous one
-			if uint64(blk.Epoch) > bld.currentEpochId {
-				if err := bld.storePreviousEpoch(ctx, db, uint64(blk.Epoch)); err != nil {
-					return fmt.Errorf("failed to store previous epoch: %s", err.Error())
-				}
-			}
 			// store transaction into database
 			trx.Epoch = blk.Epoch
-			if err := bld.storeTransaction(ctx, db, trx); err != nil {
+			frames, err := bld.storeTransaction(ctx, batch, trx)
+			if err != nil {
 				return fmt.Errorf("failed to store transaction: %s", err.Error())
 			}
+			for _, t := range frames {
+				rewarded = append(rewarded, *t)
+			}
 			// process logs
 			if trx.Logs != nil && len(trx.Logs) > 0 {
 				for _, log := range trx.Logs {
-					if log.Address != bld.repo.GasMonetizationAddress() {
+					gm, ok := bld.deployments[log.Address]
+					if !ok || uint64(blk.Number) < gm.startFromBlock {
 						continue
 					}
-					handler, ok := bld.topics[log.Topics[0]]
-					if ok && log.BlockNumber == uint64(blk.Number) {
-						bld.log.Infof("known topic %s found, processing", log.Topics[0].String())
-						if err := handler(ctx, &log, db); err != nil {
-							return err
-						}
+					if log.BlockNumber != uint64(blk.Number) {
+						continue
+					}
+					if err := bld.processLog(ctx, gm, &log, st); err != nil {
+						return err
 					}
 				}
 			}
 		}
+		if err := batch.Flush(ctx); err != nil {
+			return fmt.Errorf("failed to store transactions: %s", err.Error())
+		}
+		// the rewards of the sealed epoch were added already, the late block adds its own
+		if late {
+			if err := bld.addRewards(ctx, st, rewarded); err != nil {
+				return fmt.Errorf("failed to add rewards of late block #%d: %s", blk.Number, err.Error())
+			}
+		}
 		// update last processed block number, so we can continue from here
-		if err := db.UpdateLastProcessedBlock(ctx, uint64(blk.Number)); err != nil {
+		if err := st.UpdateLastProcessedBlock(ctx, uint64(blk.Number)); err != nil {
 			return err
 		}
 		return nil
@@ -161,37 +228,110 @@ func (bld *blkDispatcher) processTxs(blk *types.Block) bool {
 	return true
 }
 
-// storePreviousEpoch stores the previous epoch data in the database.
-func (bld *blkDispatcher) storePreviousEpoch(ctx context.Context, db *db.Db, newEpochId uint64) error {
+// lateBlockProcessed checks if the given block of a sealed epoch was processed already. Blocks are processed
+// in order, so a block up to the last processed one is a repeated one and is skipped.
+func (bld *blkDispatcher) lateBlockProcessed(ctx context.Context, st storage.Storage, blk *types.Block) (bool, error) {
+	last, err := st.LastProcessedBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	if uint64(blk.Number) <= last {
+		bld.log.Noticef("block #%d of sealed epoch %d was processed already, skipping", blk.Number, uint64(blk.Epoch))
+		return true, nil
+	}
+	bld.log.Warningf("late block #%d of sealed epoch %d, adding its rewards to the epoch", blk.Number, uint64(blk.Epoch))
+	return false, nil
+}
+
+// atomic runs the given function over the storage applying all its changes at once. If an event handler
+// failed, its raw event is stored along with the failure after the changes are discarded, so the failure
+// survives the rollback.
+func (bld *blkDispatcher) atomic(ctx context.Context, fn func(context.Context, storage.Storage) error) error {
+	err := bld.storage.Atomic(ctx, fn)
+	var failed *failedEventError
+	if errors.As(err, &failed) {
+		// the context of the transaction may be over already
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+		defer cancel()
+		if err := bld.storage.StoreContractEvent(ctx, failed.event); err != nil {
+			bld.log.Errorf("failed to store failed event #%d/#%d; %s", failed.event.BlockNumber, failed.event.LogIndex, err.Error())
+		}
+	}
+	return err
+}
+
+// closeEpoch seals the current epoch and opens the epoch of the given block, the first block of the epoch observed.
+// The rewards of the sealed epoch are stored only once, so the epoch can be closed again when the block is retried.
+func (bld *blkDispatcher) closeEpoch(ctx context.Context, st storage.Storage, blk *types.Block) error {
+	newEpochId := uint64(blk.Epoch)
+	blkTime := time.Unix(int64(blk.TimeStamp), 0)
+	// there is no epoch to be closed when the first block is processed
+	if bld.currentEpochId > 0 {
+		sealed, err := st.SealEpoch(ctx, bld.currentEpochId, uint64(blk.Number)-1, blkTime)
+		if err != nil {
+			return err
+		}
+		if !sealed {
+			bld.log.Noticef("epoch %d was sealed already", bld.currentEpochId)
+		} else if err := bld.storePreviousEpoch(ctx, st); err != nil {
+			return fmt.Errorf("failed to store previous epoch: %s", err.Error())
+		}
+	}
+	if err := st.OpenEpoch(ctx, newEpochId, uint64(blk.Number), blkTime); err != nil {
+		return err
+	}
 	// update the current epoch id
-	if err := db.UpdateCurrentEpoch(ctx, newEpochId); err != nil {
+	if err := st.UpdateCurrentEpoch(ctx, newEpochId); err != nil {
 		return err
 	}
-	// map for temporarily storing projects to be updated
-	projects := make(map[int64]*types.Project)
-	var transactionsCount uint64 = 0
-	totalCollected := big.NewInt(0)
+	// make sure the transactions of the new epoch can be stored
+	if p, ok := st.(storage.Partitioned); ok {
+		if err := p.PrepareTransactionPartitions(ctx, newEpochId, newEpochId+1, bld.partitions.Epochs); err != nil {
+			return err
+		}
+	}
+	// set the new epoch id
+	bld.currentEpochId = newEpochId
+	return nil
+}
+
+// storePreviousEpoch stores the rewards of the current epoch, which is over, in the database.
+func (bld *blkDispatcher) storePreviousEpoch(ctx context.Context, st storage.Storage) error {
 	// get all transactions for the previous epoch and update generated rewards and number of transactions
-	tq := db.TransactionQuery(ctx)
-	txs, err := tq.WhereEpoch(bld.currentEpochId).GetAll()
+	txs, err := st.FindTransactions(ctx, storage.TransactionFilter{Epoch: &bld.currentEpochId})
 	if err != nil {
 		return err
 	}
+	return bld.addRewards(ctx, st, txs)
+}
+
+// addRewards adds the rewards and the number of the given rewarded transactions to their projects and the totals.
+func (bld *blkDispatcher) addRewards(ctx context.Context, st storage.Storage, txs []types.Transaction) error {
+	// map for temporarily storing projects to be updated
+	projects := make(map[int64]*types.Project)
+	var transactionsCount uint64 = 0
+	totalCollected := big.NewInt(0)
+	var err error
+	// a transaction may be rewarded for multiple call frames, it is counted once per project and once in total
+	counted := make(map[common.Hash]map[int64]bool)
 	// loop all transactions from the previous epoch and update data
 	for _, trx := range txs {
-		transactionsCount += 1
+		if counted[trx.Hash.Hash] == nil {
+			counted[trx.Hash.Hash] = make(map[int64]bool)
+			transactionsCount += 1
+		}
 		totalCollected = totalCollected.Add(totalCollected, trx.RewardToClaim.ToInt())
 		project, exists := projects[trx.ProjectId]
 		if !exists {
-			pq := db.ProjectQuery(ctx)
-			project, err = pq.WhereId(trx.ProjectId).GetFirstOrFail()
+			project, err = findProjectById(ctx, st, trx.ProjectId)
 			if err != nil {
 				return err
 			}
 			// if project is watched, take it from the map so the fields are updated for log handler
-			watched, isWatched := bld.watchedProjectIds[project.ProjectId]
-			if isWatched {
-				project = watched
+			if gm, ok := bld.deployments[project.ContractAddress.Address]; ok {
+				if watched, isWatched := gm.watchedProjectIds[project.ProjectId]; isWatched {
+					project = watched
+				}
 			}
 			projects[trx.ProjectId] = project
 		}
@@ -210,101 +350,89 @@ func (bld *blkDispatcher) storePreviousEpoch(ctx context.Context, db *db.Db, new
 			project.RewardsToClaim = &types.Big{Big: hexutil.Big(*res)}
 		}
 		// increase number of transactions
-		project.TransactionsCount += 1
+		if !counted[trx.Hash.Hash][trx.ProjectId] {
+			counted[trx.Hash.Hash][trx.ProjectId] = true
+			project.TransactionsCount += 1
+		}
 	}
 	// increase the total amount collected
 	if totalCollected.Cmp(big.NewInt(0)) > 0 {
-		if err = db.IncreaseTotalAmountCollected(ctx, totalCollected); err != nil {
+		if err = st.IncreaseTotalAmountCollected(ctx, totalCollected); err != nil {
 			return err
 		}
 	}
 	// update the number of transactions
 	if transactionsCount > 0 {
-		if err = db.IncreaseTotalTransactionsCount(ctx, transactionsCount); err != nil {
+		if err = st.IncreaseTotalTransactionsCount(ctx, transactionsCount); err != nil {
 			return err
 		}
 	}
 	// loop through all projects and update the data
 	for _, project := range projects {
-		if err = db.UpdateProject(ctx, project); err != nil {
+		if err = st.UpdateProject(ctx, project); err != nil {
 			return err
 		}
 	}
-	// set the new epoch id
-	bld.currentEpochId = newEpochId
 	return nil
 }
 
-// storeTransaction stores a transaction in the repository.
-func (bld *blkDispatcher) storeTransaction(ctx context.Context, db *db.Db, trx *types.Transaction) error {
+// storeTransaction adds the rewarded call frames of a transaction to the batch to be stored and returns them.
+func (bld *blkDispatcher) storeTransaction(ctx context.Context, batch storage.TransactionBatch, trx *types.Transaction) ([]*types.Transaction, error) {
 	traceResult, err := bld.repo.TraceTransaction(trx.Hash.Hash)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if traceResult == nil || len(traceResult) == 0 {
-		return nil
+		return nil, nil
 	}
-	// map for storing gas used for each transaction
-	gasMap := make(map[string]*hexutil.Uint64)
 	// list of transactions to be stored
 	var transactions []*types.Transaction
-
-	// we need to iterate over all traces and subtract gas used from parents
-	// currently each transaction contains gas used for all sub-calls
-	for _, trace := range traceResult {
-		// if error occurred, skip
-		if trace.Error != nil {
-			continue
-		}
-		// get parent path
-		parent := trace.ParentStringPath()
-		// if parent exists, subtract gas used from parent
-		if parent != nil {
-			gas, exists := gasMap[*parent]
-			// if parent does not exist, skip (error occurred in parent)
-			if !exists {
-				continue
+	// walk the whole call tree, every frame of a watched contract is rewarded
+	for _, frame := range attributeFrames(bld.attribution, traceResult) {
+		// create new transaction for each contract deployment watching the receiver
+		for _, project := range bld.watchingProjects(frame.Target) {
+			gasUsed := hexutil.Uint64(frame.GasUsed)
+			t := &types.Transaction{
+				ProjectId:         project.Id,
+				ContractAddress:   project.ContractAddress,
+				Hash:              trx.Hash,
+				BlockHash:         trx.BlockHash,
+				BlockNumber:       trx.BlockNumber,
+				Epoch:             trx.Epoch,
+				Timestamp:         trx.Timestamp,
+				From:              &types.Address{Address: frame.From},
+				To:                &types.Address{Address: frame.To},
+				TraceAddress:      frame.Path,
+				CallType:          frame.CallType,
+				Selector:          frame.Selector,
+				GasUsed:           &gasUsed,
+				GasPrice:          trx.GasPrice,
+				EffectiveGasPrice: trx.EffectiveGasPrice,
+				BaseFee:           trx.BaseFee,
+				GasPriceSource:    trx.GasPriceSource,
 			}
-			// set new gas used for parent, so we keep pointer to the same value
-			*gas = hexutil.Uint64(uint64(*gas) - uint64(*trace.Result.GasUsed))
+			// add transaction to the list
+			transactions = append(transactions, t)
 		}
-		// set total gas used for given transaction
-		gasMap[trace.StringPath()] = trace.Result.GasUsed
-		// check whether we are interested in this transaction
-		// we are only interested in receiver address
-		if bld.watchedContracts[*trace.Action.To] == nil {
-			return nil
-		}
-		// create new transaction
-		t := &types.Transaction{
-			ProjectId:   bld.watchedContracts[*trace.Action.To].Id,
-			Hash:        trx.Hash,
-			BlockHash:   trx.BlockHash,
-			BlockNumber: trx.BlockNumber,
-			Epoch:       trx.Epoch,
-			Timestamp:   trx.Timestamp,
-			From:        &types.Address{Address: *trace.Action.From},
-			To:          &types.Address{Address: *trace.Action.To},
-			GasUsed:     gasMap[trace.StringPath()],
-			GasPrice:    trx.GasPrice,
-		}
-		// add transaction to the list
-		transactions = append(transactions, t)
 	}
 
 	// store all transactions
 	for _, t := range transactions {
 		// do final reward calculation on final gas amounts
-		total := new(big.Int).Mul(t.GasPrice.ToInt(), new(big.Int).SetUint64(uint64(*t.GasUsed)))
-		reward := new(big.Int).Mul(total, big.NewInt(rewardsPercentage))
-		finalReward := new(big.Int).Div(reward, big.NewInt(100))
-		t.RewardToClaim = &types.Big{Big: hexutil.Big(*finalReward)}
+		t.RewardToClaim = &types.Big{Big: hexutil.Big(*calculateReward(t.EffectiveGasPrice.ToInt(), uint64(*t.GasUsed)))}
 		// store transaction
-		if err := db.StoreTransaction(ctx, t); err != nil {
-			return err
+		if err := batch.Add(ctx, t); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return transactions, nil
+}
+
+// calculateReward calculates the reward of the given amount of gas paid at the given gas price.
+func calculateReward(gasPrice *big.Int, gasUsed uint64) *big.Int {
+	total := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasUsed))
+	reward := new(big.Int).Mul(total, big.NewInt(rewardsPercentage))
+	return reward.Div(reward, big.NewInt(100))
 }
 
 // load a transaction detail from repository, if possible.
@@ -315,14 +443,17 @@ func (bld *blkDispatcher) load(blk *types.Block, th *common.Hash) *types.Transac
 		bld.log.Errorf("transaction %s detail not available; %s", th.String(), err.Error())
 		return nil
 	}
-	// update time stamp using the block data
+	// update time stamp and the effective gas price using the block data
 	trx.Timestamp = time.Unix(int64(blk.TimeStamp), 0)
+	trx.ResolveEffectiveGasPrice(blk.BaseFee.ToInt())
 	return trx
 }
 
 // initializeCurrentEpoch initializes the current epoch.
 func (bld *blkDispatcher) initializeCurrentEpoch() {
-	epoch, err := bld.repo.CurrentEpoch()
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
+	epoch, err := bld.storage.CurrentEpoch(ctx)
 	if err != nil {
 		bld.log.Fatal("failed to get current epoch: %v", err)
 	}
@@ -340,26 +471,67 @@ func (bld *blkDispatcher) sendNotification(message string) {
 	}
 }
 
-// initializeProjects initializes the list of watched projects.
-func (bld *blkDispatcher) initializeProjects() {
-	bld.watchedContracts = make(map[common.Address]*types.Project)
-	bld.watchedProjectIds = make(map[uint64]*types.Project)
+// watchingProjects returns projects watching the given contract across all gas monetization deployments.
+func (bld *blkDispatcher) watchingProjects(addr common.Address) []*types.Project {
+	var projects []*types.Project
+	for _, gm := range bld.deployments {
+		if project, ok := gm.watchedContracts[addr]; ok {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// initializeDeployments initializes the list of gas monetization contract deployments
+// along with their watched projects.
+func (bld *blkDispatcher) initializeDeployments() {
+	bld.deployments = make(map[common.Address]*gasMonetization)
+	last, err := bld.repo.LastProcessedBlock()
+	if err != nil {
+		bld.log.Fatalf("failed to get last processed block: %v", err)
+	}
+	for _, addr := range bld.repo.GasMonetizationAddresses() {
+		start, err := bld.repo.GasMonetizationStartBlock(addr)
+		if err != nil {
+			bld.log.Fatalf("failed to get start block of contract %s: %v", addr.Hex(), err)
+		}
+		gm := &gasMonetization{
+			address:           types.Address{Address: addr},
+			startFromBlock:    start,
+			watchedContracts:  make(map[common.Address]*types.Project),
+			watchedProjectIds: make(map[uint64]*types.Project),
+		}
+		bld.initializeProjects(gm, last)
+		bld.deployments[addr] = gm
+	}
+}
+
+// initializeProjects initializes the list of watched projects of the given deployment.
+// Contracts are watched as they were members of their projects at the block the dispatcher
+// continues from, so replayed blocks are attributed as the chain state dictated at that point.
+func (bld *blkDispatcher) initializeProjects(gm *gasMonetization, block uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
 	// get all active projects
-	pq := bld.repo.ProjectQuery()
-	projects, err := pq.WhereActiveInEpoch(bld.currentEpochId).GetAll()
+	projects, err := bld.storage.FindProjects(ctx, storage.ProjectFilter{Contract: &gm.address, ActiveInEpoch: &bld.currentEpochId})
 	if err != nil {
 		bld.log.Fatal("failed to get active projects: %v", err)
 	}
-	for _, project := range projects {
-		pcq := bld.repo.ProjectContractQuery()
-		contracts, err := pcq.WhereIsApproved(true).WhereProjectId(project.Id).GetAll()
+	approved := true
+	for i := range projects {
+		project := &projects[i]
+		contracts, err := bld.storage.FindProjectContracts(ctx, storage.ProjectContractFilter{
+			ProjectId:     &project.Id,
+			Approved:      &approved,
+			MemberAtBlock: &block,
+		})
 		if err != nil {
 			bld.log.Fatal("failed to get project contracts: %v", err)
 		}
 		for _, c := range contracts {
 			// store reference to the project for fast lookups
-			bld.watchedContracts[c.Address.Address] = &project
-			bld.watchedProjectIds[project.ProjectId] = &project
+			gm.watchedContracts[c.Address.Address] = project
+			gm.watchedProjectIds[project.ProjectId] = project
 		}
 	}
 }
@@ -367,5 +539,5 @@ func (bld *blkDispatcher) initializeProjects() {
 // initializeTrackedData initializes the data tracked by the block dispatcher.
 func (bld *blkDispatcher) initializeTrackedData() {
 	bld.initializeCurrentEpoch()
-	bld.initializeProjects()
+	bld.initializeDeployments()
 }
@@ -73,6 +73,8 @@ type DispatcherTestSuite struct {
 	currentEpoch uint64
 	// blkDispatcher is the block dispatcher to test
 	blkDispatcher blkDispatcher
+	// metadataFetcher fetches metadata of the test projects
+	metadataFetcher metadataFetcher
 	// mock server for testing the data provider
 	mockServerUrl string
 }
@@ -99,16 +101,28 @@ func (s *DispatcherTestSuite) SetupSuite() {
 			log:  testLogger,
 			mgr:  New(&config.Config{}, s.testRepo, testLogger),
 		},
+		storage: s.testRepo.Storage(),
 	}
 	nm := new(notifier.MockNotifier)
 	nm.On("SendNotification", mock.Anything).Return(nil)
 	s.blkDispatcher.notifier = nm
+	s.blkDispatcher.partitions = &config.Partitions{Epochs: 1000}
 	s.blkDispatcher.init()
 	// make channel for receiving blocks
 	s.blkDispatcher.inBlock = make(chan *types.Block)
 	// make channel for receiving dispatched block ids
 	s.blkDispatcher.outDispatched = make(chan uint64)
 	s.blkDispatcher.run()
+	// initialize metadata fetcher, it is triggered by tests directly
+	s.metadataFetcher = metadataFetcher{
+		service: service{
+			repo: s.testRepo,
+			log:  testLogger,
+		},
+		cfg: &config.Metadata{Interval: 15, Timeout: 5, MaxSize: 64 * 1024, RetryDelay: 1, MaxRetryDelay: 10,
+			BatchSize: 10, AllowPrivateHosts: true},
+	}
+	s.metadataFetcher.init()
 }
 
 // SetupTest sets up the test
@@ -167,6 +181,7 @@ func (s *DispatcherTestSuite) TestAddProject() {
 	assert.EqualValues(s.T(), s.mockServerUrl+projectUrl, project.Url)
 	assert.EqualValues(s.T(), projectName, project.Name)
 	assert.EqualValues(s.T(), projectImageUrl, project.ImageUrl)
+	assert.EqualValues(s.T(), types.ProjectMetadataOk, project.MetadataStatus)
 	assert.Nil(s.T(), project.LastWithdrawalEpoch)
 	assert.EqualValues(s.T(), s.currentEpoch, project.ActiveFromEpoch)
 	assert.Nil(s.T(), project.ActiveToEpoch)
@@ -251,13 +266,18 @@ func (s *DispatcherTestSuite) TestRemoveContract() {
 	_, err = s.projectsManagerSession.RemoveProjectContract(new(big.Int).SetUint64(1), projectContracts[0].Address)
 	assert.Nil(s.T(), err)
 	// process the latest block
-	s.processBlock(s.getLatestBlock())
-	// assert the contract does not exist
-	_, err = pcq.GetFirstOrFail()
-	assert.NotNil(s.T(), err)
-	// assert the other contract still exists
+	blk := s.getLatestBlock()
+	s.processBlock(blk)
+	// assert the contract membership is closed, but the contract is kept
+	removed, err := pcq.GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.NotNil(s.T(), removed.RemovedAtBlock)
+	assert.EqualValues(s.T(), uint64(blk.Number), *removed.RemovedAtBlock)
+	assert.False(s.T(), removed.IsMemberAt(uint64(blk.Number)))
+	assert.True(s.T(), removed.IsMemberAt(uint64(blk.Number)-1))
+	// assert the other contract is still a member
 	pcq = s.testRepo.ProjectContractQuery()
-	_, err = pcq.WhereAddress(&projectContracts[1]).GetFirstOrFail()
+	_, err = pcq.WhereAddress(&projectContracts[1]).WhereNotRemoved().GetFirstOrFail()
 	assert.Nil(s.T(), err)
 }
 
@@ -274,6 +294,8 @@ func (s *DispatcherTestSuite) TestUpdateUri() {
 	assert.Nil(s.T(), err)
 	// process the latest block
 	s.processBlock(s.getLatestBlock())
+	// fetch metadata of the new URI
+	s.metadataFetcher.fetchDue()
 	// get updated project
 	project, err = pq.WhereOwner(&projectOwner).GetFirstOrFail()
 	assert.Nil(s.T(), err)
@@ -281,6 +303,44 @@ func (s *DispatcherTestSuite) TestUpdateUri() {
 	assert.EqualValues(s.T(), s.mockServerUrl+projectUpdatedUrl, project.Url)
 	assert.EqualValues(s.T(), projectUpdatedName, project.Name)
 	assert.EqualValues(s.T(), projectUpdatedImageUrl, project.ImageUrl)
+	// both documents are archived, the oldest first
+	vq := s.testRepo.ProjectMetadataVersionQuery()
+	versions, err := vq.WhereProjectId(project.Id).GetAll()
+	assert.Nil(s.T(), err)
+	if assert.Len(s.T(), versions, 2) {
+		assert.Equal(s.T(), s.mockServerUrl+projectUrl, versions[0].Uri)
+		assert.Equal(s.T(), s.mockServerUrl+projectUpdatedUrl, versions[1].Uri)
+		assert.Contains(s.T(), versions[1].Content, projectUpdatedName)
+	}
+}
+
+// TestRefreshMetadata tests metadata of active projects are fetched again and unchanged documents are not archived twice
+func (s *DispatcherTestSuite) TestRefreshMetadata() {
+	s.setupTestProject()
+	pq := s.testRepo.ProjectQuery()
+	project, err := pq.WhereOwner(&projectOwner).GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	fetchedAt := *project.MetadataFetchedAt
+	// schedule the refresh of all metadata fetched so far
+	refresher := metadataRefresher{
+		service: service{repo: s.testRepo, log: s.metadataFetcher.log},
+		cfg:     &config.Metadata{RefreshInterval: 0},
+	}
+	refresher.refresh()
+	project, err = pq.GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), types.ProjectMetadataPending, project.MetadataStatus)
+	// fetch the same document again
+	s.metadataFetcher.fetchDue()
+	project, err = pq.GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), types.ProjectMetadataOk, project.MetadataStatus)
+	assert.True(s.T(), project.MetadataFetchedAt.After(fetchedAt))
+	// the unchanged document is archived once only
+	vq := s.testRepo.ProjectMetadataVersionQuery()
+	versions, err := vq.WhereProjectId(project.Id).GetAll()
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), versions, 1)
 }
 
 // TestUpdateRewardsRecipient tests the update rewards recipient functionality
@@ -324,6 +384,19 @@ func (s *DispatcherTestSuite) TestUpdateOwner() {
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), project)
 	assert.EqualValues(s.T(), id, project.Id)
+	// assert the change is recorded in history along with the initial owner
+	phq := s.testRepo.ProjectHistoryQuery()
+	history, err := phq.WhereProjectId(id).WhereAttribute(types.ProjectAttributeOwner).GetAll()
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), history, 2)
+	for _, h := range history {
+		if h.OldValue == nil {
+			assert.EqualValues(s.T(), projectOwner.Hex(), h.NewValue)
+			continue
+		}
+		assert.EqualValues(s.T(), projectOwner.Hex(), *h.OldValue)
+		assert.EqualValues(s.T(), addr.Hex(), h.NewValue)
+	}
 }
 
 // TestUpdateOwner tests the update owner functionality
@@ -534,6 +607,7 @@ func (s *DispatcherTestSuite) TestWithdrawal() {
 	assert.EqualValues(s.T(), s.currentEpoch, wr.RequestEpoch)
 	assert.EqualValues(s.T(), s.currentEpoch, *wr.WithdrawEpoch)
 	assert.EqualValues(s.T(), wr.Amount.ToInt(), totalClaimed)
+	assert.EqualValues(s.T(), projectRecipient.Hex(), wr.RecipientAddress.Hex())
 	// assert transactions were deleted
 	tq = s.testRepo.TransactionQuery()
 	transactions, err = tq.GetAll()
@@ -541,6 +615,47 @@ func (s *DispatcherTestSuite) TestWithdrawal() {
 	assert.Empty(s.T(), transactions)
 }
 
+// TestBootstrap tests the registry state is bootstrapped from the contract logs
+func (s *DispatcherTestSuite) TestBootstrap() {
+	contractAddresses := utils.Map(projectContracts, func(c *types.Address) common.Address { return c.Address })
+	_, err := s.projectsManagerSession.AddProject(
+		projectOwner.Address, projectRecipient.Address, s.mockServerUrl+projectUrl, contractAddresses)
+	assert.Nil(s.T(), err)
+	_, err = s.projectsManagerSession.RemoveProjectContract(new(big.Int).SetUint64(1), projectContracts[1].Address)
+	assert.Nil(s.T(), err)
+	// mine another block, the state is bootstrapped as of its beginning
+	s.shiftEpochs(1)
+	blk := s.getLatestBlock()
+	testLogger := logger.New(log.Writer(), "test", logging.ERROR)
+	err = Bootstrap(s.testRepo, testLogger, s.blkDispatcher.partitions, uint64(blk.Number))
+	assert.Nil(s.T(), err)
+	s.metadataFetcher.fetchDue()
+	// assert project and its contracts were stored
+	pq := s.testRepo.ProjectQuery()
+	project, err := pq.WhereOwner(&projectOwner).GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), projectRecipient.Hex(), project.ReceiverAddress.Hex())
+	assert.EqualValues(s.T(), projectName, project.Name)
+	pcq := s.testRepo.ProjectContractQuery()
+	pcl, err := pcq.WhereProjectId(project.Id).WhereNotRemoved().GetAll()
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), pcl, len(projectContracts)-1)
+	// assert the scanner continues from the bootstrap block
+	last, err := s.testRepo.LastProcessedBlock()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), uint64(blk.Number), last)
+	// assert the pinned epoch was opened at the bootstrap block and the bootstrap finished
+	epoch, err := s.testRepo.EpochQuery().WhereNumber(uint64(blk.Epoch)).GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), uint64(blk.Number), *epoch.FirstBlock)
+	assert.NotNil(s.T(), epoch.StartTime)
+	checkpoint, err := s.testRepo.Storage().BootstrapCheckpoint(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), checkpoint)
+	// bootstrap requires an empty database
+	assert.NotNil(s.T(), Bootstrap(s.testRepo, testLogger, s.blkDispatcher.partitions, uint64(blk.Number)))
+}
+
 // initializeSfc deploys the sfc mock contract to the test chain
 func (s *DispatcherTestSuite) initializeSfc() {
 	auth, err := bind.NewKeyedTransactorWithChainID(s.testChain.AdminAcc.PrivateKey, big.NewInt(TestChainId))
@@ -567,8 +682,18 @@ func (s *DispatcherTestSuite) initializeGasMonetization() {
 	s.gasMonetizationAddr = address
 }
 
-// sendTransaction sends a transaction from the given account to the given address
+// sendTransaction sends a transaction from the given account to the given address and processes its block
 func (s *DispatcherTestSuite) sendTransaction(from *testAccount, to common.Address, value *big.Int) {
+	s.submitTransaction(from, to, value)
+	// process the latest block
+	s.processBlock(s.getLatestBlock())
+	// reset mock value back
+	s.mockTracer.ExpectedCalls = nil
+	s.mockTracer.On("TraceTransaction", mock.Anything).Return([]types.TransactionTrace{}, nil)
+}
+
+// submitTransaction sends a transaction from the given account to the given address and mocks its trace
+func (s *DispatcherTestSuite) submitTransaction(from *testAccount, to common.Address, value *big.Int) {
 	nonce, err := s.testChain.RawRpc.PendingNonceAt(context.Background(), from.Address)
 	assert.Nil(s.T(), err)
 	tx := eth.NewTx(&eth.LegacyTx{
@@ -597,11 +722,6 @@ func (s *DispatcherTestSuite) sendTransaction(from *testAccount, to common.Addre
 			},
 		},
 	}, nil)
-	// process the latest block
-	s.processBlock(s.getLatestBlock())
-	// reset mock value back
-	s.mockTracer.ExpectedCalls = nil
-	s.mockTracer.On("TraceTransaction", mock.Anything).Return([]types.TransactionTrace{}, nil)
 }
 
 // initializeGasMonetizationSessions initializes sessions for the test accounts
@@ -686,6 +806,8 @@ func (s *DispatcherTestSuite) setupTestProject() {
 	assert.Nil(s.T(), err)
 	// process the latest block
 	s.processBlock(s.getLatestBlock())
+	// fetch metadata of the project
+	s.metadataFetcher.fetchDue()
 }
 
 // getLatestBlock returns the latest block
@@ -697,8 +819,13 @@ func (s *DispatcherTestSuite) getLatestBlock() *types.Block {
 
 // processBlock processes the given block by sending it to the dispatcher
 func (s *DispatcherTestSuite) processBlock(blk *types.Block) {
+	s.processBlockOfEpoch(blk, s.currentEpoch)
+}
+
+// processBlockOfEpoch processes the given block of the given epoch by sending it to the dispatcher
+func (s *DispatcherTestSuite) processBlockOfEpoch(blk *types.Block, epoch uint64) {
 	// inject epoch number into block, because it is not set by the test chain
-	blk.Epoch = hexutil.Uint64(s.currentEpoch)
+	blk.Epoch = hexutil.Uint64(epoch)
 	// send block to dispatcher
 	s.blkDispatcher.inBlock <- blk
 	// wait for block to be processed
@@ -731,3 +858,58 @@ func (s *DispatcherTestSuite) initializeMockServer() {
 	}))
 	s.mockServerUrl = ts.URL
 }
+
+// TestEmptyBlockClosesEpoch tests the epoch is closed by the first block of a new epoch, even an empty one
+func (s *DispatcherTestSuite) TestEmptyBlockClosesEpoch() {
+	s.setupTestProject()
+	previous := s.currentEpoch
+	s.shiftEpochs(1)
+	blk := s.getLatestBlock()
+	blk.Txs = nil
+	s.processBlock(blk)
+
+	epoch, err := s.testRepo.Epoch(previous)
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), epoch.Sealed)
+	assert.EqualValues(s.T(), uint64(blk.Number)-1, *epoch.LastBlock)
+
+	epoch, err = s.testRepo.EpochAt(time.Unix(int64(blk.TimeStamp), 0))
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), s.currentEpoch, epoch.Number)
+	assert.EqualValues(s.T(), uint64(blk.Number), *epoch.FirstBlock)
+	assert.False(s.T(), epoch.Sealed)
+}
+
+// TestLateBlockOfSealedEpoch tests a block arriving after its epoch was sealed is recorded in its epoch
+// and its rewards are added to the project and the totals
+func (s *DispatcherTestSuite) TestLateBlockOfSealedEpoch() {
+	s.setupTestProject()
+	late := s.currentEpoch
+	s.submitTransaction(s.testChain.FunderAcc, projectContracts[0].Address, big.NewInt(1_000))
+	blk := s.getLatestBlock()
+	// the epoch is sealed before the block of the transaction is processed
+	s.processBlockOfEpoch(&types.Block{Number: blk.Number - 1, Epoch: hexutil.Uint64(late + 1), TimeStamp: blk.TimeStamp}, late+1)
+	epoch, err := s.testRepo.Epoch(late)
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), epoch.Sealed)
+	s.processBlockOfEpoch(blk, late)
+	s.mockTracer.ExpectedCalls = nil
+	s.mockTracer.On("TraceTransaction", mock.Anything).Return([]types.TransactionTrace{}, nil)
+
+	transaction, err := s.testRepo.TransactionQuery().GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), late, transaction.Epoch)
+	project, err := s.testRepo.ProjectQuery().WhereOwner(&projectOwner).GetFirstOrFail()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), transaction.RewardToClaim.ToInt(), project.CollectedRewards.ToInt())
+	assert.EqualValues(s.T(), transaction.RewardToClaim.ToInt(), project.RewardsToClaim.ToInt())
+	assert.EqualValues(s.T(), 1, project.TransactionsCount)
+	totalAmount, err := s.testRepo.TotalAmountCollected()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), transaction.RewardToClaim.ToInt(), totalAmount)
+	totalCount, err := s.testRepo.TotalTransactionsCount()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 1, totalCount)
+	// the dispatcher stays in the new epoch
+	assert.EqualValues(s.T(), late+1, s.blkDispatcher.currentEpochId)
+}
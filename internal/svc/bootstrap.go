@@ -0,0 +1,223 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/logger"
+	"ftm-gas-monetization/internal/repository"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"time"
+)
+
+// bootstrapLogsBlockRange represents the number of blocks the registry logs are loaded and stored for at once.
+const bootstrapLogsBlockRange = 10_000
+
+// Bootstrap initializes the registry state of all gas monetization contracts as of the beginning
+// of the given block, so a new instance does not need to scan the whole history of the chain.
+// The contract does not expose the projects registry, the state is built by replaying the registry events
+// loaded through eth_getLogs with the regular event handlers. Rewards collected before the block
+// can not be reconstructed, they require transaction traces. The scanner continues from the given block.
+// The events are replayed in chunks stored along with the block the replay continues from, so an interrupted
+// bootstrap continues from the last stored chunk when run again.
+func Bootstrap(repo *repository.Repository, log *logger.AppLogger, partitions *config.Partitions, block uint64) error {
+	bld := &blkDispatcher{
+		service: service{
+			repo: repo,
+			log:  log.ModuleLogger("bootstrap"),
+		},
+		storage:       repo.Storage(),
+		partitions:    partitions,
+		bootstrapping: true,
+	}
+	checkpoint, err := bld.bootstrapCheckpoint(block)
+	if err != nil {
+		return err
+	}
+	pinned, err := repo.BlockByNumber((*hexutil.Uint64)(&block))
+	if err != nil {
+		return fmt.Errorf("block #%d not available; %s", block, err.Error())
+	}
+	bld.initializeTopics()
+	bld.initializeTrackedData()
+
+	from := block
+	for _, gm := range bld.deployments {
+		if gm.startFromBlock < from {
+			from = gm.startFromBlock
+		}
+	}
+	if checkpoint > 0 {
+		if err := bld.restoreWatchedProjects(checkpoint); err != nil {
+			return err
+		}
+		from = checkpoint
+		bld.log.Noticef("continuing interrupted bootstrap from block #%d", from)
+	}
+	for ; from < block; from += bootstrapLogsBlockRange {
+		to := from + bootstrapLogsBlockRange - 1
+		if to >= block {
+			to = block - 1
+		}
+		if err := bld.bootstrapBlocks(from, to); err != nil {
+			return err
+		}
+		bld.log.Infof("registry replayed up to block #%d", to)
+	}
+
+	// the scanner continues from the pinned block in its epoch
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
+	err = bld.storage.Atomic(ctx, func(ctx context.Context, st storage.Storage) error {
+		epoch := uint64(pinned.Epoch)
+		// the pinned block is the first block of the epoch observed by this instance
+		if err := st.OpenEpoch(ctx, epoch, block, time.Unix(int64(pinned.TimeStamp), 0)); err != nil {
+			return err
+		}
+		if p, ok := st.(storage.Partitioned); ok {
+			if err := p.PrepareTransactionPartitions(ctx, epoch, epoch+1, bld.partitions.Epochs); err != nil {
+				return err
+			}
+		}
+		if err := st.UpdateCurrentEpoch(ctx, epoch); err != nil {
+			return err
+		}
+		if err := st.UpdateLastProcessedBlock(ctx, block); err != nil {
+			return err
+		}
+		return st.UpdateBootstrapCheckpoint(ctx, 0)
+	})
+	if err != nil {
+		return err
+	}
+	for _, gm := range bld.deployments {
+		if err := bld.reportPendingWithdrawals(ctx, bld.storage, gm, block); err != nil {
+			return err
+		}
+	}
+	bld.log.Noticef("registry state bootstrapped at block #%d, epoch #%d", block, uint64(pinned.Epoch))
+	return nil
+}
+
+// bootstrapCheckpoint returns the block the interrupted bootstrap continues from, zero if the bootstrap
+// starts over. A new bootstrap is supported on an empty database only.
+func (bld *blkDispatcher) bootstrapCheckpoint(block uint64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
+	checkpoint, err := bld.storage.BootstrapCheckpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if checkpoint > block {
+		return 0, fmt.Errorf("the interrupted bootstrap replayed the registry up to block #%d already", checkpoint-1)
+	}
+	if checkpoint > 0 {
+		return checkpoint, nil
+	}
+	projects, err := bld.storage.FindProjects(ctx, storage.ProjectFilter{})
+	if err != nil {
+		return 0, err
+	}
+	if len(projects) > 0 {
+		return 0, fmt.Errorf("the database already contains %d projects", len(projects))
+	}
+	return 0, nil
+}
+
+// restoreWatchedProjects loads the projects watched as of the beginning of the given block,
+// the handlers of the replayed events rely on them.
+func (bld *blkDispatcher) restoreWatchedProjects(block uint64) error {
+	blk, err := bld.repo.BlockByNumber((*hexutil.Uint64)(&block))
+	if err != nil {
+		return fmt.Errorf("block #%d not available; %s", block, err.Error())
+	}
+	bld.currentEpochId = uint64(blk.Epoch)
+	for _, gm := range bld.deployments {
+		gm.watchedContracts = make(map[common.Address]*types.Project)
+		gm.watchedProjectIds = make(map[uint64]*types.Project)
+		bld.initializeProjects(gm, block)
+	}
+	return nil
+}
+
+// bootstrapLog represents a registry event to be replayed along with the epoch it was emitted in.
+type bootstrapLog struct {
+	gm    *gasMonetization
+	log   *eth.Log
+	epoch uint64
+}
+
+// bootstrapBlocks replays the registry events emitted by all contracts in the given blocks. The events
+// are loaded before the storage transaction starts, the changes are stored at once along with the block
+// the replay continues from.
+func (bld *blkDispatcher) bootstrapBlocks(from uint64, to uint64) error {
+	var replay []bootstrapLog
+	epochs := make(map[uint64]uint64)
+	for _, gm := range bld.deployments {
+		if to < gm.startFromBlock {
+			continue
+		}
+		first := from
+		if first < gm.startFromBlock {
+			first = gm.startFromBlock
+		}
+		logs, err := bld.repo.GasMonetizationLogs(gm.address.Address, first, to)
+		if err != nil {
+			return fmt.Errorf("failed to load logs of %s in <#%d, #%d>: %v", gm.address.Hex(), first, to, err)
+		}
+		for i := range logs {
+			// handlers record the epoch of the changes, use the epoch of the block the log was emitted in
+			epoch, ok := epochs[logs[i].BlockNumber]
+			if !ok {
+				blk, err := bld.repo.BlockByNumber((*hexutil.Uint64)(&logs[i].BlockNumber))
+				if err != nil {
+					return fmt.Errorf("block #%d not available; %s", logs[i].BlockNumber, err.Error())
+				}
+				epoch = uint64(blk.Epoch)
+				epochs[logs[i].BlockNumber] = epoch
+			}
+			replay = append(replay, bootstrapLog{gm: gm, log: &logs[i], epoch: epoch})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeoutDuration)
+	defer cancel()
+	return bld.atomic(ctx, func(ctx context.Context, st storage.Storage) error {
+		for _, r := range replay {
+			bld.currentEpochId = r.epoch
+			if err := bld.processLog(ctx, r.gm, r.log, st); err != nil {
+				return err
+			}
+		}
+		return st.UpdateBootstrapCheckpoint(ctx, to+1)
+	})
+}
+
+// reportPendingWithdrawals reports withdrawal requests of the given contract still pending at the given block.
+// The rewards of the project are unknown to a bootstrapped instance, so the withdrawals are not completed
+// automatically.
+func (bld *blkDispatcher) reportPendingWithdrawals(ctx context.Context, st storage.Storage, gm *gasMonetization, block uint64) error {
+	requests, err := st.FindWithdrawalRequests(ctx, storage.WithdrawalRequestFilter{Contract: &gm.address, NotWithdrawn: true})
+	if err != nil {
+		return fmt.Errorf("failed to get withdrawal requests of %s: %v", gm.address.Hex(), err)
+	}
+	for _, request := range requests {
+		project, err := findProjectById(ctx, st, request.ProjectId)
+		if err != nil {
+			return fmt.Errorf("failed to get project of withdrawal request #%d: %v", request.Id, err)
+		}
+		isPending, err := bld.repo.HasPendingWithdrawalAt(gm.address.Address, project.ProjectId, request.RequestEpoch, block)
+		if err != nil {
+			return fmt.Errorf("failed to check if withdrawal is pending for project #%d: %v", project.ProjectId, err)
+		}
+		if isPending {
+			bld.log.Criticalf("withdrawal of project #%d requested in epoch #%d is pending and has to be completed manually",
+				project.ProjectId, request.RequestEpoch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sort"
+)
+
+// eventRegistry binds contract events to their handlers by the event name.
+// Topic IDs are derived from the contract ABI, so the handlers can not drift
+// from the events actually emitted by the contract.
+type eventRegistry struct {
+	abi      *abi.ABI
+	handlers map[string]EventHandler
+	ignored  map[string]bool
+}
+
+// newEventRegistry creates a new empty registry of events of the given ABI.
+func newEventRegistry(abi *abi.ABI) *eventRegistry {
+	return &eventRegistry{
+		abi:      abi,
+		handlers: make(map[string]EventHandler),
+		ignored:  make(map[string]bool),
+	}
+}
+
+// handle registers a handler for the event of the given name.
+func (reg *eventRegistry) handle(name string, handler EventHandler) *eventRegistry {
+	reg.handlers[name] = handler
+	return reg
+}
+
+// ignore marks events of the given names as deliberately not processed.
+func (reg *eventRegistry) ignore(names ...string) *eventRegistry {
+	for _, name := range names {
+		reg.ignored[name] = true
+	}
+	return reg
+}
+
+// topics builds the map of topics to their respective event handlers. It fails if a handler or an ignored event
+// is registered for an event the ABI does not know. The list of ABI events which are neither handled,
+// nor deliberately ignored, is returned as well.
+func (reg *eventRegistry) topics() (map[common.Hash]EventHandler, []string, error) {
+	for name := range reg.handlers {
+		if _, ok := reg.abi.Events[name]; !ok {
+			return nil, nil, fmt.Errorf("handler registered for unknown event %s", name)
+		}
+	}
+	for name := range reg.ignored {
+		if _, ok := reg.abi.Events[name]; !ok {
+			return nil, nil, fmt.Errorf("unknown event %s marked as ignored", name)
+		}
+	}
+
+	topics := make(map[common.Hash]EventHandler, len(reg.handlers))
+	missing := make([]string, 0)
+	for name, event := range reg.abi.Events {
+		if handler, ok := reg.handlers[name]; ok {
+			topics[event.ID] = handler
+			continue
+		}
+		if !reg.ignored[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return topics, missing, nil
+}
+
+// outcome returns the processing outcome of an event of the given name, which has no handler registered.
+func (reg *eventRegistry) outcome(name string) string {
+	switch {
+	case name == "":
+		return types.ContractEventUnknown
+	case reg.ignored[name]:
+		return types.ContractEventIgnored
+	default:
+		return types.ContractEventUnhandled
+	}
+}
+
+// decode resolves the name of the event of the given log record and unpacks its arguments, both indexed
+// and non-indexed ones. An empty name is returned if the topic of the log is unknown to the ABI.
+func (reg *eventRegistry) decode(log *eth.Log) (string, map[string]interface{}, error) {
+	if len(log.Topics) == 0 {
+		return "", nil, nil
+	}
+	event, err := reg.abi.EventByID(log.Topics[0])
+	if err != nil {
+		return "", nil, nil
+	}
+	args := make(map[string]interface{})
+	if len(log.Data) > 0 {
+		if err := event.Inputs.UnpackIntoMap(args, log.Data); err != nil {
+			return event.Name, nil, err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+		return event.Name, nil, err
+	}
+	return event.Name, args, nil
+}
+
+// encodeEventArguments encodes decoded event arguments into a JSON object.
+// Big integers are encoded as decimal strings and byte arrays as 0x prefixed hex strings.
+func encodeEventArguments(args map[string]interface{}) (string, error) {
+	out := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		switch v := value.(type) {
+		case *big.Int:
+			out[name] = v.String()
+		case []byte:
+			out[name] = hexutil.Encode(v)
+		case [32]byte:
+			out[name] = hexutil.Encode(v[:])
+		default:
+			out[name] = v
+		}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodedEventHandler adapts a handler of a typed event into an EventHandler.
+// The log record is decoded by the given parser of the generated contract binding.
+func decodedEventHandler[E any](
+	name string,
+	parse func(eth.Log) (*E, error),
+	handler func(context.Context, *E, storage.Storage) error,
+) EventHandler {
+	return func(ctx context.Context, log *eth.Log, st storage.Storage) error {
+		event, err := parse(*log)
+		if err != nil {
+			return fmt.Errorf("failed to unpack %s event #%d/#%d: %v", name, log.BlockNumber, log.Index, err)
+		}
+		return handler(ctx, event, st)
+	}
+}
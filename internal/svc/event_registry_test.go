@@ -0,0 +1,78 @@
+package svc
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/repository/rpc/contracts"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+// TestEventRegistryCoversAbi tests that every event of the contract ABI is either handled or ignored deliberately
+func TestEventRegistryCoversAbi(t *testing.T) {
+	abi, err := contracts.GasMonetizationMetaData.GetAbi()
+	assert.Nil(t, err)
+	events, err := contracts.NewGasMonetizationFilterer(common.Address{}, nil)
+	assert.Nil(t, err)
+	bld := blkDispatcher{}
+	topics, missing, err := bld.eventRegistry(abi, events).topics()
+	assert.Nil(t, err)
+	assert.Empty(t, missing)
+	assert.Len(t, topics, len(abi.Events)-len(ignoredEvents))
+	// topics are derived from the ABI
+	assert.Contains(t, topics, abi.Events["ProjectAdded"].ID)
+	assert.Contains(t, topics, abi.Events["WithdrawalCompleted"].ID)
+	assert.NotContains(t, topics, abi.Events["RoleGranted"].ID)
+}
+
+// TestEventRegistryReportsDrift tests that the registry reports events without handlers and unknown handlers
+func TestEventRegistryReportsDrift(t *testing.T) {
+	abi, err := contracts.GasMonetizationMetaData.GetAbi()
+	assert.Nil(t, err)
+	noop := func(context.Context, *eth.Log, storage.Storage) error { return nil }
+	// unhandled events are reported as missing
+	_, missing, err := newEventRegistry(abi).handle("ProjectAdded", noop).ignore(ignoredEvents...).topics()
+	assert.Nil(t, err)
+	assert.Contains(t, missing, "ProjectSuspended")
+	assert.NotContains(t, missing, "ProjectAdded")
+	assert.NotContains(t, missing, "RoleGranted")
+	// handler of an event the ABI does not know fails
+	_, _, err = newEventRegistry(abi).handle("ProjectRemoved", noop).topics()
+	assert.NotNil(t, err)
+	// ignoring an event the ABI does not know fails
+	_, _, err = newEventRegistry(abi).ignore("ProjectRemoved").topics()
+	assert.NotNil(t, err)
+}
+
+// TestEventRegistryDecodesRawLogs tests that raw logs are decoded into event names and JSON encoded arguments
+func TestEventRegistryDecodesRawLogs(t *testing.T) {
+	abi, err := contracts.GasMonetizationMetaData.GetAbi()
+	assert.Nil(t, err)
+	reg := newEventRegistry(abi).ignore(ignoredEvents...)
+	owner := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	event := abi.Events["ProjectOwnerUpdated"]
+	data, err := event.Inputs.NonIndexed().Pack(owner)
+	assert.Nil(t, err)
+	log := &eth.Log{
+		Topics: []common.Hash{event.ID, common.BigToHash(big.NewInt(7))},
+		Data:   data,
+	}
+	name, args, err := reg.decode(log)
+	assert.Nil(t, err)
+	assert.Equal(t, "ProjectOwnerUpdated", name)
+	encoded, err := encodeEventArguments(args)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"projectId":"7","owner":"0x1000000000000000000000000000000000000001"}`, encoded)
+	assert.Equal(t, types.ContractEventUnhandled, reg.outcome(name))
+	assert.Equal(t, types.ContractEventIgnored, reg.outcome("RoleGranted"))
+	// unknown topics are not decoded
+	name, args, err = reg.decode(&eth.Log{Topics: []common.Hash{common.HexToHash("0x01")}})
+	assert.Nil(t, err)
+	assert.Empty(t, name)
+	assert.Nil(t, args)
+	assert.Equal(t, types.ContractEventUnknown, reg.outcome(name))
+}
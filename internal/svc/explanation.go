@@ -0,0 +1,205 @@
+package svc
+
+import (
+	"fmt"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/repository"
+	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+)
+
+// ExplainTransaction explains the gas attribution and rewards of the given transaction. The transaction
+// is traced live, the stored rewards replace the live attribution if the transaction was processed already.
+func ExplainTransaction(repo *repository.Repository, cfg *config.Attribution, hash common.Hash) (*types.RewardExplanation, error) {
+	tq := repo.TransactionQuery()
+	rows, err := tq.WhereHash(&types.Hash{Hash: hash}).OrderBy(db.TransactionColumns.Id, db.Asc).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	out, err := explainTrace(repo, cfg, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 {
+		projects, err := storedProjects(repo, rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := overlayStored(out, rows, projects); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// overlayStored replaces the live attribution of the explained frames with the stored rewards of a processed
+// transaction, so the explanation shows what was paid even if the watching projects changed since.
+func overlayStored(out *types.RewardExplanation, rows []types.Transaction, projects map[int64]*types.Project) error {
+	first := &rows[0]
+	out.Epoch = uint64(first.Epoch)
+	out.Source = types.RewardExplanationStored
+	out.GasPrice = first.GasPrice
+	out.EffectiveGasPrice = first.EffectiveGasPrice
+	out.BaseFee = first.BaseFee
+	out.GasPriceSource = first.GasPriceSource
+
+	frames := make(map[string]int, len(out.Frames))
+	for i := range out.Frames {
+		frames[out.Frames[i].Path] = i
+		out.Frames[i].Watched = false
+		out.Frames[i].Projects = nil
+		out.Frames[i].Reward = nil
+	}
+	total := new(big.Int)
+	for i := range rows {
+		row := &rows[i]
+		project, ok := projects[row.ProjectId]
+		if !ok {
+			return fmt.Errorf("project %d of transaction %s not found", row.ProjectId, row.Hash.Hex())
+		}
+		total.Add(total, row.RewardToClaim.ToInt())
+		// frames watched by multiple deployments are stored once per deployment
+		idx, ok := frames[row.TraceAddress]
+		if !ok {
+			// the stored frame is missing in the live trace, it is explained from the stored row alone
+			idx = len(out.Frames)
+			frames[row.TraceAddress] = idx
+			out.Frames = append(out.Frames, types.RewardExplanationFrame{
+				Path:     row.TraceAddress,
+				CallType: row.CallType,
+				From:     &row.From.Address,
+				To:       &row.To.Address,
+				Selector: row.Selector,
+			})
+		}
+		frame := &out.Frames[idx]
+		frame.SelfGas = uint64(*row.GasUsed)
+		frame.Watched = true
+		frame.Reward = row.RewardToClaim
+		frame.Projects = append(frame.Projects, types.RewardExplanationProject{
+			Contract:  project.ContractAddress.Address,
+			ProjectId: project.ProjectId,
+		})
+	}
+	out.Reward = &types.Big{Big: hexutil.Big(*total)}
+	return nil
+}
+
+// storedProjects loads the projects the given stored rows are rewarded to, by their id.
+func storedProjects(repo *repository.Repository, rows []types.Transaction) (map[int64]*types.Project, error) {
+	ids := make([]int64, 0, len(rows))
+	for i := range rows {
+		ids = append(ids, rows[i].ProjectId)
+	}
+	pq := repo.ProjectQuery()
+	list, err := pq.WhereIdIn(ids).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	projects := make(map[int64]*types.Project, len(list))
+	for i := range list {
+		projects[list[i].Id] = &list[i]
+	}
+	return projects, nil
+}
+
+// explainTrace explains the attribution of a live trace of the transaction against the projects watching
+// the attributed contracts at the transaction block.
+func explainTrace(repo *repository.Repository, cfg *config.Attribution, hash common.Hash) (*types.RewardExplanation, error) {
+	trx, err := repo.Transaction(&hash)
+	if err != nil {
+		return nil, err
+	}
+	if trx.BlockNumber == nil {
+		return nil, fmt.Errorf("transaction %s is pending", hash.Hex())
+	}
+	blk, err := repo.BlockByNumber(trx.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	trx.ResolveEffectiveGasPrice(blk.BaseFee.ToInt())
+	traces, err := repo.TraceTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	out := &types.RewardExplanation{
+		Hash:              hash,
+		BlockNumber:       uint64(*trx.BlockNumber),
+		Epoch:             uint64(blk.Epoch),
+		Source:            types.RewardExplanationTrace,
+		GasPrice:          trx.GasPrice,
+		EffectiveGasPrice: trx.EffectiveGasPrice,
+		BaseFee:           trx.BaseFee,
+		GasPriceSource:    trx.GasPriceSource,
+		RewardsPercentage: rewardsPercentage,
+	}
+	total := new(big.Int)
+	selfGas := frameSelfGas(traces)
+	for i := range traces {
+		trace := &traces[i]
+		gasUsed := trace.GasUsed()
+		frame := types.RewardExplanationFrame{
+			Path:     trace.StringPath(),
+			CallType: trace.CallType(),
+			GasUsed:  &gasUsed,
+			SelfGas:  selfGas[trace.StringPath()],
+			Error:    trace.Error,
+		}
+		if trace.Action != nil {
+			frame.From = trace.Action.From
+			frame.To = trace.Action.To
+			frame.Selector = trace.Action.Selector()
+		}
+		if created := trace.Created(); created != nil {
+			frame.To = created
+		}
+		// failed frames are not attributed, their gas stays with the parent
+		if _, alive := selfGas[frame.Path]; alive {
+			if target, ok := attributionTarget(cfg, trace); ok {
+				frame.Target = &target
+				frame.Projects, err = watchingProjectsAt(repo, target, out.BlockNumber, out.Epoch)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(frame.Projects) > 0 {
+			reward := calculateReward(trx.EffectiveGasPrice.ToInt(), frame.SelfGas)
+			frame.Watched = true
+			frame.Reward = &types.Big{Big: hexutil.Big(*reward)}
+			total.Add(total, new(big.Int).Mul(reward, big.NewInt(int64(len(frame.Projects)))))
+		}
+		out.Frames = append(out.Frames, frame)
+	}
+	out.Reward = &types.Big{Big: hexutil.Big(*total)}
+	return out, nil
+}
+
+// watchingProjectsAt returns the projects watching the given contract at the given block and epoch.
+func watchingProjectsAt(repo *repository.Repository, contract common.Address, block uint64, epoch uint64) ([]types.RewardExplanationProject, error) {
+	pcq := repo.ProjectContractQuery()
+	contracts, err := pcq.WhereAddress(&types.Address{Address: contract}).WhereIsApproved(true).WhereMemberAtBlock(block).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 {
+		return nil, nil
+	}
+	ids := make([]int64, 0, len(contracts))
+	for _, c := range contracts {
+		ids = append(ids, c.ProjectId)
+	}
+	pq := repo.ProjectQuery()
+	projects, err := pq.WhereActiveInEpoch(epoch).WhereIdIn(ids).OrderBy(db.ProjectColumns.Id, db.Asc).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var out []types.RewardExplanationProject
+	for _, p := range projects {
+		out = append(out, types.RewardExplanationProject{Contract: p.ContractAddress.Address, ProjectId: p.ProjectId})
+	}
+	return out, nil
+}
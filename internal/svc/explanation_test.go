@@ -0,0 +1,69 @@
+package svc
+
+import (
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+// TestOverlayStored tests the stored rewards replace the live attribution while the traced frames are kept
+func TestOverlayStored(t *testing.T) {
+	target := common.HexToAddress("0x02")
+	gasUsed := uint64(0x5000)
+	out := &types.RewardExplanation{
+		Source: types.RewardExplanationTrace,
+		Frames: []types.RewardExplanationFrame{
+			{Path: "", CallType: types.CallTypeCall, SelfGas: 0x4000},
+			{Path: "0", CallType: types.CallTypeDelegateCall, Target: &target, GasUsed: &gasUsed, SelfGas: 0x3000,
+				Watched: true, Projects: []types.RewardExplanationProject{{Contract: common.HexToAddress("0xc2"), ProjectId: 9}}},
+		},
+	}
+	stored := hexutil.Uint64(0x2000)
+	row := func(projectId int64) types.Transaction {
+		return types.Transaction{
+			ProjectId:      projectId,
+			Hash:           &types.Hash{Hash: common.HexToHash("0x01")},
+			Epoch:          7,
+			TraceAddress:   "0",
+			CallType:       types.CallTypeDelegateCall,
+			From:           &types.Address{Address: common.HexToAddress("0x01")},
+			To:             &types.Address{Address: target},
+			GasUsed:        &stored,
+			GasPriceSource: types.GasPriceSourceReceipt,
+			RewardToClaim:  &types.Big{Big: hexutil.Big(*big.NewInt(100))},
+		}
+	}
+	projects := map[int64]*types.Project{
+		1: {Id: 1, ProjectId: 1, ContractAddress: &types.Address{Address: common.HexToAddress("0xc1")}},
+		2: {Id: 2, ProjectId: 1, ContractAddress: &types.Address{Address: common.HexToAddress("0xc2")}},
+	}
+
+	assert.Nil(t, overlayStored(out, []types.Transaction{row(1), row(2)}, projects))
+	assert.Equal(t, types.RewardExplanationStored, out.Source)
+	assert.EqualValues(t, 7, out.Epoch)
+	assert.Equal(t, types.GasPriceSourceReceipt, out.GasPriceSource)
+	assert.EqualValues(t, 200, out.Reward.ToInt().Int64())
+	assert.Len(t, out.Frames, 2)
+
+	// the unwatched frame of the trace is kept
+	assert.False(t, out.Frames[0].Watched)
+	assert.EqualValues(t, 0x4000, out.Frames[0].SelfGas)
+
+	// the rewarded frame keeps the traced gas and target with the stored rewards and projects
+	frame := out.Frames[1]
+	assert.True(t, frame.Watched)
+	assert.Equal(t, &target, frame.Target)
+	assert.EqualValues(t, 0x5000, *frame.GasUsed)
+	assert.EqualValues(t, 0x2000, frame.SelfGas)
+	assert.EqualValues(t, 100, frame.Reward.ToInt().Int64())
+	assert.Equal(t, []types.RewardExplanationProject{
+		{Contract: common.HexToAddress("0xc1"), ProjectId: 1},
+		{Contract: common.HexToAddress("0xc2"), ProjectId: 1},
+	}, frame.Projects)
+
+	// rewards of unknown projects are not explained
+	assert.NotNil(t, overlayStored(out, []types.Transaction{row(3)}, projects))
+}
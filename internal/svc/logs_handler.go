@@ -3,350 +3,469 @@ package svc
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/repository/rpc/contracts"
+	"ftm-gas-monetization/internal/repository/storage"
 	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	eth "github.com/ethereum/go-ethereum/core/types"
-	"io"
 	"math/big"
-	"net/http"
 )
 
 // EventHandler represents a function used to process event log record.
-type EventHandler func(context.Context, *eth.Log, *db.Db) error
+type EventHandler func(context.Context, *eth.Log, storage.Storage) error
+
+// ignoredEvents lists the gas monetization contract events deliberately not processed by the dispatcher.
+var ignoredEvents = []string{
+	"ContractDeployed",
+	"FundsAdded",
+	"FundsWithdrawn",
+	"RoleAdminChanged",
+	"RoleGranted",
+	"RoleRevoked",
+	"SfcAddressUpdated",
+	"WithdrawalConfirmationsLimitUpdated",
+	"WithdrawalEpochsLimitUpdated",
+}
 
 // initializeTopics represents a map of topics to their respective event handlers.
 func (bld *blkDispatcher) initializeTopics() {
-	bld.topics = map[common.Hash]EventHandler{
-		common.HexToHash("0xa8f2a13a6c4c221e863c34b0174b2a8356551bc645dc295ae4b5796c240915aa"): bld.handleProjectAdded,
-		common.HexToHash("0x0c3ad6c6f2fc1e970caed51e87ac06c3a37569f33664f42771264a4ae8907822"): bld.handleProjectSuspended,
-		common.HexToHash("0x0737ed2cc6eb4cf4aefb6d1e1404305301a64cae58ccf508828a20412fb77f35"): bld.handleProjectEnabled,
-		common.HexToHash("0xf83ba82192ce64f0fd48145ca2b60956a005a2d4e28f14fb099fad71294b8ff3"): bld.handleProjectContractAdded,
-		common.HexToHash("0xd32f2e923c29ff9e7231f459d69add67f769d05c5069c23bbdea536fc0cf154a"): bld.handleProjectContractRemoved,
-		common.HexToHash("0x781779743e625d6e652139cabc7e7c736ad376a0f1302b1b5c346548d948c72e"): bld.handleProjectMetadataUriUpdated,
-		common.HexToHash("0xc96c5102d284d786d29b5d0d7dda6ce493724355b762993adfef62b7220f161c"): bld.handleProjectRecipientUpdated,
-		common.HexToHash("0xffc579e983741c17a95792c458e2ae8c933b1bf7f5cd84f3bca571505c25d42a"): bld.handleProjectOwnerUpdated,
-		common.HexToHash("0x6b19bb08027e5bee64cbe3f99bbbfb671c0e134643993f0ad046fd01d020b342"): bld.handleWithdrawalRequest,
-		common.HexToHash("0x709b466596e79834da0e8ee56d4624cb3e8464a18cd5ae894790b672594c402c"): bld.handleWithdrawalCompleted,
-		common.HexToHash("0xe13f2d157714b016f2b9ff3d2db5002bca0925cf357170d6ab0bd1867ce6880b"): bld.handleInvalidWithdrawalAmount,
+	registry := bld.eventRegistry(bld.repo.GasMonetizationAbi(), bld.repo.GasMonetizationEvents())
+	topics, missing, err := registry.topics()
+	if err != nil {
+		bld.log.Fatalf("failed to initialize event handlers; %s", err.Error())
+	}
+	for _, name := range missing {
+		bld.log.Criticalf("event %s has no handler and is not ignored deliberately", name)
 	}
+	bld.events = registry
+	bld.topics = topics
 }
 
-// handleProjectAdded is an event handler for the ProjectAdded event.
-// It is called when a new project is added to the registry.
-func (bld *blkDispatcher) handleProjectAdded(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Data) < 192 || len(log.Topics) != 4 {
-		return fmt.Errorf("not ProjectAdded() event #%d/#%d; expected 192 bytes of data, %d given; expected 4 topics, %d given",
-			log.BlockNumber, log.Index, len(log.Data), len(log.Topics))
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectAdded", log.Data)
+// failedEventError represents a failure of an event handler along with the raw event it failed on.
+// The event can not be stored by the storage transaction being rolled back, it is stored by atomic
+// once the transaction is over.
+type failedEventError struct {
+	event *types.ContractEvent
+	err   error
+}
+
+// Error returns the message of the handler failure.
+func (e *failedEventError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the handler failure.
+func (e *failedEventError) Unwrap() error {
+	return e.err
+}
+
+// processLog processes the log record emitted by the given gas monetization contract and stores
+// the raw event along with the outcome of its handler. The event of a failed handler is returned
+// in a failedEventError instead, see atomic. The handler of an event processed already is not
+// called again, so the blocks can be replayed.
+func (bld *blkDispatcher) processLog(ctx context.Context, gm *gasMonetization, log *eth.Log, transaction storage.Storage) error {
+	event := bld.contractEvent(gm, log)
+	handler, ok := bld.handler(log)
+	if !ok {
+		if event.Outcome == types.ContractEventUnknown {
+			bld.log.Warningf("unknown topic of log #%d/#%d emitted by %s", log.BlockNumber, log.Index, gm.address.Hex())
+		}
+		return transaction.StoreContractEvent(ctx, event)
+	}
+	processed, err := eventProcessed(ctx, transaction, event)
+	if err != nil {
+		return fmt.Errorf("failed to check event #%d/#%d: %v", log.BlockNumber, log.Index, err)
+	}
+	if processed {
+		bld.log.Noticef("event #%d/#%d was processed already, skipping", log.BlockNumber, log.Index)
+		return nil
+	}
+	bld.log.Infof("known topic %s found, processing", log.Topics[0].String())
+	if err := handler(ctx, log, transaction); err != nil {
+		msg := err.Error()
+		event.Outcome = types.ContractEventFailed
+		event.Error = &msg
+		return &failedEventError{event: event, err: err}
+	}
+	event.Outcome = types.ContractEventProcessed
+	return transaction.StoreContractEvent(ctx, event)
+}
+
+// eventProcessed checks if the given event was processed by its handler already.
+func eventProcessed(ctx context.Context, st storage.Storage, event *types.ContractEvent) (bool, error) {
+	outcome := types.ContractEventProcessed
+	events, err := st.FindContractEvents(ctx, storage.ContractEventFilter{
+		TxHash:   event.TxHash,
+		LogIndex: &event.LogIndex,
+		Outcome:  &outcome,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectAdded event #%d/#%d: %v", log.BlockNumber, log.Index, err)
+		return false, err
 	}
+	return len(events) > 0, nil
+}
+
+// handler returns the event handler of the given log record, if any.
+func (bld *blkDispatcher) handler(log *eth.Log) (EventHandler, bool) {
+	if len(log.Topics) == 0 {
+		return nil, false
+	}
+	handler, ok := bld.topics[log.Topics[0]]
+	return handler, ok
+}
+
+// contractEvent builds the raw contract event record of the given log record.
+func (bld *blkDispatcher) contractEvent(gm *gasMonetization, log *eth.Log) *types.ContractEvent {
+	event := &types.ContractEvent{
+		ContractAddress: &gm.address,
+		BlockNumber:     log.BlockNumber,
+		TxHash:          &types.Hash{Hash: log.TxHash},
+		LogIndex:        uint64(log.Index),
+		Data:            hexutil.Encode(log.Data),
+	}
+	if len(log.Topics) > 0 {
+		event.Topic = &types.Hash{Hash: log.Topics[0]}
+	}
+	name, args, err := bld.events.decode(log)
+	if name != "" {
+		event.Name = &name
+	}
+	if err != nil {
+		bld.log.Errorf("failed to decode arguments of %s event #%d/#%d; %s", name, log.BlockNumber, log.Index, err.Error())
+	} else if args != nil {
+		if encoded, err := encodeEventArguments(args); err == nil {
+			event.Arguments = &encoded
+		} else {
+			bld.log.Errorf("failed to encode arguments of %s event #%d/#%d; %s", name, log.BlockNumber, log.Index, err.Error())
+		}
+	}
+	event.Outcome = bld.events.outcome(name)
+	return event
+}
+
+// eventRegistry builds the registry of gas monetization contract events handled by the dispatcher.
+func (bld *blkDispatcher) eventRegistry(abi *abi.ABI, events *contracts.GasMonetizationFilterer) *eventRegistry {
+	return newEventRegistry(abi).
+		handle("ProjectAdded", decodedEventHandler("ProjectAdded", events.ParseProjectAdded, bld.handleProjectAdded)).
+		handle("ProjectSuspended", decodedEventHandler("ProjectSuspended", events.ParseProjectSuspended, bld.handleProjectSuspended)).
+		handle("ProjectEnabled", decodedEventHandler("ProjectEnabled", events.ParseProjectEnabled, bld.handleProjectEnabled)).
+		handle("ProjectContractAdded", decodedEventHandler("ProjectContractAdded", events.ParseProjectContractAdded, bld.handleProjectContractAdded)).
+		handle("ProjectContractRemoved", decodedEventHandler("ProjectContractRemoved", events.ParseProjectContractRemoved, bld.handleProjectContractRemoved)).
+		handle("ProjectMetadataUriUpdated", decodedEventHandler("ProjectMetadataUriUpdated", events.ParseProjectMetadataUriUpdated, bld.handleProjectMetadataUriUpdated)).
+		handle("ProjectRewardsRecipientUpdated", decodedEventHandler("ProjectRewardsRecipientUpdated", events.ParseProjectRewardsRecipientUpdated, bld.handleProjectRecipientUpdated)).
+		handle("ProjectOwnerUpdated", decodedEventHandler("ProjectOwnerUpdated", events.ParseProjectOwnerUpdated, bld.handleProjectOwnerUpdated)).
+		handle("WithdrawalRequested", decodedEventHandler("WithdrawalRequested", events.ParseWithdrawalRequested, bld.handleWithdrawalRequest)).
+		handle("WithdrawalCompleted", decodedEventHandler("WithdrawalCompleted", events.ParseWithdrawalCompleted, bld.handleWithdrawalCompleted)).
+		handle("InvalidWithdrawalAmount", decodedEventHandler("InvalidWithdrawalAmount", events.ParseInvalidWithdrawalAmount, bld.handleInvalidWithdrawalAmount)).
+		ignore(ignoredEvents...)
+}
+
+// handleProjectAdded is an event handler for the ProjectAdded event.
+// It is called when a new project is added to the registry.
+func (bld *blkDispatcher) handleProjectAdded(ctx context.Context, event *contracts.GasMonetizationProjectAdded, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// create project
-	ownerAddr := types.Address{Address: common.BytesToAddress(log.Topics[2].Bytes())}
-	receiverAddr := types.Address{Address: common.BytesToAddress(log.Topics[3].Bytes())}
+	ownerAddr := types.Address{Address: event.Owner}
+	receiverAddr := types.Address{Address: event.RewardsRecipient}
 	project := &types.Project{
-		ProjectId:           log.Topics[1].Big().Uint64(),
+		ProjectId:           event.ProjectId.Uint64(),
+		ContractAddress:     &gm.address,
 		OwnerAddress:        &ownerAddr,
 		ReceiverAddress:     &receiverAddr,
-		Url:                 eventData["metadataUri"].(string),
+		Url:                 event.MetadataUri,
 		LastWithdrawalEpoch: nil,
 		CollectedRewards:    nil,
 		ClaimedRewards:      nil,
 		TransactionsCount:   0,
-		ActiveFromEpoch:     eventData["activeFromEpoch"].(*big.Int).Uint64(),
+		ActiveFromEpoch:     event.ActiveFromEpoch.Uint64(),
 		ActiveToEpoch:       nil,
 	}
-	if err = setMetadata(project); err != nil {
-		bld.log.Criticalf("failed to set metadata for project #%d: %v", project.ProjectId, err)
-	}
-	// store project
+	// store project, its metadata are fetched by the metadata fetcher later
 	if err := transaction.StoreProject(ctx, project); err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return fmt.Errorf("project #%d is already registered in %s: %v", project.ProjectId, gm.address.Hex(), err)
+		}
 		return fmt.Errorf("failed to add project #%d: %v", project.ProjectId, err)
 	}
+	// record initial attributes, so the history covers the whole project lifetime
+	initial := [][2]string{
+		{types.ProjectAttributeOwner, ownerAddr.Hex()},
+		{types.ProjectAttributeRecipient, receiverAddr.Hex()},
+		{types.ProjectAttributeMetadataUri, event.MetadataUri},
+	}
+	for _, attr := range initial {
+		if err := bld.storeProjectChange(ctx, transaction, project, attr[0], nil, attr[1], &event.Raw); err != nil {
+			return err
+		}
+	}
 	// create contracts
-	for _, contract := range eventData["contracts"].([]common.Address) {
+	for _, contract := range event.Contracts {
 		addr := types.Address{Address: contract}
 		if err := transaction.StoreProjectContract(ctx, &types.ProjectContract{
-			ProjectId: project.Id,
-			Address:   &addr,
-			Approved:  true,
+			ProjectId:    project.Id,
+			Address:      &addr,
+			Approved:     true,
+			AddedAtEpoch: bld.currentEpochId,
+			AddedAtBlock: event.Raw.BlockNumber,
 		}); err != nil {
-			return fmt.Errorf("failed to add contract %s for project #%d: %v", contract.Hex(), project.ProjectId, err)
+			return projectContractError(err, contract, project)
 		}
 		// add contract to watched contracts
-		bld.watchedContracts[contract] = project
+		gm.watchedContracts[contract] = project
 	}
 	// add project to watched projects
-	bld.watchedProjectIds[project.ProjectId] = project
+	gm.watchedProjectIds[project.ProjectId] = project
 	return nil
 }
 
-// handleProjectSuspended is an event handler for the ProjectSuspended event.
-func (bld *blkDispatcher) handleProjectSuspended(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Data) != 32 || len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectSuspended", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectSuspended event #%d/#%d: %v", log.BlockNumber, log.Index, err)
+// projectContractError describes the failure to add the given contract to the given project.
+func projectContractError(err error, contract common.Address, project *types.Project) error {
+	if errors.Is(err, storage.ErrDuplicate) {
+		return fmt.Errorf("contract %s is already a member of project #%d or another project of the same gas monetization contract: %v", contract.Hex(), project.ProjectId, err)
 	}
+	return fmt.Errorf("failed to add contract %s for project #%d: %v", contract.Hex(), project.ProjectId, err)
+}
+
+// handleProjectSuspended is an event handler for the ProjectSuspended event.
+func (bld *blkDispatcher) handleProjectSuspended(ctx context.Context, event *contracts.GasMonetizationProjectSuspended, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// get project from map
-	project := bld.watchedProjectIds[log.Topics[1].Big().Uint64()]
+	project := gm.watchedProjectIds[event.ProjectId.Uint64()]
 	if project == nil {
-		return fmt.Errorf("project #%d is not watched", log.Topics[1].Big().Uint64())
+		return fmt.Errorf("project #%d is not watched", event.ProjectId.Uint64())
 	}
 	// suspend project
-	activeTo := eventData["suspendedOnEpochNumber"].(*big.Int).Uint64()
+	activeTo := event.SuspendedOnEpochNumber.Uint64()
 	project.ActiveToEpoch = &activeTo
-	err = transaction.UpdateProject(ctx, project)
+	err := transaction.UpdateProject(ctx, project)
 	if err != nil {
 		return fmt.Errorf("failed to suspend project #%d: %v", project.ProjectId, err)
 	}
 	// remove contracts from watched contracts
-	pcq := transaction.ProjectContractQuery(ctx)
-	contracts, err := pcq.WhereProjectId(project.Id).WhereIsApproved(true).GetAll()
+	contracts, err := watchedProjectContracts(ctx, transaction, project)
 	if err != nil {
 		return fmt.Errorf("failed to get contracts for project #%d: %v", project.ProjectId, err)
 	}
 	for _, contract := range contracts {
-		delete(bld.watchedContracts, contract.Address.Address)
+		delete(gm.watchedContracts, contract.Address.Address)
 	}
 	// remove project from watched projects
-	delete(bld.watchedProjectIds, project.ProjectId)
+	delete(gm.watchedProjectIds, project.ProjectId)
 	return nil
 }
 
 // handleProjectEnabled is an event handler for the ProjectEnabled event.
-func (bld *blkDispatcher) handleProjectEnabled(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Data) != 32 || len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectEnabled", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectEnabled event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
+func (bld *blkDispatcher) handleProjectEnabled(ctx context.Context, event *contracts.GasMonetizationProjectEnabled, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// fetch project
-	pq := transaction.ProjectQuery(ctx)
-	project, err := pq.WhereProjectId(log.Topics[1].Big().Uint64()).GetFirstOrFail()
+	project, err := findProject(ctx, transaction, gm, event.ProjectId.Uint64())
 	if err != nil {
-		return fmt.Errorf("failed to get project #%d: %v", log.Topics[1].Big().Uint64(), err)
+		return fmt.Errorf("failed to get project #%d: %v", event.ProjectId.Uint64(), err)
 	}
 	// enable project
-	project.ActiveFromEpoch = eventData["enabledOnEpochNumber"].(*big.Int).Uint64()
+	project.ActiveFromEpoch = event.EnabledOnEpochNumber.Uint64()
 	project.ActiveToEpoch = nil
 	err = transaction.UpdateProject(ctx, project)
 	if err != nil {
 		return fmt.Errorf("failed to enable project #%d: %v", project.ProjectId, err)
 	}
 	// add contracts into watched contracts
-	pcq := transaction.ProjectContractQuery(ctx)
-	contracts, err := pcq.WhereProjectId(project.Id).WhereIsApproved(true).GetAll()
+	contracts, err := watchedProjectContracts(ctx, transaction, project)
 	if err != nil {
 		return fmt.Errorf("failed to get contracts for project #%d: %v", project.ProjectId, err)
 	}
 	for _, contract := range contracts {
-		bld.watchedContracts[contract.Address.Address] = project
+		gm.watchedContracts[contract.Address.Address] = project
 	}
 	// add project into watched projects
-	bld.watchedProjectIds[project.ProjectId] = project
+	gm.watchedProjectIds[project.ProjectId] = project
 	return nil
 }
 
 // handleProjectContractAdded is an event handler for the ProjectContractAdded event.
-func (bld *blkDispatcher) handleProjectContractAdded(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Topics) != 3 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectContractAdded", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectContractAdded event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
+func (bld *blkDispatcher) handleProjectContractAdded(ctx context.Context, event *contracts.GasMonetizationProjectContractAdded, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// get project from map
-	project, isWatched := bld.watchedProjectIds[log.Topics[1].Big().Uint64()]
+	project, isWatched := gm.watchedProjectIds[event.ProjectId.Uint64()]
 	if project == nil {
 		// in case project is not watched, we should fetch it from DB
-		pq := transaction.ProjectQuery(ctx)
-		project, err = pq.WhereProjectId(log.Topics[1].Big().Uint64()).GetFirstOrFail()
+		var err error
+		project, err = findProject(ctx, transaction, gm, event.ProjectId.Uint64())
 		if err != nil {
-			return fmt.Errorf("failed to get project #%d: %v", log.Topics[1].Big().Uint64(), err)
+			return fmt.Errorf("failed to get project #%d: %v", event.ProjectId.Uint64(), err)
 		}
 	}
-	// add contract
-	addr := types.Address{Address: common.BytesToAddress(log.Topics[2].Bytes())}
-	if err := transaction.StoreProjectContract(ctx, &types.ProjectContract{
-		ProjectId: project.Id,
-		Address:   &addr,
-		Approved:  true,
-	}); err != nil {
-		return fmt.Errorf("failed to add contract %s for project #%d: %v", addr.Hex(), project.ProjectId, err)
+	// add contract, unless the membership is already stored by a previous run over this block
+	addr := types.Address{Address: event.ContractAddress}
+	existing, err := transaction.FindProjectContracts(ctx, storage.ProjectContractFilter{
+		ProjectId:    &project.Id,
+		Address:      &addr,
+		AddedAtBlock: &event.Raw.BlockNumber,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get contract %s for project #%d: %v", addr.Hex(), project.ProjectId, err)
+	}
+	if len(existing) == 0 {
+		if err := transaction.StoreProjectContract(ctx, &types.ProjectContract{
+			ProjectId:    project.Id,
+			Address:      &addr,
+			Approved:     true,
+			AddedAtEpoch: bld.currentEpochId,
+			AddedAtBlock: event.Raw.BlockNumber,
+		}); err != nil {
+			return projectContractError(err, addr.Address, project)
+		}
 	}
 	// add contract into watched contracts if project is watched
 	if isWatched {
-		bld.watchedContracts[addr.Address] = project
+		gm.watchedContracts[addr.Address] = project
 	}
 	return nil
 }
 
 // handleProjectContractRemoved is an event handler for the ProjectContractRemoved event.
-func (bld *blkDispatcher) handleProjectContractRemoved(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Topics) != 3 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectContractRemoved", log.Data)
+func (bld *blkDispatcher) handleProjectContractRemoved(ctx context.Context, event *contracts.GasMonetizationProjectContractRemoved, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
+	// find the project, the same contract may be registered in another deployment
+	project, err := findProject(ctx, transaction, gm, event.ProjectId.Uint64())
 	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectContractRemoved event #%d/#%d: %v", log.BlockNumber, log.Index, err)
+		return fmt.Errorf("failed to get project #%d: %v", event.ProjectId.Uint64(), err)
+	}
+	// close the membership of the contract; it is already closed if the block is processed again
+	addr := types.Address{Address: event.ContractAddress}
+	members, err := transaction.FindProjectContracts(ctx, storage.ProjectContractFilter{
+		ProjectId:  &project.Id,
+		Address:    &addr,
+		NotRemoved: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get contract %s for project #%d: %v", addr.Hex(), event.ProjectId.Uint64(), err)
 	}
-	// delete contract
-	qb := transaction.ProjectContractQuery(ctx)
-	addr := types.Address{Address: common.BytesToAddress(log.Topics[2].Bytes())}
-	if err := qb.WhereAddress(&addr).Delete(); err != nil {
-		return fmt.Errorf("failed to delete contract %s for project #%d: %v", addr.Hex(), log.Topics[1].Big().Uint64(), err)
+	for i := range members {
+		if err := transaction.RemoveProjectContract(ctx, &members[i], bld.currentEpochId, event.Raw.BlockNumber); err != nil {
+			return fmt.Errorf("failed to remove contract %s for project #%d: %v", addr.Hex(), event.ProjectId.Uint64(), err)
+		}
 	}
 	// remove contract from watched contracts (if project is not watched, then delete is no-op)
-	delete(bld.watchedContracts, addr.Address)
+	delete(gm.watchedContracts, addr.Address)
 	return nil
 }
 
 // handleProjectMetadataUriUpdated is an event handler for the ProjectMetadataUriUpdated event.
-func (bld *blkDispatcher) handleProjectMetadataUriUpdated(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectMetadataUriUpdated", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectMetadataUriUpdated event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
-	projectId := log.Topics[1].Big().Uint64()
-	uri := eventData["metadataUri"].(string)
+func (bld *blkDispatcher) handleProjectMetadataUriUpdated(ctx context.Context, event *contracts.GasMonetizationProjectMetadataUriUpdated, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
+	projectId := event.ProjectId.Uint64()
 	// get project from map
-	project := bld.watchedProjectIds[projectId]
+	project := gm.watchedProjectIds[projectId]
 	if project == nil {
 		// in case project is not watched, we should fetch it from DB
-		pq := transaction.ProjectQuery(ctx)
-		project, err = pq.WhereProjectId(projectId).GetFirstOrFail()
+		var err error
+		project, err = findProject(ctx, transaction, gm, projectId)
 		if err != nil {
 			return fmt.Errorf("failed to get project #%d: %v", projectId, err)
 		}
 	}
-	project.Url = uri
-	if err = setMetadata(project); err != nil {
-		bld.log.Criticalf("failed to set metadata for project #%d: %v", projectId, err)
+	oldUri := project.Url
+	if err := bld.storeProjectChange(ctx, transaction, project, types.ProjectAttributeMetadataUri, &oldUri, event.MetadataUri, &event.Raw); err != nil {
+		return err
 	}
-	if err = transaction.UpdateProject(ctx, project); err != nil {
+	project.Url = event.MetadataUri
+	if err := transaction.UpdateProject(ctx, project); err != nil {
 		return fmt.Errorf("failed to update project #%d: %v", projectId, err)
 	}
+	// metadata of the new URI are fetched by the metadata fetcher
+	if err := transaction.ScheduleProjectMetadata(ctx, project); err != nil {
+		return fmt.Errorf("failed to schedule metadata of project #%d: %v", projectId, err)
+	}
 	return nil
 }
 
 // handleProjectRecipientUpdated is an event handler for the ProjectRewardsRecipientUpdated event.
-func (bld *blkDispatcher) handleProjectRecipientUpdated(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectRewardsRecipientUpdated", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectRewardsRecipientUpdated event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
+func (bld *blkDispatcher) handleProjectRecipientUpdated(ctx context.Context, event *contracts.GasMonetizationProjectRewardsRecipientUpdated, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// get project from map
-	project := bld.watchedProjectIds[log.Topics[1].Big().Uint64()]
+	project := gm.watchedProjectIds[event.ProjectId.Uint64()]
 	if project == nil {
-		return fmt.Errorf("project #%d is not watched", log.Topics[1].Big().Uint64())
+		return fmt.Errorf("project #%d is not watched", event.ProjectId.Uint64())
 	}
 	// update recipient
-	recipient := types.Address{Address: eventData["recipient"].(common.Address)}
+	recipient := types.Address{Address: event.Recipient}
+	oldRecipient := project.ReceiverAddress.Hex()
+	if err := bld.storeProjectChange(ctx, transaction, project, types.ProjectAttributeRecipient, &oldRecipient, recipient.Hex(), &event.Raw); err != nil {
+		return err
+	}
 	project.ReceiverAddress = &recipient
-	err = transaction.UpdateProject(ctx, project)
-	if err != nil {
+	if err := transaction.UpdateProject(ctx, project); err != nil {
 		return fmt.Errorf("failed to update recipient %s for project #%d: %v", recipient.Hex(), project.ProjectId, err)
 	}
 	return nil
 }
 
 // handleProjectOwnerUpdated is an event handler for the ProjectOwnerUpdated event.
-func (bld *blkDispatcher) handleProjectOwnerUpdated(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "ProjectOwnerUpdated", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectOwnerUpdated event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
+func (bld *blkDispatcher) handleProjectOwnerUpdated(ctx context.Context, event *contracts.GasMonetizationProjectOwnerUpdated, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// get project from map
-	project := bld.watchedProjectIds[log.Topics[1].Big().Uint64()]
+	project := gm.watchedProjectIds[event.ProjectId.Uint64()]
 	if project == nil {
-		return fmt.Errorf("project #%d is not watched", log.Topics[1].Big().Uint64())
+		return fmt.Errorf("project #%d is not watched", event.ProjectId.Uint64())
 	}
 	// update owner
-	owner := types.Address{Address: eventData["owner"].(common.Address)}
+	owner := types.Address{Address: event.Owner}
+	oldOwner := project.OwnerAddress.Hex()
+	if err := bld.storeProjectChange(ctx, transaction, project, types.ProjectAttributeOwner, &oldOwner, owner.Hex(), &event.Raw); err != nil {
+		return err
+	}
 	project.OwnerAddress = &owner
-	err = transaction.UpdateProject(ctx, project)
-	if err != nil {
+	if err := transaction.UpdateProject(ctx, project); err != nil {
 		return fmt.Errorf("failed to update owner %s for project #%d: %v", owner.Hex(), project.ProjectId, err)
 	}
 	return nil
 }
 
 // handleWithdrawalRequest is an event handler for the WithdrawalRequested event.
-func (bld *blkDispatcher) handleWithdrawalRequest(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Data) != 32 || len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "WithdrawalRequested", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack ProjectOwnerUpdated event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
+func (bld *blkDispatcher) handleWithdrawalRequest(ctx context.Context, event *contracts.GasMonetizationWithdrawalRequested, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// get project from map
-	project := bld.watchedProjectIds[log.Topics[1].Big().Uint64()]
+	project := gm.watchedProjectIds[event.ProjectId.Uint64()]
 	if project == nil {
-		return fmt.Errorf("project #%d is not watched", log.Topics[1].Big().Uint64())
+		return fmt.Errorf("project #%d is not watched", event.ProjectId.Uint64())
 	}
 	// create withdrawal request
-	epoch := eventData["requestEpochNumber"].(*big.Int).Uint64()
-	err = transaction.StoreWithdrawalRequest(ctx, &types.WithdrawalRequest{
-		ProjectId:     project.Id,
-		RequestEpoch:  eventData["requestEpochNumber"].(*big.Int).Uint64(),
-		WithdrawEpoch: nil,
-		Amount:        nil,
+	epoch := event.RequestEpochNumber.Uint64()
+	err := transaction.StoreWithdrawalRequest(ctx, &types.WithdrawalRequest{
+		ProjectId:       project.Id,
+		ContractAddress: &gm.address,
+		RequestEpoch:    epoch,
+		WithdrawEpoch:   nil,
+		Amount:          nil,
 	})
 	if err != nil {
+		if errors.Is(err, storage.ErrMissingReference) {
+			return fmt.Errorf("withdrawal requested for project #%d, which is not stored: %v", project.ProjectId, err)
+		}
 		return fmt.Errorf("failed to store withdrawal request for project #%d: %v", project.ProjectId, err)
 	}
+	// the rewards are not known while bootstrapping, pending withdrawals are reported afterwards
+	if bld.bootstrapping {
+		return nil
+	}
 	// submit amount to withdraw to contract
 	if project.RewardsToClaim == nil {
 		return fmt.Errorf("project #%d has no rewards to claim", project.ProjectId)
 	}
 	// complete withdrawal for given project if it is still pending
-	isPending, err := bld.repo.HasPendingWithdrawal(project.ProjectId, epoch)
+	isPending, err := bld.repo.HasPendingWithdrawal(gm.address.Address, project.ProjectId, epoch)
 	if err != nil {
 		return fmt.Errorf("failed to check if withdrawal is pending for project #%d: %v", project.ProjectId, err)
 	}
 	if !isPending {
 		return nil
 	}
-	if err = bld.repo.CompleteWithdrawal(project.ProjectId, epoch, project.RewardsToClaim.ToInt()); err != nil {
+	if err = bld.repo.CompleteWithdrawal(gm.address.Address, project.ProjectId, epoch, project.RewardsToClaim.ToInt()); err != nil {
 		bld.log.Criticalf("failed to complete withdrawal for project #%d: %s", project.ProjectId, err.Error())
 		// also send notification that withdrawal failed
 		bld.sendNotification(fmt.Sprintf("Failed to complete withdrawal for project #%d: %s", project.ProjectId, err.Error()))
@@ -355,37 +474,36 @@ func (bld *blkDispatcher) handleWithdrawalRequest(ctx context.Context, log *eth.
 }
 
 // handleWithdrawalCompleted is an event handler for the WithdrawalCompleted event.
-func (bld *blkDispatcher) handleWithdrawalCompleted(ctx context.Context, log *eth.Log, transaction *db.Db) error {
-	if len(log.Data) != 96 || len(log.Topics) != 2 {
-		return nil
-	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "WithdrawalCompleted", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack WithdrawalCompleted event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
+func (bld *blkDispatcher) handleWithdrawalCompleted(ctx context.Context, event *contracts.GasMonetizationWithdrawalCompleted, transaction storage.Storage) error {
+	gm := bld.deployments[event.Raw.Address]
 	// get project from map
-	project := bld.watchedProjectIds[log.Topics[1].Big().Uint64()]
+	project := gm.watchedProjectIds[event.ProjectId.Uint64()]
 	if project == nil {
 		// in case project is not watched, we should fetch it from DB
-		pq := transaction.ProjectQuery(ctx)
-		project, err = pq.WhereProjectId(log.Topics[1].Big().Uint64()).GetFirstOrFail()
+		var err error
+		project, err = findProject(ctx, transaction, gm, event.ProjectId.Uint64())
 		if err != nil {
-			return fmt.Errorf("failed to get project #%d: %v", log.Topics[1].Big().Uint64(), err)
+			return fmt.Errorf("failed to get project #%d: %v", event.ProjectId.Uint64(), err)
 		}
 	}
-	requestEpoch := eventData["requestEpochNumber"].(*big.Int).Uint64()
-	withdrawalEpoch := eventData["withdrawalEpochNumber"].(*big.Int).Uint64()
-	amount := eventData["amount"].(*big.Int)
+	requestEpoch := event.RequestEpochNumber.Uint64()
+	withdrawalEpoch := event.WithdrawalEpochNumber.Uint64()
+	amount := event.Amount
 	// fill withdrawal request
-	wrq := transaction.WithdrawalRequestQuery(ctx)
-	request, err := wrq.WhereProjectId(project.Id).WhereRequestEpoch(requestEpoch).GetFirstOrFail()
+	requests, err := transaction.FindWithdrawalRequests(ctx, storage.WithdrawalRequestFilter{
+		ProjectId:    &project.Id,
+		RequestEpoch: &requestEpoch,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get withdrawal request for project #%d: %v", project.ProjectId, err)
 	}
+	if len(requests) == 0 {
+		return fmt.Errorf("withdrawal request of project #%d in epoch #%d not found", project.ProjectId, requestEpoch)
+	}
+	request := &requests[0]
 	request.WithdrawEpoch = &withdrawalEpoch
 	request.Amount = &types.Big{Big: hexutil.Big(*amount)}
+	request.RecipientAddress = project.ReceiverAddress
 	if err = transaction.UpdateWithdrawalRequest(ctx, request); err != nil {
 		return fmt.Errorf("failed to update withdrawal request for project #%d: %v", project.ProjectId, err)
 	}
@@ -396,12 +514,14 @@ func (bld *blkDispatcher) handleWithdrawalCompleted(ctx context.Context, log *et
 		res := new(big.Int).Add(project.ClaimedRewards.ToInt(), amount)
 		project.ClaimedRewards = &types.Big{Big: hexutil.Big(*res)}
 	}
-	// subtract claimed amount from rewards to claim
-	if project.RewardsToClaim == nil {
+	// subtract claimed amount from rewards to claim, which are not known while bootstrapping
+	if project.RewardsToClaim == nil && !bld.bootstrapping {
 		return fmt.Errorf("project #%d has no rewards to claim", project.ProjectId)
 	}
-	res := new(big.Int).Sub(project.RewardsToClaim.ToInt(), amount)
-	project.RewardsToClaim = &types.Big{Big: hexutil.Big(*res)}
+	if project.RewardsToClaim != nil {
+		res := new(big.Int).Sub(project.RewardsToClaim.ToInt(), amount)
+		project.RewardsToClaim = &types.Big{Big: hexutil.Big(*res)}
+	}
 	// update last withdrawal epoch
 	project.LastWithdrawalEpoch = &withdrawalEpoch
 	if err = transaction.UpdateProject(ctx, project); err != nil {
@@ -415,49 +535,82 @@ func (bld *blkDispatcher) handleWithdrawalCompleted(ctx context.Context, log *et
 	// we are free to delete related transaction... we also need to delete transactions
 	// from previous epoch, so we won't delete transactions from current epoch.
 	// TODO: This might change
-	tq := transaction.TransactionQuery(ctx)
-	if err = tq.WhereProjectId(project.Id).WhereEpochLt(withdrawalEpoch).Delete(); err != nil {
+	if err = transaction.DeleteTransactions(ctx, storage.TransactionFilter{
+		ProjectId:   &project.Id,
+		EpochBefore: &withdrawalEpoch,
+	}); err != nil {
 		return fmt.Errorf("failed to delete transactions for project #%d: %v", project.ProjectId, err)
 	}
 	return nil
 }
 
 // handleInvalidWithdrawalAmount is an event handler for the InvalidWithdrawalAmount event.
-func (bld *blkDispatcher) handleInvalidWithdrawalAmount(_ context.Context, log *eth.Log, _ *db.Db) error {
-	if len(log.Data) != 96 || len(log.Topics) != 2 {
+func (bld *blkDispatcher) handleInvalidWithdrawalAmount(_ context.Context, event *contracts.GasMonetizationInvalidWithdrawalAmount, _ storage.Storage) error {
+	// historical failures are not worth a notification
+	if bld.bootstrapping {
 		return nil
 	}
-	// parse event data
-	eventData := make(map[string]interface{})
-	err := bld.repo.GasMonetizationAbi().UnpackIntoMap(eventData, "InvalidWithdrawalAmount", log.Data)
-	if err != nil {
-		return fmt.Errorf("failed to unpack InvalidWithdrawalAmount event #%d/#%d: %v", log.BlockNumber, log.Index, err)
-	}
-	projectId := log.Topics[1].Big().Uint64()
-	epoch := eventData["withdrawalEpochNumber"].(*big.Int).Uint64()
-	amount := eventData["amount"].(*big.Int)
-	diffAmount := eventData["diffAmount"].(*big.Int)
 	// notify error
-	bld.sendNotification(fmt.Sprintf("Invalid withdrawal amount for project #%d: %s (epoch #%d, diff %s)", projectId, amount, epoch, diffAmount))
+	bld.sendNotification(fmt.Sprintf("Invalid withdrawal amount for project #%d: %s (epoch #%d, diff %s)",
+		event.ProjectId.Uint64(), event.Amount, event.WithdrawalEpochNumber.Uint64(), event.DiffAmount))
 	return nil
 }
 
-// setMetadata sets metadata for given project.
-func setMetadata(project *types.Project) error {
-	resp, err := http.Get(project.Url)
+// findProject returns the project of the given id registered in the given gas monetization contract.
+func findProject(ctx context.Context, st storage.Storage, gm *gasMonetization, projectId uint64) (*types.Project, error) {
+	projects, err := st.FindProjects(ctx, storage.ProjectFilter{Contract: &gm.address, ProjectId: &projectId})
 	if err != nil {
-		return fmt.Errorf("failed to get project metadata: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("project #%d of %s not found", projectId, gm.address.Hex())
+	}
+	return &projects[0], nil
+}
+
+// findProjectById returns the project of the given database id.
+func findProjectById(ctx context.Context, st storage.Storage, id int64) (*types.Project, error) {
+	projects, err := st.FindProjects(ctx, storage.ProjectFilter{Id: &id})
 	if err != nil {
-		return fmt.Errorf("failed to read project metadata: %v", err)
+		return nil, err
 	}
-	var metadata types.ProjectMetadata
-	if err = json.Unmarshal(body, &metadata); err != nil {
-		return fmt.Errorf("failed to unmarshal project metadata: %v", err)
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("project %d not found", id)
+	}
+	return &projects[0], nil
+}
+
+// watchedProjectContracts returns the approved contracts of the project, which were not removed.
+func watchedProjectContracts(ctx context.Context, st storage.Storage, project *types.Project) ([]types.ProjectContract, error) {
+	approved := true
+	return st.FindProjectContracts(ctx, storage.ProjectContractFilter{
+		ProjectId:  &project.Id,
+		Approved:   &approved,
+		NotRemoved: true,
+	})
+}
+
+// storeProjectChange records the change of the project attribute made by the given log record.
+func (bld *blkDispatcher) storeProjectChange(
+	ctx context.Context,
+	transaction storage.Storage,
+	project *types.Project,
+	attribute string,
+	oldValue *string,
+	newValue string,
+	log *eth.Log,
+) error {
+	if err := transaction.StoreProjectHistory(ctx, &types.ProjectHistory{
+		ProjectId:   project.Id,
+		Attribute:   attribute,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		BlockNumber: log.BlockNumber,
+		LogIndex:    uint64(log.Index),
+		Epoch:       bld.currentEpochId,
+		TxHash:      &types.Hash{Hash: log.TxHash},
+	}); err != nil {
+		return fmt.Errorf("failed to store %s change of project #%d: %v", attribute, project.ProjectId, err)
 	}
-	project.Name = metadata.Name
-	project.ImageUrl = metadata.ImageUrl
 	return nil
 }
@@ -0,0 +1,365 @@
+package svc
+
+import (
+	"context"
+	"ftm-gas-monetization/internal/logger"
+	"ftm-gas-monetization/internal/repository/rpc/contracts"
+	"ftm-gas-monetization/internal/repository/storage"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"log"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// LogsHandlerTestSuite tests the event handlers and the epoch closing against the in-memory storage.
+type LogsHandlerTestSuite struct {
+	suite.Suite
+	storage       *storage.Memory
+	gm            *gasMonetization
+	blkDispatcher blkDispatcher
+}
+
+func TestLogsHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(LogsHandlerTestSuite))
+}
+
+// SetupTest sets up the block dispatcher over an empty storage
+func (s *LogsHandlerTestSuite) SetupTest() {
+	s.storage = storage.NewMemory()
+	s.gm = &gasMonetization{
+		address:           types.Address{Address: common.HexToAddress("0x6000000000000000000000000000000000000006")},
+		watchedContracts:  make(map[common.Address]*types.Project),
+		watchedProjectIds: make(map[uint64]*types.Project),
+	}
+	s.blkDispatcher = blkDispatcher{
+		service: service{
+			log: logger.New(log.Writer(), "test", logging.ERROR),
+		},
+		storage:        s.storage,
+		deployments:    map[common.Address]*gasMonetization{s.gm.address.Address: s.gm},
+		currentEpochId: 10,
+	}
+	abi, err := contracts.GasMonetizationMetaData.GetAbi()
+	assert.Nil(s.T(), err)
+	events, err := contracts.NewGasMonetizationFilterer(s.gm.address.Address, nil)
+	assert.Nil(s.T(), err)
+	s.blkDispatcher.events = s.blkDispatcher.eventRegistry(abi, events)
+	s.blkDispatcher.topics, _, err = s.blkDispatcher.events.topics()
+	assert.Nil(s.T(), err)
+}
+
+// TestProjectAdded tests the added project is stored and watched along with its contracts
+func (s *LogsHandlerTestSuite) TestProjectAdded() {
+	project := s.addProject(1)
+
+	projects, err := s.storage.FindProjects(context.Background(), storage.ProjectFilter{Contract: &s.gm.address})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), projects, 1)
+	assert.EqualValues(s.T(), 1, projects[0].ProjectId)
+	assert.Equal(s.T(), projectOwner.Hex(), projects[0].OwnerAddress.Hex())
+	assert.EqualValues(s.T(), 10, projects[0].ActiveFromEpoch)
+	assert.Same(s.T(), project, s.gm.watchedProjectIds[1])
+
+	contracts, err := s.storage.FindProjectContracts(context.Background(), storage.ProjectContractFilter{ProjectId: &project.Id})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), contracts, len(projectContracts))
+	for i, c := range contracts {
+		assert.True(s.T(), c.Approved)
+		assert.Equal(s.T(), projectContracts[i].Hex(), c.Address.Hex())
+		assert.Same(s.T(), project, s.gm.watchedContracts[c.Address.Address])
+	}
+
+	// the initial attributes are recorded
+	history, err := s.storage.FindProjectHistory(context.Background(), storage.ProjectHistoryFilter{ProjectId: &project.Id})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), history, 3)
+
+	// the project can not be registered twice
+	err = s.blkDispatcher.handleProjectAdded(context.Background(), s.projectAddedEvent(1), s.storage)
+	assert.NotNil(s.T(), err)
+}
+
+// TestProjectSuspended tests the suspended project is no longer watched
+func (s *LogsHandlerTestSuite) TestProjectSuspended() {
+	project := s.addProject(1)
+
+	err := s.blkDispatcher.handleProjectSuspended(context.Background(), &contracts.GasMonetizationProjectSuspended{
+		ProjectId:              big.NewInt(1),
+		SuspendedOnEpochNumber: big.NewInt(12),
+		Raw:                    eth.Log{Address: s.gm.address.Address, BlockNumber: 120},
+	}, s.storage)
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), s.gm.watchedProjectIds)
+	assert.Empty(s.T(), s.gm.watchedContracts)
+
+	epoch := uint64(12)
+	active, err := s.storage.FindProjects(context.Background(), storage.ProjectFilter{Id: &project.Id, ActiveInEpoch: &epoch})
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), active)
+}
+
+// TestCloseEpoch tests the rewards of the closed epoch are added to the projects and the totals once
+func (s *LogsHandlerTestSuite) TestCloseEpoch() {
+	project := s.addProject(1)
+	hash := types.Hash{Hash: common.HexToHash("0x01")}
+	// two rewarded call frames of the same transaction are counted as a single transaction
+	for _, path := range []string{"", "0"} {
+		err := s.storage.StoreTransaction(context.Background(), &types.Transaction{
+			ProjectId:     project.Id,
+			Hash:          &hash,
+			Epoch:         10,
+			TraceAddress:  path,
+			RewardToClaim: &types.Big{Big: hexutil.Big(*big.NewInt(100))},
+		})
+		assert.Nil(s.T(), err)
+	}
+
+	blk := &types.Block{Number: 200, Epoch: 11}
+	assert.Nil(s.T(), s.blkDispatcher.closeEpoch(context.Background(), s.storage, blk))
+	assert.EqualValues(s.T(), 11, s.blkDispatcher.currentEpochId)
+	assert.EqualValues(s.T(), 200, project.CollectedRewards.ToInt().Int64())
+	assert.EqualValues(s.T(), 200, project.RewardsToClaim.ToInt().Int64())
+	assert.EqualValues(s.T(), 1, project.TransactionsCount)
+
+	stats, err := s.storage.Stats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 200, stats.TotalAmountCollected.ToInt().Int64())
+	assert.EqualValues(s.T(), 1, stats.TotalTransactionsCount)
+
+	// the sealed epoch is not counted again when the block is retried
+	s.blkDispatcher.currentEpochId = 10
+	assert.Nil(s.T(), s.blkDispatcher.closeEpoch(context.Background(), s.storage, blk))
+	stats, err = s.storage.Stats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 200, stats.TotalAmountCollected.ToInt().Int64())
+	assert.EqualValues(s.T(), 200, project.CollectedRewards.ToInt().Int64())
+}
+
+// TestLateBlock tests a repeated block of a sealed epoch is skipped and a late one adds its rewards to the sealed epoch
+func (s *LogsHandlerTestSuite) TestLateBlock() {
+	project := s.addProject(1)
+	assert.Nil(s.T(), s.blkDispatcher.closeEpoch(context.Background(), s.storage, &types.Block{Number: 200, Epoch: 11}))
+	assert.Nil(s.T(), s.storage.UpdateLastProcessedBlock(context.Background(), 300))
+
+	// the block was processed already, its transactions are not loaded again
+	hash := common.HexToHash("0x01")
+	assert.True(s.T(), s.blkDispatcher.processTxs(&types.Block{Number: 150, Epoch: 10, Txs: []*common.Hash{&hash}}))
+	assert.EqualValues(s.T(), 11, s.blkDispatcher.currentEpochId)
+	processed, err := s.blkDispatcher.lateBlockProcessed(context.Background(), s.storage, &types.Block{Number: 301, Epoch: 10})
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), processed)
+
+	// the rewards of a late block are added to the project and the totals
+	err = s.blkDispatcher.addRewards(context.Background(), s.storage, []types.Transaction{{
+		ProjectId:     project.Id,
+		Hash:          &types.Hash{Hash: hash},
+		Epoch:         10,
+		RewardToClaim: &types.Big{Big: hexutil.Big(*big.NewInt(100))},
+	}})
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 100, project.CollectedRewards.ToInt().Int64())
+	assert.EqualValues(s.T(), 1, project.TransactionsCount)
+	stats, err := s.storage.Stats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 100, stats.TotalAmountCollected.ToInt().Int64())
+	assert.EqualValues(s.T(), 1, stats.TotalTransactionsCount)
+}
+
+// TestWithdrawalCompleted tests the completed withdrawal moves the rewards to claimed and drops the old transactions
+func (s *LogsHandlerTestSuite) TestWithdrawalCompleted() {
+	project := s.addProject(1)
+	project.RewardsToClaim = &types.Big{Big: hexutil.Big(*big.NewInt(300))}
+	assert.Nil(s.T(), s.storage.UpdateProject(context.Background(), project))
+	for _, epoch := range []hexutil.Uint64{10, 11} {
+		err := s.storage.StoreTransaction(context.Background(), &types.Transaction{
+			ProjectId:     project.Id,
+			Hash:          &types.Hash{Hash: common.BigToHash(big.NewInt(int64(epoch)))},
+			Epoch:         epoch,
+			RewardToClaim: &types.Big{Big: hexutil.Big(*big.NewInt(100))},
+		})
+		assert.Nil(s.T(), err)
+	}
+	err := s.storage.StoreWithdrawalRequest(context.Background(), &types.WithdrawalRequest{
+		ProjectId:    project.Id,
+		RequestEpoch: 10,
+	})
+	assert.Nil(s.T(), err)
+
+	err = s.blkDispatcher.handleWithdrawalCompleted(context.Background(), &contracts.GasMonetizationWithdrawalCompleted{
+		ProjectId:             big.NewInt(1),
+		RequestEpochNumber:    big.NewInt(10),
+		WithdrawalEpochNumber: big.NewInt(11),
+		Amount:                big.NewInt(200),
+		Raw:                   eth.Log{Address: s.gm.address.Address, BlockNumber: 110},
+	}, s.storage)
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 200, project.ClaimedRewards.ToInt().Int64())
+	assert.EqualValues(s.T(), 100, project.RewardsToClaim.ToInt().Int64())
+	assert.EqualValues(s.T(), 11, *project.LastWithdrawalEpoch)
+
+	requests, err := s.storage.FindWithdrawalRequests(context.Background(), storage.WithdrawalRequestFilter{NotWithdrawn: true})
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), requests)
+
+	// only the transactions of the withdrawal epoch are kept
+	txs, err := s.storage.FindTransactions(context.Background(), storage.TransactionFilter{ProjectId: &project.Id})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), txs, 1)
+	assert.EqualValues(s.T(), 11, txs[0].Epoch)
+
+	stats, err := s.storage.Stats(context.Background())
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), 200, stats.TotalAmountClaimed.ToInt().Int64())
+}
+
+// TestFailedHandler tests the event of a failed handler is stored once the changes of the block are discarded
+func (s *LogsHandlerTestSuite) TestFailedHandler() {
+	// the suspended project is not watched, so the handler fails
+	log := s.packLog("ProjectSuspended", 120, 3, big.NewInt(1), big.NewInt(12))
+
+	var err error
+	done := make(chan error, 1)
+	go func() {
+		done <- s.blkDispatcher.atomic(context.Background(), func(ctx context.Context, st storage.Storage) error {
+			// the change made before the failure is discarded
+			if err := st.UpdateLastProcessedBlock(ctx, 120); err != nil {
+				return err
+			}
+			return s.blkDispatcher.processLog(ctx, s.gm, &log, st)
+		})
+	}()
+	select {
+	case err = <-done:
+		assert.ErrorContains(s.T(), err, "is not watched")
+	case <-time.After(5 * time.Second):
+		s.T().Fatal("storing the failed event blocked the storage")
+	}
+
+	last, err := s.storage.LastProcessedBlock(context.Background())
+	assert.Nil(s.T(), err)
+	assert.Zero(s.T(), last)
+
+	stored, err := s.storage.FindContractEvents(context.Background(), storage.ContractEventFilter{})
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), stored, 1)
+	assert.Equal(s.T(), types.ContractEventFailed, stored[0].Outcome)
+	assert.EqualValues(s.T(), 3, stored[0].LogIndex)
+	assert.Contains(s.T(), *stored[0].Error, "is not watched")
+}
+
+// TestReplay tests the blocks processed again leave the state unchanged
+func (s *LogsHandlerTestSuite) TestReplay() {
+	logs := []eth.Log{
+		s.packLog("ProjectAdded", 100, 0, big.NewInt(1), projectOwner.Address, projectRecipient.Address, projectUrl,
+			big.NewInt(10), []common.Address{projectContracts[0].Address}),
+		s.packLog("ProjectContractAdded", 110, 1, big.NewInt(1), projectContracts[1].Address),
+		s.packLog("ProjectRewardsRecipientUpdated", 120, 2, big.NewInt(1), projectOwner.Address),
+		s.packLog("ProjectMetadataUriUpdated", 130, 3, big.NewInt(1), "/updated.json"),
+	}
+	replay := func() {
+		err := s.blkDispatcher.atomic(context.Background(), func(ctx context.Context, st storage.Storage) error {
+			for i := range logs {
+				if err := s.blkDispatcher.processLog(ctx, s.gm, &logs[i], st); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		assert.Nil(s.T(), err)
+	}
+
+	replay()
+	first := s.snapshot()
+	assert.Len(s.T(), first.projects, 1)
+	assert.Len(s.T(), first.contracts, 2)
+	assert.Len(s.T(), first.history, 5)
+	assert.Len(s.T(), first.events, len(logs))
+	replay()
+	assert.Equal(s.T(), first, s.snapshot())
+	assert.Len(s.T(), s.gm.watchedContracts, 2)
+}
+
+// storageSnapshot represents the registry state stored by the event handlers
+type storageSnapshot struct {
+	projects  []types.Project
+	contracts []types.ProjectContract
+	history   []types.ProjectHistory
+	events    []types.ContractEvent
+}
+
+// snapshot returns the registry state of the storage
+func (s *LogsHandlerTestSuite) snapshot() storageSnapshot {
+	var snapshot storageSnapshot
+	var err error
+	ctx := context.Background()
+	snapshot.projects, err = s.storage.FindProjects(ctx, storage.ProjectFilter{})
+	assert.Nil(s.T(), err)
+	snapshot.contracts, err = s.storage.FindProjectContracts(ctx, storage.ProjectContractFilter{})
+	assert.Nil(s.T(), err)
+	snapshot.history, err = s.storage.FindProjectHistory(ctx, storage.ProjectHistoryFilter{})
+	assert.Nil(s.T(), err)
+	snapshot.events, err = s.storage.FindContractEvents(ctx, storage.ContractEventFilter{})
+	assert.Nil(s.T(), err)
+	return snapshot
+}
+
+// packLog creates the log record of the given event emitted by the test gas monetization contract,
+// the values are given in the order of the event inputs
+func (s *LogsHandlerTestSuite) packLog(name string, block uint64, index uint, values ...interface{}) eth.Log {
+	meta, err := contracts.GasMonetizationMetaData.GetAbi()
+	assert.Nil(s.T(), err)
+	event := meta.Events[name]
+	var indexed [][]interface{}
+	var data []interface{}
+	for i, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, []interface{}{values[i]})
+		} else {
+			data = append(data, values[i])
+		}
+	}
+	topics, err := abi.MakeTopics(indexed...)
+	assert.Nil(s.T(), err)
+	packed, err := event.Inputs.NonIndexed().Pack(data...)
+	assert.Nil(s.T(), err)
+	log := eth.Log{
+		Address:     s.gm.address.Address,
+		Topics:      []common.Hash{event.ID},
+		Data:        packed,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+		Index:       index,
+	}
+	for _, t := range topics {
+		log.Topics = append(log.Topics, t[0])
+	}
+	return log
+}
+
+// addProject registers a project of the given id with the test contracts
+func (s *LogsHandlerTestSuite) addProject(projectId int64) *types.Project {
+	err := s.blkDispatcher.handleProjectAdded(context.Background(), s.projectAddedEvent(projectId), s.storage)
+	assert.Nil(s.T(), err)
+	return s.gm.watchedProjectIds[uint64(projectId)]
+}
+
+// projectAddedEvent creates the ProjectAdded event of the given project id
+func (s *LogsHandlerTestSuite) projectAddedEvent(projectId int64) *contracts.GasMonetizationProjectAdded {
+	return &contracts.GasMonetizationProjectAdded{
+		ProjectId:        big.NewInt(projectId),
+		Owner:            projectOwner.Address,
+		RewardsRecipient: projectRecipient.Address,
+		MetadataUri:      projectUrl,
+		ActiveFromEpoch:  big.NewInt(10),
+		Contracts:        []common.Address{projectContracts[0].Address, projectContracts[1].Address},
+		Raw:              eth.Log{Address: s.gm.address.Address, BlockNumber: 100},
+	}
+}
@@ -17,8 +17,11 @@ type Manager struct {
 	log  *logger.AppLogger
 
 	// managed services
-	blkScanner    *blkScanner
-	blkDispatcher *blkDispatcher
+	blkScanner          *blkScanner
+	blkDispatcher       *blkDispatcher
+	metadataFetcher     *metadataFetcher
+	metadataRefresher   *metadataRefresher
+	partitionMaintainer *partitionMaintainer
 }
 
 func New(cfg *config.Config, repo *repository.Repository, log *logger.AppLogger) *Manager {
@@ -85,9 +88,42 @@ func (mgr *Manager) init() {
 			log:  mgr.log.ModuleLogger("blk_scanner"),
 			mgr:  mgr,
 		},
-		notifier: notifier.NewSlackNotifier(mgr.cfg.Slack.Token, mgr.cfg.Slack.ChannelId),
+		storage:     mgr.repo.Storage(),
+		notifier:    notifier.NewSlackNotifier(mgr.cfg.Slack.Token, mgr.cfg.Slack.ChannelId),
+		attribution: &mgr.cfg.Attribution,
+		partitions:  &mgr.cfg.Partitions,
 	}
 	mgr.svc = append(mgr.svc, mgr.blkDispatcher)
+
+	mgr.partitionMaintainer = &partitionMaintainer{
+		service: service{
+			repo: mgr.repo,
+			log:  mgr.log.ModuleLogger("partition_maintainer"),
+			mgr:  mgr,
+		},
+		cfg: &mgr.cfg.Partitions,
+	}
+	mgr.svc = append(mgr.svc, mgr.partitionMaintainer)
+
+	mgr.metadataFetcher = &metadataFetcher{
+		service: service{
+			repo: mgr.repo,
+			log:  mgr.log.ModuleLogger("metadata_fetcher"),
+			mgr:  mgr,
+		},
+		cfg: &mgr.cfg.Metadata,
+	}
+	mgr.svc = append(mgr.svc, mgr.metadataFetcher)
+
+	mgr.metadataRefresher = &metadataRefresher{
+		service: service{
+			repo: mgr.repo,
+			log:  mgr.log.ModuleLogger("metadata_refresher"),
+			mgr:  mgr,
+		},
+		cfg: &mgr.cfg.Metadata,
+	}
+	mgr.svc = append(mgr.svc, mgr.metadataRefresher)
 }
 
 // started signals to the manager that the calling service
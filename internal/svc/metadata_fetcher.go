@@ -0,0 +1,344 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/repository/db"
+	"ftm-gas-monetization/internal/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxMetadataRedirects is the maximal number of redirects followed when fetching a metadata document.
+const maxMetadataRedirects = 5
+
+// reservedNetworks lists the special purpose networks not covered by the checks of the net package,
+// metadata hosts must not resolve into them.
+var reservedNetworks = mustParseNetworks(
+	"0.0.0.0/8",       // this network
+	"100.64.0.0/10",   // shared address space
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"240.0.0.0/4",     // reserved, including broadcast
+	"64:ff9b::/96",    // IPv4/IPv6 translation
+	"2001:db8::/32",   // documentation
+)
+
+// metadataFetcher implements a service responsible for fetching metadata of projects. The metadata
+// are fetched outside of the block processing, so a slow metadata host never stalls the indexing.
+// Failed fetches are retried with an exponential backoff.
+type metadataFetcher struct {
+	service
+	cfg       *config.Metadata
+	client    *http.Client
+	resolvers map[string]metadataUriResolver
+	tick      *time.Ticker
+}
+
+// name returns the name of the service used by orchestrator.
+func (mf *metadataFetcher) name() string {
+	return "metadata fetcher"
+}
+
+// init prepares the metadata fetcher to perform its function.
+func (mf *metadataFetcher) init() {
+	mf.sigStop = make(chan struct{})
+	mf.client = newMetadataClient(mf.cfg)
+	mf.resolvers = newMetadataUriResolvers(mf.cfg.IpfsGateways, mf.cfg.ArweaveGateways)
+	// the ticker is ready before the fetcher runs, so it can always be stopped on close
+	mf.tick = time.NewTicker(time.Duration(mf.cfg.Interval) * time.Second)
+}
+
+// run starts the metadata fetcher.
+func (mf *metadataFetcher) run() {
+	// signal orchestrator we started and go
+	mf.mgr.started(mf)
+	go mf.execute()
+}
+
+// close signals the metadata fetcher to terminate.
+func (mf *metadataFetcher) close() {
+	if mf.tick != nil {
+		mf.tick.Stop()
+	}
+	if mf.sigStop != nil {
+		close(mf.sigStop)
+	}
+}
+
+// execute periodically fetches metadata of projects due to be fetched.
+func (mf *metadataFetcher) execute() {
+	defer mf.mgr.finished(mf)
+
+	for {
+		select {
+		case <-mf.sigStop:
+			return
+		case <-mf.tick.C:
+			mf.fetchDue()
+		}
+	}
+}
+
+// fetchDue fetches metadata of projects due to be fetched, the longest waiting first.
+// A single check is limited to the configured batch size, the rest waits for the next tick.
+func (mf *metadataFetcher) fetchDue() {
+	pq := mf.repo.ProjectQuery()
+	projects, err := pq.WhereMetadataDue(time.Now()).
+		OrderBy(db.ProjectColumns.MetadataNextFetch, db.Asc).
+		Limit(uint64(mf.cfg.BatchSize)).
+		GetAll()
+	if err != nil {
+		mf.log.Errorf("failed to get projects with metadata due; %s", err.Error())
+		return
+	}
+	for i := range projects {
+		mf.fetch(&projects[i])
+	}
+}
+
+// fetch fetches metadata of the given project and stores the outcome.
+func (mf *metadataFetcher) fetch(project *types.Project) {
+	metadata, raw, err := mf.download(project.Url)
+	now := time.Now()
+	var invalid *metadataValidationError
+	switch {
+	case errors.As(err, &invalid):
+		// the document may be fixed by the owner at the same URI, check it again after the longest delay
+		msg := err.Error()
+		project.MetadataStatus = types.ProjectMetadataInvalid
+		project.MetadataError = &msg
+		project.MetadataValidationErrors = invalid.errors
+		project.MetadataAttempts++
+		project.MetadataNextFetch = now.Add(time.Duration(mf.cfg.MaxRetryDelay) * time.Second)
+		mf.log.Warningf("metadata of project #%d are invalid; %s", project.ProjectId, msg)
+	case err != nil:
+		msg := err.Error()
+		project.MetadataStatus = types.ProjectMetadataFailed
+		project.MetadataError = &msg
+		project.MetadataAttempts++
+		project.MetadataNextFetch = now.Add(mf.backoff(project.MetadataAttempts))
+		mf.log.Warningf("failed to fetch metadata of project #%d, attempt %d; %s", project.ProjectId, project.MetadataAttempts, msg)
+	default:
+		project.Metadata = metadata
+		project.Name = metadata.Name
+		project.ImageUrl = metadata.Image()
+		project.MetadataStatus = types.ProjectMetadataOk
+		project.MetadataError = nil
+		project.MetadataValidationErrors = nil
+		project.MetadataAttempts = 0
+		project.MetadataNextFetch = now
+		project.MetadataFetchedAt = &now
+	}
+	updated, err := mf.repo.UpdateProjectMetadata(project)
+	if err != nil {
+		mf.log.Errorf("failed to store metadata of project #%d; %s", project.ProjectId, err.Error())
+		return
+	}
+	if !updated {
+		mf.log.Infof("metadata URI of project #%d changed while fetching, result dropped", project.ProjectId)
+		return
+	}
+	if metadata != nil {
+		mf.archive(project, metadata, raw, now)
+	}
+}
+
+// archive stores the fetched metadata document as a new version of the project metadata, unless it did not change.
+func (mf *metadataFetcher) archive(project *types.Project, metadata *types.ProjectMetadata, raw []byte, fetchedAt time.Time) {
+	stored, err := mf.repo.StoreProjectMetadataVersion(&types.ProjectMetadataVersion{
+		ProjectId:     project.Id,
+		Uri:           project.Url,
+		ContentHash:   &types.Hash{Hash: crypto.Keccak256Hash(raw)},
+		Content:       string(raw),
+		SchemaVersion: metadata.Version,
+		FetchedAt:     fetchedAt,
+	})
+	if err != nil {
+		mf.log.Errorf("failed to store metadata version of project #%d; %s", project.ProjectId, err.Error())
+		return
+	}
+	if stored {
+		mf.log.Infof("new metadata version of project #%d archived", project.ProjectId)
+	}
+}
+
+// download resolves the given metadata URI and decodes the metadata document. The raw document is returned as well.
+// Documents available at multiple gateways are downloaded from the first one responding successfully.
+func (mf *metadataFetcher) download(uri string) (*types.ProjectMetadata, []byte, error) {
+	if uri == "" {
+		return nil, nil, fmt.Errorf("metadata URI not set")
+	}
+	src, err := resolveMetadataUri(mf.resolvers, uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	if src.urls == nil {
+		metadata, err := mf.decode(src.contentType, src.data)
+		return metadata, src.data, err
+	}
+	for _, url := range src.urls {
+		var metadata *types.ProjectMetadata
+		var raw []byte
+		metadata, raw, err = mf.get(url)
+		if err == nil {
+			return metadata, raw, nil
+		}
+		if len(src.urls) > 1 {
+			mf.log.Debugf("failed to get metadata from %s; %s", url, err.Error())
+		}
+	}
+	return nil, nil, err
+}
+
+// get downloads and decodes the metadata document of the given HTTP(S) URL.
+func (mf *metadataFetcher) get(url string) (*types.ProjectMetadata, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(mf.cfg.Timeout)*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid metadata URI: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := mf.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get project metadata: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	// read one byte over the limit to detect oversized documents
+	body, err := io.ReadAll(io.LimitReader(resp.Body, mf.cfg.MaxSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read project metadata: %v", err)
+	}
+	metadata, err := mf.decode(resp.Header.Get("Content-Type"), body)
+	return metadata, body, err
+}
+
+// decode checks, validates and decodes the metadata document of the given content type.
+func (mf *metadataFetcher) decode(contentType string, body []byte) (*types.ProjectMetadata, error) {
+	if err := checkMetadataContentType(contentType); err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > mf.cfg.MaxSize {
+		return nil, fmt.Errorf("project metadata exceed %d bytes", mf.cfg.MaxSize)
+	}
+	return validateProjectMetadata(body)
+}
+
+// backoff returns the delay of the next fetch after the given number of failed attempts.
+func (mf *metadataFetcher) backoff(attempts int) time.Duration {
+	delay := time.Duration(mf.cfg.RetryDelay) * time.Second
+	limit := time.Duration(mf.cfg.MaxRetryDelay) * time.Second
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		return limit
+	}
+	return delay
+}
+
+// newMetadataClient creates the HTTP client fetching metadata documents. Unless private hosts are allowed,
+// the client refuses to connect to private, loopback, link-local and reserved addresses. The address is checked
+// after the host name is resolved, for every connection including the ones of redirects.
+func newMetadataClient(cfg *config.Metadata) *http.Client {
+	dialer := &net.Dialer{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	if !cfg.AllowPrivateHosts {
+		dialer.Control = checkMetadataHostAddress
+	}
+	return &http.Client{
+		Timeout: time.Duration(cfg.Timeout) * time.Second,
+		// no proxy, the proxy would connect to the checked hosts on our behalf
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: time.Duration(cfg.Timeout) * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxMetadataRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxMetadataRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// checkMetadataHostAddress checks the resolved address of a metadata host is publicly routable.
+func checkMetadataHostAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid metadata host address %s; %s", address, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid metadata host address %s", address)
+	}
+	if !isPublicAddress(ip) {
+		return fmt.Errorf("metadata host address %s is not public", ip)
+	}
+	return nil
+}
+
+// isPublicAddress checks the IP address is not private, loopback, link-local, multicast or otherwise reserved.
+func isPublicAddress(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// mustParseNetworks parses the given CIDR notations of networks.
+func mustParseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// checkMetadataContentType checks the content type of the metadata document is acceptable.
+// Plain text is accepted as well, because many hosts and gateways serve JSON documents that way.
+func checkMetadataContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %s", contentType)
+	}
+	if mediaType == "application/json" || mediaType == "text/plain" || strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	return fmt.Errorf("unexpected content type %s", mediaType)
+}
@@ -0,0 +1,102 @@
+package svc
+
+import (
+	"ftm-gas-monetization/internal/config"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMetadataFetcherDownload tests the metadata document is downloaded with the configured limits
+func TestMetadataFetcherDownload(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"name": "Test project", "image_url": "test.png"}`))
+		case "/plain":
+			_, _ = w.Write([]byte(`{"name": "Test project"}`))
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name": "` + strings.Repeat("x", 1024) + `"}`))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html></html>`))
+		case "/slow":
+			time.Sleep(2 * time.Second)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	mf := metadataFetcher{cfg: &config.Metadata{Interval: 15, Timeout: 1, MaxSize: 512, AllowPrivateHosts: true}}
+	mf.init()
+
+	metadata, raw, err := mf.download(ts.URL + "/ok")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name": "Test project", "image_url": "test.png"}`, string(raw))
+	assert.Equal(t, "Test project", metadata.Name)
+	assert.Equal(t, "test.png", metadata.ImageUrl)
+
+	metadata, _, err = mf.download(ts.URL + "/plain")
+	assert.Nil(t, err)
+	assert.Equal(t, "Test project", metadata.Name)
+
+	for _, path := range []string{"/large", "/html", "/slow", "/missing"} {
+		_, _, err = mf.download(ts.URL + path)
+		assert.NotNil(t, err, path)
+	}
+	_, _, err = mf.download("")
+	assert.NotNil(t, err)
+}
+
+// TestMetadataFetcherBackoff tests the retry delay doubles with each failure up to the limit
+func TestMetadataFetcherBackoff(t *testing.T) {
+	mf := metadataFetcher{cfg: &config.Metadata{RetryDelay: 60, MaxRetryDelay: 300}}
+	assert.Equal(t, time.Minute, mf.backoff(1))
+	assert.Equal(t, 2*time.Minute, mf.backoff(2))
+	assert.Equal(t, 4*time.Minute, mf.backoff(3))
+	assert.Equal(t, 5*time.Minute, mf.backoff(4))
+	assert.Equal(t, 5*time.Minute, mf.backoff(100))
+}
+
+// TestMetadataFetcherRejectsPrivateHosts tests metadata are not fetched from hosts at private and reserved addresses
+func TestMetadataFetcherRejectsPrivateHosts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte(`{"name": "Test project"}`))
+		case "/file":
+			http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	mf := metadataFetcher{cfg: &config.Metadata{Interval: 15, Timeout: 1, MaxSize: 512}}
+	mf.init()
+	_, _, err := mf.download(ts.URL + "/ok")
+	assert.ErrorContains(t, err, "is not public")
+
+	// redirects are allowed to the web only
+	mf = metadataFetcher{cfg: &config.Metadata{Interval: 15, Timeout: 1, MaxSize: 512, AllowPrivateHosts: true}}
+	mf.init()
+	_, _, err = mf.download(ts.URL + "/file")
+	assert.ErrorContains(t, err, "unsupported scheme")
+
+	for _, address := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1",
+		"192.0.2.1", "198.18.0.1", "240.0.0.1", "255.255.255.255", "224.0.0.1",
+		"::1", "::", "fe80::1", "fc00::1", "ff02::1", "2001:db8::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+	} {
+		assert.False(t, isPublicAddress(net.ParseIP(address)), address)
+	}
+	for _, address := range []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"} {
+		assert.True(t, isPublicAddress(net.ParseIP(address)), address)
+	}
+}
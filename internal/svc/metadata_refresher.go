@@ -0,0 +1,72 @@
+package svc
+
+import (
+	"ftm-gas-monetization/internal/config"
+	"time"
+)
+
+// metadataRefreshTickDuration represents the period of checks for project metadata due to be refreshed.
+const metadataRefreshTickDuration = 10 * time.Minute
+
+// metadataRefresher implements a service responsible for refreshing metadata of active projects.
+// Projects with metadata older than the refresh interval are scheduled for the metadata fetcher,
+// which records a new metadata version when the document changed.
+type metadataRefresher struct {
+	service
+	cfg  *config.Metadata
+	tick *time.Ticker
+}
+
+// name returns the name of the service used by orchestrator.
+func (mr *metadataRefresher) name() string {
+	return "metadata refresher"
+}
+
+// init prepares the metadata refresher to perform its function.
+func (mr *metadataRefresher) init() {
+	mr.sigStop = make(chan struct{})
+	mr.tick = time.NewTicker(metadataRefreshTickDuration)
+}
+
+// run starts the metadata refresher.
+func (mr *metadataRefresher) run() {
+	// signal orchestrator we started and go
+	mr.mgr.started(mr)
+	go mr.execute()
+}
+
+// close signals the metadata refresher to terminate.
+func (mr *metadataRefresher) close() {
+	if mr.tick != nil {
+		mr.tick.Stop()
+	}
+	if mr.sigStop != nil {
+		close(mr.sigStop)
+	}
+}
+
+// execute periodically schedules metadata of active projects to be refreshed.
+func (mr *metadataRefresher) execute() {
+	defer mr.mgr.finished(mr)
+
+	for {
+		select {
+		case <-mr.sigStop:
+			return
+		case <-mr.tick.C:
+			mr.refresh()
+		}
+	}
+}
+
+// refresh schedules metadata of active projects fetched before the refresh interval to be fetched again.
+func (mr *metadataRefresher) refresh() {
+	count, err := mr.repo.ScheduleProjectMetadataRefresh(time.Now().Add(-time.Duration(mr.cfg.RefreshInterval) * time.Second))
+	if err != nil {
+		mr.log.Errorf("failed to schedule metadata refresh; %s", err.Error())
+		return
+	}
+	if count > 0 {
+		mr.log.Infof("metadata of %d projects scheduled for refresh", count)
+	}
+}
@@ -0,0 +1,122 @@
+package svc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// metadataSource represents a resolved location of a project metadata document. It is either a list of HTTP(S)
+// URLs the document is available at, tried in the order given, or the document itself for inline URIs.
+type metadataSource struct {
+	urls        []string
+	data        []byte
+	contentType string
+}
+
+// metadataUriResolver resolves a metadata URI of a particular scheme into the metadata source.
+type metadataUriResolver func(uri string) (*metadataSource, error)
+
+// newMetadataUriResolvers creates resolvers of the supported metadata URI schemes.
+// Content addressed schemes are resolved through the given gateways.
+func newMetadataUriResolvers(ipfsGateways []string, arweaveGateways []string) map[string]metadataUriResolver {
+	return map[string]metadataUriResolver{
+		"http":  resolveHttpUri,
+		"https": resolveHttpUri,
+		"ipfs":  gatewayUriResolver(ipfsGateways, ipfsPath),
+		"ar":    gatewayUriResolver(arweaveGateways, arweavePath),
+		"data":  resolveDataUri,
+	}
+}
+
+// resolveMetadataUri resolves the given metadata URI by the resolver of its scheme.
+func resolveMetadataUri(resolvers map[string]metadataUriResolver, uri string) (*metadataSource, error) {
+	scheme, _, found := strings.Cut(uri, ":")
+	if !found {
+		return nil, fmt.Errorf("metadata URI %s has no scheme", uri)
+	}
+	resolve, ok := resolvers[strings.ToLower(scheme)]
+	if !ok {
+		return nil, fmt.Errorf("metadata URI scheme %s not supported", scheme)
+	}
+	return resolve(uri)
+}
+
+// resolveHttpUri resolves a plain HTTP(S) URI.
+func resolveHttpUri(uri string) (*metadataSource, error) {
+	if _, err := url.ParseRequestURI(uri); err != nil {
+		return nil, fmt.Errorf("invalid metadata URI: %v", err)
+	}
+	return &metadataSource{urls: []string{uri}}, nil
+}
+
+// gatewayUriResolver creates a resolver of content addressed URIs served by the given HTTP gateways.
+// The path function extracts the gateway path of the content from the URI.
+func gatewayUriResolver(gateways []string, path func(string) (string, error)) metadataUriResolver {
+	return func(uri string) (*metadataSource, error) {
+		if len(gateways) == 0 {
+			return nil, fmt.Errorf("no gateway configured for metadata URI %s", uri)
+		}
+		p, err := path(uri)
+		if err != nil {
+			return nil, err
+		}
+		src := &metadataSource{urls: make([]string, len(gateways))}
+		for i, gw := range gateways {
+			src.urls[i] = strings.TrimSuffix(gw, "/") + "/" + p
+		}
+		return src, nil
+	}
+}
+
+// ipfsPath extracts the CID and the optional path from the ipfs://<cid>/<path> URI.
+// The legacy ipfs://ipfs/<cid> form is accepted as well.
+func ipfsPath(uri string) (string, error) {
+	p := strings.TrimPrefix(uri[len("ipfs:"):], "//")
+	p = strings.TrimPrefix(p, "ipfs/")
+	if p == "" || strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("invalid IPFS URI %s", uri)
+	}
+	return p, nil
+}
+
+// arweavePath extracts the transaction id and the optional path from the ar://<tx>/<path> URI.
+func arweavePath(uri string) (string, error) {
+	p := strings.TrimPrefix(uri[len("ar:"):], "//")
+	if p == "" || strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("invalid Arweave URI %s", uri)
+	}
+	return p, nil
+}
+
+// resolveDataUri decodes the inline document of the data:[<media type>][;base64],<data> URI.
+func resolveDataUri(uri string) (*metadataSource, error) {
+	header, payload, found := strings.Cut(uri[len("data:"):], ",")
+	if !found {
+		return nil, fmt.Errorf("invalid data URI, missing payload")
+	}
+	contentType, isBase64 := strings.CutSuffix(header, ";base64")
+	if contentType == "" {
+		// default media type defined by RFC 2397
+		contentType = "text/plain;charset=US-ASCII"
+	}
+	var data []byte
+	if isBase64 {
+		var err error
+		data, err = base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			// some encoders omit the padding
+			if data, err = base64.RawStdEncoding.DecodeString(payload); err != nil {
+				return nil, fmt.Errorf("invalid base64 payload of data URI: %v", err)
+			}
+		}
+	} else {
+		decoded, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payload of data URI: %v", err)
+		}
+		data = []byte(decoded)
+	}
+	return &metadataSource{data: data, contentType: contentType}, nil
+}
@@ -0,0 +1,127 @@
+package svc
+
+import (
+	"encoding/base64"
+	"ftm-gas-monetization/internal/config"
+	"ftm-gas-monetization/internal/logger"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newGatewayStub creates a gateway stub serving the metadata document at the given path only.
+func newGatewayStub(path string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "Test project", "image_url": "test.png"}`))
+	}))
+}
+
+// newResolvingFetcher creates a metadata fetcher resolving content addressed URIs through the given gateways.
+func newResolvingFetcher(ipfsGateways []string, arweaveGateways []string) *metadataFetcher {
+	mf := metadataFetcher{cfg: &config.Metadata{
+		Interval:        15,
+		Timeout:         1,
+		MaxSize:         512,
+		IpfsGateways:    ipfsGateways,
+		ArweaveGateways: arweaveGateways,
+		// the gateway stubs listen on the loopback
+		AllowPrivateHosts: true,
+	}}
+	mf.log = logger.New(log.Writer(), "test", logging.ERROR)
+	mf.init()
+	return &mf
+}
+
+// TestMetadataResolverIpfs tests ipfs:// URIs are resolved through the configured gateways
+func TestMetadataResolverIpfs(t *testing.T) {
+	const cid = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	ts := newGatewayStub("/ipfs/" + cid + "/metadata.json")
+	defer ts.Close()
+	broken := newGatewayStub("/none")
+	defer broken.Close()
+
+	// unavailable gateways are skipped
+	mf := newResolvingFetcher([]string{broken.URL + "/ipfs", ts.URL + "/ipfs/"}, nil)
+	for _, uri := range []string{"ipfs://" + cid + "/metadata.json", "ipfs://ipfs/" + cid + "/metadata.json"} {
+		metadata, _, err := mf.download(uri)
+		assert.Nil(t, err, uri)
+		if assert.NotNil(t, metadata, uri) {
+			assert.Equal(t, "Test project", metadata.Name)
+			assert.Equal(t, "test.png", metadata.ImageUrl)
+		}
+	}
+	_, _, err := mf.download("ipfs://" + cid + "/missing.json")
+	assert.NotNil(t, err)
+	_, _, err = mf.download("ipfs://")
+	assert.NotNil(t, err)
+
+	// no gateway to resolve the URI through
+	mf = newResolvingFetcher(nil, nil)
+	_, _, err = mf.download("ipfs://" + cid + "/metadata.json")
+	assert.NotNil(t, err)
+}
+
+// TestMetadataResolverArweave tests ar:// URIs are resolved through the configured gateways
+func TestMetadataResolverArweave(t *testing.T) {
+	const tx = "bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U"
+	ts := newGatewayStub("/" + tx)
+	defer ts.Close()
+
+	mf := newResolvingFetcher(nil, []string{ts.URL})
+	metadata, _, err := mf.download("ar://" + tx)
+	assert.Nil(t, err)
+	if assert.NotNil(t, metadata) {
+		assert.Equal(t, "Test project", metadata.Name)
+	}
+	_, _, err = mf.download("ar://")
+	assert.NotNil(t, err)
+}
+
+// TestMetadataResolverData tests inline data: URIs are decoded with the configured limits
+func TestMetadataResolverData(t *testing.T) {
+	mf := newResolvingFetcher(nil, nil)
+	doc := `{"name": "Test project", "image_url": "test.png"}`
+
+	metadata, _, err := mf.download("data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(doc)))
+	assert.Nil(t, err)
+	if assert.NotNil(t, metadata) {
+		assert.Equal(t, "Test project", metadata.Name)
+		assert.Equal(t, "test.png", metadata.ImageUrl)
+	}
+
+	// unpadded base64 and percent encoded payloads
+	metadata, _, err = mf.download("data:application/json;base64," + base64.RawStdEncoding.EncodeToString([]byte(`{"name":"A"}`)))
+	assert.Nil(t, err)
+	assert.Equal(t, "A", metadata.Name)
+	metadata, _, err = mf.download(`data:,%7B%22name%22%3A%22Test%20project%22%7D`)
+	assert.Nil(t, err)
+	assert.Equal(t, "Test project", metadata.Name)
+
+	large := make([]byte, 1024)
+	for _, uri := range []string{
+		"data:application/json;base64,not base64",
+		"data:text/html;base64," + base64.StdEncoding.EncodeToString([]byte(doc)),
+		"data:application/json;base64," + base64.StdEncoding.EncodeToString(large),
+		"data:application/json;base64",
+	} {
+		_, _, err = mf.download(uri)
+		assert.NotNil(t, err, uri)
+	}
+}
+
+// TestMetadataResolverSchemes tests URIs of unsupported schemes are rejected
+func TestMetadataResolverSchemes(t *testing.T) {
+	mf := newResolvingFetcher(nil, nil)
+	for _, uri := range []string{"ftp://example.com/metadata.json", "metadata.json", "https://"} {
+		_, _, err := mf.download(uri)
+		assert.NotNil(t, err, uri)
+	}
+}
@@ -0,0 +1,287 @@
+package svc
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ftm-gas-monetization/internal/types"
+	"io/fs"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed schema/project_metadata_v*.json
+var metadataSchemaFiles embed.FS
+
+// metadataSchemas represents the supported versions of the project metadata schema.
+// Version 0 represents legacy documents without the version field.
+var metadataSchemas = mustLoadMetadataSchemas()
+
+// metadataValidationError represents violations of the metadata schema by a metadata document.
+type metadataValidationError struct {
+	version int
+	errors  []string
+}
+
+// Error implements the error interface.
+func (e *metadataValidationError) Error() string {
+	return fmt.Sprintf("metadata do not conform to schema v%d: %s", e.version, strings.Join(e.errors, "; "))
+}
+
+// validateProjectMetadata validates the metadata document against the schema of its version and decodes it.
+func validateProjectMetadata(body []byte) (*types.ProjectMetadata, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal project metadata: %v", err)
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, &metadataValidationError{errors: []string{"/: must be an object"}}
+	}
+	version := 0
+	if v, ok := obj["version"]; ok {
+		n, ok := v.(json.Number)
+		i, err := n.Int64()
+		if !ok || err != nil {
+			return nil, &metadataValidationError{errors: []string{"/version: must be an integer"}}
+		}
+		version = int(i)
+	}
+	schema, ok := metadataSchemas[version]
+	if !ok {
+		return nil, &metadataValidationError{version: version, errors: []string{fmt.Sprintf("/version: unsupported schema version %d", version)}}
+	}
+	if errs := schema.validate("", doc); len(errs) > 0 {
+		return nil, &metadataValidationError{version: version, errors: errs}
+	}
+	var metadata types.ProjectMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal project metadata: %v", err)
+	}
+	return &metadata, nil
+}
+
+// mustLoadMetadataSchemas loads the embedded metadata schemas, versions are numbered from 0 without gaps.
+func mustLoadMetadataSchemas() map[int]*jsonSchema {
+	schemas := make(map[int]*jsonSchema)
+	for version := 0; ; version++ {
+		data, err := metadataSchemaFiles.ReadFile(fmt.Sprintf("schema/project_metadata_v%d.json", version))
+		if errors.Is(err, fs.ErrNotExist) {
+			return schemas
+		}
+		if err != nil {
+			panic(err)
+		}
+		var schema jsonSchema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			panic(fmt.Errorf("invalid metadata schema v%d: %v", version, err))
+		}
+		schemas[version] = &schema
+	}
+}
+
+// jsonSchema represents the subset of JSON Schema used by the metadata schemas.
+// Unsupported keywords are rejected when the schema is loaded, so they can not be ignored silently.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema"`
+	Id                   string                 `json:"$id"`
+	Title                string                 `json:"title"`
+	Description          string                 `json:"description"`
+	Type                 string                 `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	PropertyNames        *jsonSchema            `json:"propertyNames"`
+	MaxProperties        *int                   `json:"maxProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MaxItems             *int                   `json:"maxItems"`
+	UniqueItems          bool                   `json:"uniqueItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Format               string                 `json:"format"`
+
+	// reject represents the false schema, no value is valid
+	reject  bool
+	pattern *regexp.Regexp
+}
+
+// UnmarshalJSON decodes the schema, including boolean schemas.
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = jsonSchema{reject: !b}
+		return nil
+	}
+	type plain jsonSchema
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	switch s.Format {
+	case "", "uri", "email":
+	default:
+		return fmt.Errorf("unsupported format %s", s.Format)
+	}
+	return nil
+}
+
+// validate validates the value at the given JSON pointer path against the schema and returns the violations found.
+func (s *jsonSchema) validate(path string, value interface{}) []string {
+	if s.reject {
+		return []string{pointer(path) + ": not allowed"}
+	}
+	if s.Type != "" && !s.hasType(value) {
+		return []string{fmt.Sprintf("%s: must be %s", pointer(path), s.Type)}
+	}
+	var errs []string
+	if s.Enum != nil && !s.inEnum(value) {
+		errs = append(errs, fmt.Sprintf("%s: must be one of %v", pointer(path), s.Enum))
+	}
+	switch v := value.(type) {
+	case string:
+		errs = append(errs, s.validateString(path, v)...)
+	case []interface{}:
+		errs = append(errs, s.validateArray(path, v)...)
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(path, v)...)
+	}
+	return errs
+}
+
+// hasType checks the value is of the type required by the schema.
+func (s *jsonSchema) hasType(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return s.Type == "string"
+	case json.Number:
+		if s.Type == "integer" {
+			_, err := v.Int64()
+			return err == nil
+		}
+		return s.Type == "number"
+	case bool:
+		return s.Type == "boolean"
+	case []interface{}:
+		return s.Type == "array"
+	case map[string]interface{}:
+		return s.Type == "object"
+	case nil:
+		return s.Type == "null"
+	}
+	return false
+}
+
+// inEnum checks the value is one of the values enumerated by the schema.
+func (s *jsonSchema) inEnum(value interface{}) bool {
+	for _, e := range s.Enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateString validates the string value against the schema.
+func (s *jsonSchema) validateString(path string, value string) (errs []string) {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		errs = append(errs, fmt.Sprintf("%s: must be at least %d characters long", pointer(path), *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		errs = append(errs, fmt.Sprintf("%s: must be at most %d characters long", pointer(path), *s.MaxLength))
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		errs = append(errs, fmt.Sprintf("%s: must match pattern %s", pointer(path), s.Pattern))
+	}
+	switch s.Format {
+	case "uri":
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" {
+			errs = append(errs, fmt.Sprintf("%s: must be an absolute URI", pointer(path)))
+		}
+	case "email":
+		if a, err := mail.ParseAddress(value); err != nil || a.Address != value {
+			errs = append(errs, fmt.Sprintf("%s: must be an email address", pointer(path)))
+		}
+	}
+	return errs
+}
+
+// validateArray validates the array value against the schema.
+func (s *jsonSchema) validateArray(path string, value []interface{}) (errs []string) {
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		errs = append(errs, fmt.Sprintf("%s: must have at most %d items", pointer(path), *s.MaxItems))
+	}
+	for i, item := range value {
+		if s.UniqueItems {
+			for _, other := range value[:i] {
+				if reflect.DeepEqual(item, other) {
+					errs = append(errs, fmt.Sprintf("%s/%d: must be unique", path, i))
+					break
+				}
+			}
+		}
+		if s.Items != nil {
+			errs = append(errs, s.Items.validate(fmt.Sprintf("%s/%d", path, i), item)...)
+		}
+	}
+	return errs
+}
+
+// validateObject validates the object value against the schema, properties are checked in the order of their names.
+func (s *jsonSchema) validateObject(path string, value map[string]interface{}) (errs []string) {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			errs = append(errs, fmt.Sprintf("%s/%s: is required", path, name))
+		}
+	}
+	if s.MaxProperties != nil && len(value) > *s.MaxProperties {
+		errs = append(errs, fmt.Sprintf("%s: must have at most %d properties", pointer(path), *s.MaxProperties))
+	}
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := path + "/" + name
+		if s.PropertyNames != nil {
+			for _, err := range s.PropertyNames.validate(prop, name) {
+				errs = append(errs, "property name "+err)
+			}
+		}
+		if ps, ok := s.Properties[name]; ok {
+			errs = append(errs, ps.validate(prop, value[name])...)
+		} else if s.AdditionalProperties != nil {
+			errs = append(errs, s.AdditionalProperties.validate(prop, value[name])...)
+		}
+	}
+	return errs
+}
+
+// pointer returns the JSON pointer of the given path, the root document is represented by a slash.
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,84 @@
+package svc
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestMetadataSchemaVersions tests every embedded version of the metadata schema is loaded
+func TestMetadataSchemaVersions(t *testing.T) {
+	assert.Contains(t, metadataSchemas, 0)
+	assert.Contains(t, metadataSchemas, 1)
+}
+
+// TestMetadataSchemaValid tests a complete metadata document is validated and decoded
+func TestMetadataSchemaValid(t *testing.T) {
+	metadata, err := validateProjectMetadata([]byte(`{
+		"version": 1,
+		"name": "Test project",
+		"description": "Project used in tests",
+		"website": "https://example.com",
+		"logo": {"small": "https://example.com/small.png", "medium": "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},
+		"socials": {"twitter": "https://twitter.com/example", "discord": "https://discord.gg/example"},
+		"category": "defi",
+		"tags": ["dex", "yield-farming"],
+		"contact": {"email": "team@example.com"}
+	}`))
+	assert.Nil(t, err)
+	if assert.NotNil(t, metadata) {
+		assert.Equal(t, 1, metadata.Version)
+		assert.Equal(t, "Test project", metadata.Name)
+		assert.Equal(t, "defi", metadata.Category)
+		assert.Equal(t, []string{"dex", "yield-farming"}, metadata.Tags)
+		assert.Equal(t, "https://discord.gg/example", metadata.Socials["discord"])
+		assert.Equal(t, "team@example.com", metadata.Contact.Email)
+		assert.Equal(t, "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", metadata.Image())
+	}
+
+	// legacy documents without version keep working
+	metadata, err = validateProjectMetadata([]byte(`{"name": "Test project", "image_url": "test.png", "extra": true}`))
+	assert.Nil(t, err)
+	if assert.NotNil(t, metadata) {
+		assert.Equal(t, 0, metadata.Version)
+		assert.Equal(t, "test.png", metadata.Image())
+	}
+}
+
+// TestMetadataSchemaInvalid tests violations of the metadata schema are reported one by one
+func TestMetadataSchemaInvalid(t *testing.T) {
+	_, err := validateProjectMetadata([]byte(`{
+		"version": 1,
+		"website": "example.com",
+		"category": "unknown",
+		"tags": ["dex", "dex", "Not A Tag"],
+		"socials": {"Twitter": "https://twitter.com/example"},
+		"contact": {"email": "not an email", "phone": "123"},
+		"color": "red"
+	}`))
+	var invalid *metadataValidationError
+	if assert.True(t, errors.As(err, &invalid)) {
+		assert.Equal(t, 1, invalid.version)
+		assert.Equal(t, []string{
+			"/name: is required",
+			"/category: must be one of [defi dex nft gaming infrastructure bridge wallet dao social other]",
+			"/color: not allowed",
+			"/contact/email: must be an email address",
+			"/contact/phone: not allowed",
+			"property name /socials/Twitter: must match pattern ^[a-z0-9_-]{1,32}$",
+			"/tags/1: must be unique",
+			"/tags/2: must match pattern ^[a-z0-9][a-z0-9-]{0,31}$",
+			"/website: must be an absolute URI",
+		}, invalid.errors)
+	}
+
+	for _, doc := range []string{`[]`, `{"version": "1", "name": "A"}`, `{"version": 99, "name": "A"}`, `{"name": ""}`} {
+		_, err = validateProjectMetadata([]byte(doc))
+		assert.True(t, errors.As(err, &invalid), doc)
+	}
+
+	// malformed documents are not validation errors
+	_, err = validateProjectMetadata([]byte(`{"name": `))
+	assert.NotNil(t, err)
+	assert.False(t, errors.As(err, &invalid))
+}
@@ -0,0 +1,91 @@
+package svc
+
+import (
+	"ftm-gas-monetization/internal/config"
+	"time"
+)
+
+// partitionMaintainer implements a service responsible for the epoch partitions of the transaction table.
+// Partitions are created ahead of the current epoch, so the block dispatcher rarely needs to create one,
+// and partitions of epochs past the retention period are detached or dropped.
+type partitionMaintainer struct {
+	service
+	// cfg is validated on load and shared with the block dispatcher, so both use the same partition size
+	cfg  *config.Partitions
+	tick *time.Ticker
+}
+
+// name returns the name of the service used by orchestrator.
+func (pm *partitionMaintainer) name() string {
+	return "partition maintainer"
+}
+
+// init prepares the partition maintainer to perform its function.
+func (pm *partitionMaintainer) init() {
+	pm.sigStop = make(chan struct{})
+	// the ticker is ready before the maintainer runs, so it can always be stopped on close
+	pm.tick = time.NewTicker(time.Duration(pm.cfg.Interval) * time.Second)
+}
+
+// run starts the partition maintainer.
+func (pm *partitionMaintainer) run() {
+	// signal orchestrator we started and go
+	pm.mgr.started(pm)
+	go pm.execute()
+}
+
+// close signals the partition maintainer to terminate.
+func (pm *partitionMaintainer) close() {
+	if pm.tick != nil {
+		pm.tick.Stop()
+	}
+	if pm.sigStop != nil {
+		close(pm.sigStop)
+	}
+}
+
+// execute maintains the partitions on start and periodically afterwards.
+func (pm *partitionMaintainer) execute() {
+	defer pm.mgr.finished(pm)
+
+	pm.maintain()
+	for {
+		select {
+		case <-pm.sigStop:
+			return
+		case <-pm.tick.C:
+			pm.maintain()
+		}
+	}
+}
+
+// maintain creates the upcoming partitions and expires the partitions past the retention period.
+func (pm *partitionMaintainer) maintain() {
+	epoch, err := pm.repo.CurrentEpoch()
+	if err != nil {
+		pm.log.Errorf("failed to get current epoch; %s", err.Error())
+		return
+	}
+
+	created, err := pm.repo.EnsureTransactionPartitions(epoch, epoch+(pm.cfg.Ahead+1)*pm.cfg.Epochs, pm.cfg.Epochs)
+	if err != nil {
+		pm.log.Errorf("failed to create transaction partitions; %s", err.Error())
+		return
+	}
+	if len(created) > 0 {
+		pm.log.Infof("%d transaction partitions created", len(created))
+	}
+
+	// the previous epoch is always kept, the rewards of the epoch are calculated when it is over
+	if pm.cfg.RetentionEpochs == 0 || epoch <= pm.cfg.RetentionEpochs {
+		return
+	}
+	expired, err := pm.repo.ExpireTransactionPartitions(epoch-pm.cfg.RetentionEpochs, pm.cfg.DetachExpired)
+	if err != nil {
+		pm.log.Errorf("failed to expire transaction partitions; %s", err.Error())
+		return
+	}
+	if len(expired) > 0 {
+		pm.log.Infof("%d transaction partitions expired", len(expired))
+	}
+}
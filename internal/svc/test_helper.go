@@ -69,11 +69,11 @@ func (tch *TestChain) SetupTestRpc(contractAddress common.Address, logger *logge
 	rpcCfg := &config.Rpc{
 		OperaRpcUrl: fmt.Sprintf("http://localhost:%s", tch.port),
 	}
-	gmCfg := &config.GasMonetization{
+	gmCfg := []config.GasMonetization{{
 		ContractAddress: contractAddress.String(),
 		DataProviderPK:  "904d5dea0bdffb09d78a81c15f0b3b893f504679eb8cd1de585309cad58e6285",
 		StartFromBlock:  0,
-	}
+	}}
 	return rpc.New(rpcCfg, gmCfg, logger)
 }
 
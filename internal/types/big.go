@@ -2,45 +2,34 @@ package types
 
 import (
 	"database/sql/driver"
-	"encoding/hex"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"math/big"
 )
 
 // Big is a wrapper around hexutil.Big that implements the sql.Scanner and driver.Valuer interfaces.
+// The value is stored as a decimal number, e.g. in NUMERIC(78,0) columns.
 type Big struct {
 	hexutil.Big
 }
 
 // Scan implements the Scanner interface. It is used by the sql package to convert a database value into Big.
 func (b *Big) Scan(value interface{}) error {
-	if value == nil {
-		b.Big = hexutil.Big{}
-		return nil
-	}
 	switch v := value.(type) {
+	case nil:
+		b.Big = hexutil.Big{}
+	case int64:
+		b.Big = hexutil.Big(*big.NewInt(v))
 	case []byte:
-		if len(v) == 0 {
-			b.Big = hexutil.Big{}
-			return nil
-		}
-		d, err := hex.DecodeString(string(v))
-		if err != nil {
-			return err
-		}
-		b.Big = hexutil.Big(*new(big.Int).SetBytes(d))
+		return b.Scan(string(v))
 	case string:
 		if len(v) == 0 {
 			b.Big = hexutil.Big{}
 			return nil
 		}
-		if len(v) < 3 || v[:2] != "0x" {
-			v = "0x" + v
-		}
-		d, err := hexutil.DecodeBig(v)
-		if err != nil {
-			return err
+		d, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return fmt.Errorf("invalid decimal bigint %s", v)
 		}
 		b.Big = hexutil.Big(*d)
 	default:
@@ -55,5 +44,5 @@ func (b *Big) Value() (driver.Value, error) {
 	if b == nil {
 		return nil, nil
 	}
-	return b.Big.String()[2:], nil
+	return b.ToInt().String(), nil
 }
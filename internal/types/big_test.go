@@ -0,0 +1,25 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+// TestBigDecimalRoundTrip tests amounts beyond 64 bits are stored and scanned as decimal numbers
+func TestBigDecimalRoundTrip(t *testing.T) {
+	amount, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	value, err := (&Big{Big: hexutil.Big(*amount)}).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, amount.String(), value)
+
+	var scanned Big
+	assert.Nil(t, scanned.Scan([]byte(amount.String())))
+	assert.Equal(t, 0, amount.Cmp(scanned.ToInt()))
+	assert.Nil(t, scanned.Scan(int64(42)))
+	assert.Equal(t, int64(42), scanned.ToInt().Int64())
+	assert.Nil(t, scanned.Scan(nil))
+	assert.Zero(t, scanned.ToInt().Sign())
+	assert.NotNil(t, scanned.Scan("75bcd15"))
+}
@@ -29,6 +29,9 @@ type Block struct {
 	// GasUsed represents the actual total used gas by all transactions in this block.
 	GasUsed hexutil.Uint64 `json:"gasUsed"`
 
+	// BaseFee represents the base fee per gas of the block. nil before the dynamic fees were introduced.
+	BaseFee *hexutil.Big `json:"baseFeePerGas"`
+
 	// TimeStamp represents the unix timestamp for when the block was collated.
 	TimeStamp hexutil.Uint64 `json:"timestamp"`
 
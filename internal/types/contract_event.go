@@ -0,0 +1,35 @@
+package types
+
+const (
+	// ContractEventProcessed marks an event successfully processed by its handler.
+	ContractEventProcessed = "processed"
+	// ContractEventFailed marks an event whose handler failed; the block is going to be retried.
+	ContractEventFailed = "failed"
+	// ContractEventIgnored marks an event deliberately not processed.
+	ContractEventIgnored = "ignored"
+	// ContractEventUnhandled marks an event known to the contract ABI, but without a handler.
+	ContractEventUnhandled = "unhandled"
+	// ContractEventUnknown marks an event with a topic unknown to the contract ABI.
+	ContractEventUnknown = "unknown"
+)
+
+// ContractEvent represents a raw log record emitted by a gas monetization contract.
+type ContractEvent struct {
+	Id              int64    `db:"id"`
+	ContractAddress *Address `db:"contract_address"`
+	BlockNumber     uint64   `db:"block_number"`
+	TxHash          *Hash    `db:"tx_hash"`
+	LogIndex        uint64   `db:"log_index"`
+	// Topic represents the first topic of the log, i.e. the event signature.
+	Topic *Hash `db:"topic"`
+	// Name represents the event name, nil if the topic is unknown to the contract ABI.
+	Name *string `db:"event_name"`
+	// Data represents 0x prefixed hex encoded raw data of the log.
+	Data string `db:"data"`
+	// Arguments represents the decoded event arguments encoded as JSON object.
+	Arguments *string `db:"arguments"`
+	// Outcome represents the result of the event processing.
+	Outcome string `db:"outcome"`
+	// Error represents the handler error message in case the processing failed.
+	Error *string `db:"error"`
+}
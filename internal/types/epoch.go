@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+// Epoch represents an epoch observed by the block dispatcher. The epoch ends when the first block
+// of a following epoch is observed, so the time ranges of the epochs do not overlap.
+type Epoch struct {
+	Number uint64 `db:"number"`
+	// FirstBlock represents the first block of the epoch observed, nil if not known.
+	FirstBlock *uint64 `db:"first_block"`
+	// LastBlock represents the last block of the sealed epoch.
+	LastBlock *uint64 `db:"last_block"`
+	// StartTime represents the time of the first block, nil if not known.
+	StartTime *time.Time `db:"start_time"`
+	// EndTime represents the time of the first block of the following epoch observed.
+	EndTime *time.Time `db:"end_time"`
+	// Sealed is set when the epoch is over and its rewards were calculated.
+	Sealed bool `db:"is_sealed"`
+}
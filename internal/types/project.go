@@ -1,8 +1,25 @@
 package types
 
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	// ProjectMetadataPending marks project metadata waiting to be fetched.
+	ProjectMetadataPending = "pending"
+	// ProjectMetadataOk marks project metadata fetched successfully.
+	ProjectMetadataOk = "ok"
+	// ProjectMetadataFailed marks project metadata which failed to be fetched, the fetch is retried later.
+	ProjectMetadataFailed = "failed"
+	// ProjectMetadataInvalid marks project metadata which do not conform to the metadata schema.
+	ProjectMetadataInvalid = "invalid"
+)
+
 type Project struct {
 	Id                  int64    `db:"id"`
 	ProjectId           uint64   `db:"project_id"`
+	ContractAddress     *Address `db:"contract_address"`
 	OwnerAddress        *Address `db:"owner_address"`
 	ReceiverAddress     *Address `db:"receiver_address"`
 	Name                string   `db:"name"`
@@ -15,4 +32,14 @@ type Project struct {
 	TransactionsCount   uint64   `db:"transactions_count"`
 	ActiveFromEpoch     uint64   `db:"active_from_epoch"`
 	ActiveToEpoch       *uint64  `db:"active_to_epoch"`
+	// metadata fetch state, maintained by the metadata fetcher outside of block processing
+	MetadataStatus    string     `db:"metadata_status"`
+	MetadataError     *string    `db:"metadata_error"`
+	MetadataAttempts  int        `db:"metadata_attempts"`
+	MetadataNextFetch time.Time  `db:"metadata_next_fetch"`
+	MetadataFetchedAt *time.Time `db:"metadata_fetched_at"`
+	// Metadata represents the last valid metadata document, nil if none was fetched yet
+	Metadata *ProjectMetadata `db:"metadata"`
+	// MetadataValidationErrors represents schema violations of the last fetched metadata document
+	MetadataValidationErrors pq.StringArray `db:"metadata_validation_errors"`
 }
@@ -0,0 +1,15 @@
+package types
+
+// ProjectActivityPeriod represents a period of epochs the project was active in.
+type ProjectActivityPeriod struct {
+	Id        int64  `db:"id"`
+	ProjectId int64  `db:"project_id"`
+	FromEpoch uint64 `db:"from_epoch"`
+	// ToEpoch represents the epoch the project was suspended in, nil while the project is active.
+	ToEpoch *uint64 `db:"to_epoch"`
+}
+
+// IsActiveIn checks the project was active in the given epoch during the period.
+func (pap *ProjectActivityPeriod) IsActiveIn(epoch uint64) bool {
+	return pap.FromEpoch <= epoch && (pap.ToEpoch == nil || *pap.ToEpoch > epoch)
+}
@@ -5,4 +5,19 @@ type ProjectContract struct {
 	ProjectId int64    `db:"project_id"`
 	Address   *Address `db:"address"`
 	Approved  bool     `db:"is_approved"`
+	// ContractAddress represents the gas monetization contract the project is registered in,
+	// it is set from the project when the contract is stored.
+	ContractAddress *Address `db:"contract_address"`
+	// AddedAtEpoch and AddedAtBlock represent the moment the contract became a member of the project.
+	AddedAtEpoch uint64 `db:"added_at_epoch"`
+	AddedAtBlock uint64 `db:"added_at_block"`
+	// RemovedAtEpoch and RemovedAtBlock represent the moment the contract was removed from the project,
+	// nil while the contract is a member.
+	RemovedAtEpoch *uint64 `db:"removed_at_epoch"`
+	RemovedAtBlock *uint64 `db:"removed_at_block"`
+}
+
+// IsMemberAt returns true if the contract was a member of the project at the given block.
+func (pc *ProjectContract) IsMemberAt(block uint64) bool {
+	return pc.AddedAtBlock <= block && (pc.RemovedAtBlock == nil || *pc.RemovedAtBlock > block)
 }
@@ -0,0 +1,24 @@
+package types
+
+const (
+	// ProjectAttributeOwner represents the owner address of the project.
+	ProjectAttributeOwner = "owner"
+	// ProjectAttributeRecipient represents the rewards recipient address of the project.
+	ProjectAttributeRecipient = "recipient"
+	// ProjectAttributeMetadataUri represents the metadata URI of the project.
+	ProjectAttributeMetadataUri = "metadata_uri"
+)
+
+// ProjectHistory represents a single change of a project attribute.
+type ProjectHistory struct {
+	Id        int64  `db:"id"`
+	ProjectId int64  `db:"project_id"`
+	Attribute string `db:"attribute"`
+	// OldValue represents the value before the change, nil when the project was added.
+	OldValue    *string `db:"old_value"`
+	NewValue    string  `db:"new_value"`
+	BlockNumber uint64  `db:"block_number"`
+	LogIndex    uint64  `db:"log_index"`
+	Epoch       uint64  `db:"epoch_number"`
+	TxHash      *Hash   `db:"tx_hash"`
+}
@@ -1,6 +1,73 @@
 package types
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ProjectMetadata represents the metadata document published by the project owner.
+// The Version identifies the schema the document was validated against; documents
+// without the version are legacy documents carrying the name and the image URL only.
 type ProjectMetadata struct {
-	Name     string `json:"name"`
-	ImageUrl string `json:"image_url"`
+	Version     int               `json:"version,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Website     string            `json:"website,omitempty"`
+	ImageUrl    string            `json:"image_url,omitempty"`
+	Logo        *ProjectLogo      `json:"logo,omitempty"`
+	Socials     map[string]string `json:"socials,omitempty"`
+	Category    string            `json:"category,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Contact     *ProjectContact   `json:"contact,omitempty"`
+}
+
+// ProjectLogo represents variants of the project logo.
+type ProjectLogo struct {
+	Small  string `json:"small,omitempty"`
+	Medium string `json:"medium,omitempty"`
+	Large  string `json:"large,omitempty"`
+	Svg    string `json:"svg,omitempty"`
+}
+
+// ProjectContact represents the contact of the project team.
+type ProjectContact struct {
+	Email string `json:"email,omitempty"`
+	Url   string `json:"url,omitempty"`
+}
+
+// Image returns the URL of the image representing the project, preferring the medium logo variant.
+func (m *ProjectMetadata) Image() string {
+	if m.Logo != nil {
+		for _, url := range []string{m.Logo.Medium, m.Logo.Large, m.Logo.Small, m.Logo.Svg} {
+			if url != "" {
+				return url
+			}
+		}
+	}
+	return m.ImageUrl
+}
+
+// Scan implements the Scanner interface. It is used by the sql package to convert a database value into metadata.
+func (m *ProjectMetadata) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = ProjectMetadata{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return fmt.Errorf("unsupported metadata type %T", value)
+	}
+}
+
+// Value implements the driver.Valuer interface. This method is used by the database/sql
+// package to convert metadata into a value that can be stored in a database.
+func (m *ProjectMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
 }
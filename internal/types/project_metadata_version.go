@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// ProjectMetadataVersion represents a distinct metadata document of a project as it was fetched.
+type ProjectMetadataVersion struct {
+	Id        int64  `db:"id"`
+	ProjectId int64  `db:"project_id"`
+	Uri       string `db:"uri"`
+	// ContentHash represents the Keccak256 hash of the raw document.
+	ContentHash *Hash `db:"content_hash"`
+	// Content represents the raw document as it was served.
+	Content string `db:"content"`
+	// SchemaVersion represents the version of the metadata schema the document conforms to.
+	SchemaVersion int       `db:"schema_version"`
+	FetchedAt     time.Time `db:"fetched_at"`
+}
@@ -0,0 +1,54 @@
+package types
+
+import "github.com/ethereum/go-ethereum/common"
+
+const (
+	// RewardExplanationStored marks an explanation of a processed transaction carrying its stored rewards.
+	RewardExplanationStored = "stored"
+	// RewardExplanationTrace marks an explanation built from a live trace of the transaction.
+	RewardExplanationTrace = "trace"
+)
+
+// RewardExplanation represents the breakdown of the gas attribution and rewards of a single transaction.
+type RewardExplanation struct {
+	Hash        common.Hash `json:"hash"`
+	BlockNumber uint64      `json:"blockNumber"`
+	Epoch       uint64      `json:"epoch"`
+	// Source represents the data the explanation is built from, stored or trace.
+	Source            string `json:"source"`
+	GasPrice          *Big   `json:"gasPrice"`
+	EffectiveGasPrice *Big   `json:"effectiveGasPrice"`
+	BaseFee           *Big   `json:"baseFee,omitempty"`
+	GasPriceSource    string `json:"gasPriceSource"`
+	// RewardsPercentage represents the share of the fee paid to the projects.
+	RewardsPercentage uint64 `json:"rewardsPercentage"`
+	// Reward represents the total reward of all watching projects.
+	Reward *Big                     `json:"reward"`
+	Frames []RewardExplanationFrame `json:"frames"`
+}
+
+// RewardExplanationFrame represents the attribution of a single call frame.
+type RewardExplanationFrame struct {
+	Path     string          `json:"path"`
+	CallType string          `json:"callType"`
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	// Target represents the contract the frame gas is attributed to, nil if the frame is not attributed.
+	Target   *common.Address `json:"target,omitempty"`
+	Selector *string         `json:"selector,omitempty"`
+	// GasUsed represents the gas used by the frame including its sub-calls.
+	GasUsed *uint64 `json:"gasUsed,omitempty"`
+	// SelfGas represents the gas used by the frame without its successful sub-calls.
+	SelfGas  uint64                     `json:"selfGas"`
+	Error    *string                    `json:"error,omitempty"`
+	Watched  bool                       `json:"watched"`
+	Projects []RewardExplanationProject `json:"projects,omitempty"`
+	// Reward represents the reward of the frame paid to each of the watching projects.
+	Reward *Big `json:"reward,omitempty"`
+}
+
+// RewardExplanationProject represents a project watching the target of a call frame.
+type RewardExplanationProject struct {
+	Contract  common.Address `json:"contract"`
+	ProjectId uint64         `json:"projectId"`
+}
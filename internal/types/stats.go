@@ -0,0 +1,8 @@
+package types
+
+// Stats represents the global totals of the gas monetization.
+type Stats struct {
+	TotalAmountCollected   Big    `db:"total_amount_collected"`
+	TotalAmountClaimed     Big    `db:"total_amount_claimed"`
+	TotalTransactionsCount uint64 `db:"total_transactions_count"`
+}
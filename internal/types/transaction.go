@@ -4,15 +4,28 @@ package types
 import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
 	"time"
 )
 
+const (
+	// GasPriceSourceReceipt marks the effective gas price reported by the transaction receipt.
+	GasPriceSourceReceipt = "receipt"
+	// GasPriceSourceBaseFee marks the effective gas price derived from the block base fee and the transaction fee caps.
+	GasPriceSourceBaseFee = "base_fee"
+	// GasPriceSourceTransaction marks the gas price provided by the transaction itself.
+	GasPriceSourceTransaction = "transaction"
+)
+
 // Transaction represents basic information provided by the API about transaction inside Opera blockchain.
 type Transaction struct {
 	Id int64 `db:"id"`
 	// ProjectId represents the project ID this transaction belongs to.
 	ProjectId int64 `db:"project_id"`
 
+	// ContractAddress represents the gas monetization contract the project is registered in.
+	ContractAddress *Address `db:"contract_address"`
+
 	// Hash represents 32 bytes hash of the transaction.
 	Hash *Hash `json:"hash" db:"hash"`
 
@@ -31,18 +44,68 @@ type Transaction struct {
 	// From represents address of the sender.
 	From *Address `json:"from" db:"from_address"`
 
-	// To represents the address of the receiver. nil when it's a contract creation transaction.
+	// To represents the address of the receiver, the created contract for contract creations.
 	To *Address `json:"to,omitempty" db:"to_address"`
 
+	// TraceAddress represents the path of the call frame in the transaction call tree, empty for the top level call.
+	TraceAddress string `db:"trace_address"`
+
+	// CallType represents the call type of the call frame the gas was attributed from.
+	CallType string `db:"call_type"`
+
+	// Selector represents the function selector of the call frame input, nil for calls without one.
+	Selector *string `db:"selector"`
+
 	// GasUsed represents the amount of gas used by this specific transaction alone.
 	GasUsed *hexutil.Uint64 `json:"gasUsed" db:"gas_used"`
 
 	// GasPrice represents gas price provided by the sender in Wei.
 	GasPrice *Big `json:"gasPrice" db:"gas_price"`
 
+	// MaxFeePerGas represents the fee cap of a dynamic fee transaction in Wei. nil for legacy transactions.
+	MaxFeePerGas *Big `json:"maxFeePerGas" db:"-"`
+
+	// MaxPriorityFeePerGas represents the tip cap of a dynamic fee transaction in Wei. nil for legacy transactions.
+	MaxPriorityFeePerGas *Big `json:"maxPriorityFeePerGas" db:"-"`
+
+	// EffectiveGasPrice represents the gas price actually paid by the sender in Wei, rewards are calculated from it.
+	EffectiveGasPrice *Big `json:"effectiveGasPrice" db:"effective_gas_price"`
+
+	// BaseFee represents the base fee per gas of the block the transaction was included in. nil if not known.
+	BaseFee *Big `db:"base_fee"`
+
+	// GasPriceSource represents the source of the effective gas price.
+	GasPriceSource string `db:"gas_price_source"`
+
 	// RewardToClaim represents the amount of reward to claim in Wei.
 	RewardToClaim *Big `db:"reward_to_claim"`
 
 	// Logs represents a list of log records created along with the transaction
 	Logs []types.Log `json:"logs"`
 }
+
+// ResolveEffectiveGasPrice resolves the gas price actually paid by the sender. The price reported by the receipt
+// is preferred; the price of a dynamic fee transaction is derived from the given block base fee otherwise.
+func (trx *Transaction) ResolveEffectiveGasPrice(baseFee *big.Int) {
+	if baseFee != nil {
+		trx.BaseFee = &Big{Big: hexutil.Big(*baseFee)}
+	}
+	switch {
+	case trx.EffectiveGasPrice != nil:
+		trx.GasPriceSource = GasPriceSourceReceipt
+	case trx.MaxFeePerGas != nil && baseFee != nil:
+		// the sender pays the base fee and the tip, up to the fee cap
+		price := new(big.Int).Set(baseFee)
+		if trx.MaxPriorityFeePerGas != nil {
+			price.Add(price, trx.MaxPriorityFeePerGas.ToInt())
+		}
+		if price.Cmp(trx.MaxFeePerGas.ToInt()) > 0 {
+			price.Set(trx.MaxFeePerGas.ToInt())
+		}
+		trx.EffectiveGasPrice = &Big{Big: hexutil.Big(*price)}
+		trx.GasPriceSource = GasPriceSourceBaseFee
+	default:
+		trx.EffectiveGasPrice = trx.GasPrice
+		trx.GasPriceSource = GasPriceSourceTransaction
+	}
+}
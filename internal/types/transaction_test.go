@@ -0,0 +1,41 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+// TestResolveEffectiveGasPrice tests the effective gas price is taken from the best available source
+func TestResolveEffectiveGasPrice(t *testing.T) {
+	wei := func(v int64) *Big {
+		return &Big{Big: hexutil.Big(*big.NewInt(v))}
+	}
+	baseFee := big.NewInt(100)
+
+	// the receipt price wins
+	trx := Transaction{GasPrice: wei(300), MaxFeePerGas: wei(300), EffectiveGasPrice: wei(150)}
+	trx.ResolveEffectiveGasPrice(baseFee)
+	assert.Equal(t, GasPriceSourceReceipt, trx.GasPriceSource)
+	assert.EqualValues(t, 150, trx.EffectiveGasPrice.ToInt().Int64())
+	assert.EqualValues(t, 100, trx.BaseFee.ToInt().Int64())
+
+	// base fee and the tip
+	trx = Transaction{GasPrice: wei(300), MaxFeePerGas: wei(300), MaxPriorityFeePerGas: wei(20)}
+	trx.ResolveEffectiveGasPrice(baseFee)
+	assert.Equal(t, GasPriceSourceBaseFee, trx.GasPriceSource)
+	assert.EqualValues(t, 120, trx.EffectiveGasPrice.ToInt().Int64())
+
+	// capped by the fee cap
+	trx = Transaction{GasPrice: wei(110), MaxFeePerGas: wei(110), MaxPriorityFeePerGas: wei(20)}
+	trx.ResolveEffectiveGasPrice(baseFee)
+	assert.EqualValues(t, 110, trx.EffectiveGasPrice.ToInt().Int64())
+
+	// legacy transaction without the receipt price
+	trx = Transaction{GasPrice: wei(300)}
+	trx.ResolveEffectiveGasPrice(nil)
+	assert.Equal(t, GasPriceSourceTransaction, trx.GasPriceSource)
+	assert.EqualValues(t, 300, trx.EffectiveGasPrice.ToInt().Int64())
+	assert.Nil(t, trx.BaseFee)
+}
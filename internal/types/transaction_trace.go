@@ -8,8 +8,31 @@ import (
 	"strings"
 )
 
+const (
+	// TraceTypeCall represents a trace of a message call.
+	TraceTypeCall = "call"
+	// TraceTypeCreate represents a trace of a contract creation.
+	TraceTypeCreate = "create"
+	// TraceTypeSuicide represents a trace of a contract self-destruct.
+	TraceTypeSuicide = "suicide"
+)
+
+const (
+	// CallTypeCall represents a regular message call.
+	CallTypeCall = "call"
+	// CallTypeDelegateCall represents a call executing the callee code in the context of the caller.
+	CallTypeDelegateCall = "delegatecall"
+	// CallTypeCallCode represents the legacy variant of the delegate call.
+	CallTypeCallCode = "callcode"
+	// CallTypeStaticCall represents a read only message call.
+	CallTypeStaticCall = "staticcall"
+	// CallTypeCreate represents a contract creation, it is not a call type of the trace itself.
+	CallTypeCreate = "create"
+)
+
 // TransactionTrace represents a transaction trace record.
 type TransactionTrace struct {
+	Type         string                  `json:"type"`
 	Action       *TransactionTraceAction `json:"action"`
 	Result       *TransactionTraceResult `json:"result"`
 	Error        *string                 `json:"error"`
@@ -18,26 +41,70 @@ type TransactionTrace struct {
 
 // StringPath returns the trace address as a string path.
 func (t *TransactionTrace) StringPath() string {
-	return strings.Trim(strings.Replace(fmt.Sprint(t.TraceAddress), " ", "", -1), "[]")
+	return TracePath(t.TraceAddress)
 }
 
-// ParentStringPath returns the trace address of a prent as a string path.
+// ParentStringPath returns the trace address of a parent as a string path.
 func (t *TransactionTrace) ParentStringPath() *string {
-	path := t.StringPath()
-	// if the path is empty, there is no parent
-	if len(path) == 0 {
+	// if the trace is the root, there is no parent
+	if len(t.TraceAddress) == 0 {
 		return nil
 	}
-	// remove the last element
-	path = path[:len(path)-1]
+	path := TracePath(t.TraceAddress[:len(t.TraceAddress)-1])
 	return &path
 }
 
+// TracePath returns the trace address as a comma separated path.
+func TracePath(address []int) string {
+	return strings.Trim(strings.Replace(fmt.Sprint(address), " ", ",", -1), "[]")
+}
+
+// CallType returns the call type of the trace; contract creations are represented by the create call type.
+func (t *TransactionTrace) CallType() string {
+	if t.Type == TraceTypeCreate {
+		return CallTypeCreate
+	}
+	if t.Action != nil && t.Action.CallType != nil {
+		return strings.ToLower(*t.Action.CallType)
+	}
+	return CallTypeCall
+}
+
+// GasUsed returns the gas used by the trace including its sub-calls, zero if the trace has no result.
+func (t *TransactionTrace) GasUsed() uint64 {
+	if t.Result == nil || t.Result.GasUsed == nil {
+		return 0
+	}
+	return uint64(*t.Result.GasUsed)
+}
+
+// Created returns the address of the contract created by the trace, nil if the trace did not create a contract.
+func (t *TransactionTrace) Created() *common.Address {
+	if t.Type != TraceTypeCreate || t.Result == nil {
+		return nil
+	}
+	return t.Result.Address
+}
+
 type TransactionTraceAction struct {
-	From *common.Address `json:"from"`
-	To   *common.Address `json:"to"`
+	CallType *string         `json:"callType"`
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Value    *hexutil.Big    `json:"value"`
+	Input    hexutil.Bytes   `json:"input"`
+}
+
+// Selector returns the function selector of the call input, nil if the input is too short to contain one.
+func (a *TransactionTraceAction) Selector() *string {
+	if len(a.Input) < 4 {
+		return nil
+	}
+	selector := hexutil.Encode(a.Input[:4])
+	return &selector
 }
 
 type TransactionTraceResult struct {
 	GasUsed *hexutil.Uint64 `json:"gasUsed"`
+	// Address represents the address of the contract created by a create trace.
+	Address *common.Address `json:"address"`
 }
@@ -1,9 +1,12 @@
 package types
 
 type WithdrawalRequest struct {
-	Id            int64   `db:"id"`
-	ProjectId     int64   `db:"project_id"`
-	RequestEpoch  uint64  `db:"request_epoch"`
-	WithdrawEpoch *uint64 `db:"withdraw_epoch"`
-	Amount        *Big    `db:"amount"`
+	Id              int64    `db:"id"`
+	ProjectId       int64    `db:"project_id"`
+	ContractAddress *Address `db:"contract_address"`
+	RequestEpoch    uint64   `db:"request_epoch"`
+	WithdrawEpoch   *uint64  `db:"withdraw_epoch"`
+	Amount          *Big     `db:"amount"`
+	// RecipientAddress represents the rewards recipient of the project in effect when the withdrawal completed.
+	RecipientAddress *Address `db:"recipient_address"`
 }